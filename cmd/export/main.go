@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/unisat-wallet/libbrc20-indexer/export"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+)
+
+var (
+	network      string
+	snapshotfile string
+	sqlitefile   string
+
+	historyDir      string
+	historyFormat   string
+	partitionBlocks uint
+)
+
+func init() {
+	flag.StringVar(&network, "network", "mainnet", "the network of snapshot, mainnet/testnet/testnet4/signet/regtest")
+	flag.StringVar(&snapshotfile, "snapshot", "", "the filename of state snapshot to export")
+	flag.StringVar(&sqlitefile, "sqlite", "", "the filename of sqlite database to write")
+	flag.StringVar(&historyDir, "history_dir", "", "the directory of decoded history files to write")
+	flag.StringVar(&historyFormat, "format", export.FORMAT_CSV, "the format of history files, csv/parquet")
+	flag.UintVar(&partitionBlocks, "partition_blocks", export.DEFAULT_PARTITION_BLOCKS, "the blocks of each history file")
+}
+
+// export state of snapshot for analysis
+func main() {
+	flag.Parse()
+	if snapshotfile == "" || (sqlitefile == "" && historyDir == "") {
+		flag.Usage()
+		log.Fatalf("snapshot and output required")
+	}
+
+	opts, err := indexer.OptionsForNetwork(network)
+	if err != nil {
+		log.Fatalf("invalid network, %s", err)
+	}
+	opts.UndoDepth = -1
+	g := indexer.New(opts)
+	if err := g.LoadSnapshot(snapshotfile); err != nil {
+		log.Fatalf("load snapshot failed, %s", err)
+	}
+
+	if sqlitefile != "" {
+		if err := export.ExportSQLite(g.Query(), sqlitefile); err != nil {
+			log.Fatalf("export sqlite failed, %s", err)
+		}
+	}
+	if historyDir != "" {
+		opts := export.HistoryOptions{Format: historyFormat, PartitionBlocks: uint32(partitionBlocks)}
+		if err := export.ExportHistory(g.Query(), historyDir, opts); err != nil {
+			log.Fatalf("export history failed, %s", err)
+		}
+	}
+}
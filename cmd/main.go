@@ -1,72 +1,284 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"time"
 
-	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/historylog"
 	"github.com/unisat-wallet/libbrc20-indexer/indexer"
 	"github.com/unisat-wallet/libbrc20-indexer/loader"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/rpc"
+	"github.com/unisat-wallet/libbrc20-indexer/rpc/brc20pb"
+	"github.com/unisat-wallet/libbrc20-indexer/server"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
+	"google.golang.org/grpc"
+)
+
+const (
+	SERVE_READ_HEADER_TIMEOUT = 10 * time.Second
+	SERVE_READ_TIMEOUT        = 30 * time.Second
+	SERVE_WRITE_TIMEOUT       = 60 * time.Second
+	SERVE_IDLE_TIMEOUT        = 120 * time.Second
 )
 
 var (
 	inputfile        string
 	outputfile       string
 	outputModulefile string
+	snapshotfile     string
+	storagefile      string
+	historyDir       string
+	archiveDir       string
+	keepBlocks       uint
+	rulesfile        string
+	network          string
+	serveAddr        string
+	grpcAddr         string
+	servePublic      bool
 	testnet          bool
+
+	opts indexer.Options
 )
 
 func init() {
-	flag.BoolVar(&testnet, "testnet", false, "testnet")
+	flag.BoolVar(&testnet, "testnet", false, "testnet, same as -network testnet")
+	flag.StringVar(&network, "network", "", "the network, mainnet/testnet/testnet4/signet/regtest, default by rules or mainnet")
 	flag.StringVar(&inputfile, "input", "./data/brc20.input.txt", "the filename of input data, default(./data/brc20.input.txt)")
 	flag.StringVar(&outputfile, "output", "./data/brc20.output.txt", "the filename of output data, default(./data/brc20.output.txt)")
 	flag.StringVar(&outputModulefile, "output_module", "./data/module.output.txt", "the filename of output data, default(./data/module.output.txt)")
+	flag.StringVar(&historyDir, "history_dir", "", "the directory of history log, history kept in memory if not set")
+	flag.UintVar(&keepBlocks, "keep_history", 0, "the blocks of history to keep, history before is pruned, default(0) keep all")
+	flag.StringVar(&archiveDir, "history_archive_dir", "", "the directory to archive history pruned into, history dropped if not set")
+	flag.StringVar(&rulesfile, "rules", "", "the filename of chain rules in json, default by network")
+	flag.StringVar(&snapshotfile, "snapshot", "", "the filename of state snapshot, resume from it if exists and save after processing")
+	flag.StringVar(&storagefile, "storage", "", "the filename of state storage, state kept in it instead of memory, resume from it if has state")
+	flag.StringVar(&serveAddr, "serve", "", "the address to serve read-only json api on while and after processing, e.g. 127.0.0.1:8080")
+	flag.StringVar(&grpcAddr, "grpc", "", "the address to serve grpc on while and after processing, e.g. 127.0.0.1:9090")
+	flag.BoolVar(&servePublic, "serve_public", false, "allow -serve and -grpc on addresses other than loopback, default(false)")
 
 	flag.Parse()
 
 	if testnet {
-		conf.GlobalNetParams = &chaincfg.TestNet3Params
+		network = "testnet"
+	}
+
+	var rules *conf.ChainRules
+	var err error
+	if rulesfile != "" {
+		rules, err = conf.LoadChainRules(rulesfile)
+		if err == nil && network == "" {
+			network = rules.Network
+		}
+	} else {
+		if network == "" {
+			network = "mainnet"
+		}
+		rules, err = conf.ChainRulesByNetwork(network)
+	}
+	if err != nil {
+		log.Fatalf("load chain rules failed, %s", err)
+	}
+
+	// also for addresses of dump
+	opts.NetParams, err = conf.NetParamsByNetwork(network)
+	if err != nil {
+		log.Fatalf("invalid network, %s", err)
 	}
 
 	if ticks := os.Getenv("TICKS_ENABLED"); ticks != "" {
-		conf.TICKS_ENABLED = ticks
+		opts.TicksEnabled = ticks
 	}
 
+	opts.Debug = os.Getenv("DEBUG") != ""
+
 	if id := os.Getenv("MODULE_SWAP_SOURCE_INSCRIPTION_ID"); id != "" {
-		conf.MODULE_SWAP_SOURCE_INSCRIPTION_ID = id
+		rules.ModuleSwapSourceInscriptionId = id
 	}
 
 	if heightStr := os.Getenv("BRC20_ENABLE_SELF_MINT_HEIGHT"); heightStr != "" {
-		if h, err := strconv.Atoi(heightStr); err != nil {
-			conf.ENABLE_SELF_MINT_HEIGHT = uint32(h)
+		if h, err := strconv.Atoi(heightStr); err == nil {
+			rules.SelfMintHeight = uint32(h)
 		}
 	}
+	opts.Rules = rules
 }
 
 func main() {
-	brc20Datas := make(chan interface{}, 10240)
-	go func() {
-		if err := loader.LoadBRC20InputData(inputfile, brc20Datas); err != nil {
-			log.Printf("invalid input, %s", err)
-		}
-		close(brc20Datas)
-	}()
-
-	g := &indexer.BRC20ModuleIndexer{}
-	g.Init()
-	g.ProcessUpdateLatestBRC20Loop(brc20Datas, nil)
-
-	loader.DumpTickerInfoMap(outputfile,
-		g.HistoryData,
-		g.InscriptionsTickerInfoMap,
-		g.UserTokensBalanceData,
-		g.TokenUsersBalanceData,
+	src, err := loader.NewFileSource(inputfile)
+	if err != nil {
+		log.Fatalf("open input failed, %s", err)
+	}
+	defer src.Close()
+
+	if historyDir != "" {
+		historyLog, err := historylog.Open(historyDir, historylog.Options{})
+		if err != nil {
+			log.Fatalf("open history log failed, %s", err)
+		}
+		defer historyLog.Close()
+		opts.HistoryLog = historyLog
+	}
+
+	resumed := false
+	if storagefile != "" {
+		db, err := storage.OpenBolt(storagefile)
+		if err != nil {
+			log.Fatalf("open storage failed, %s", err)
+		}
+		defer db.Close()
+		opts.Storage = db
+		_, err = db.Get([]byte(indexer.STORAGE_KEY_META))
+		resumed = err == nil
+	}
+
+	g := indexer.New(opts)
+	if resumed {
+		if err := g.LoadStorage(opts.Storage); err != nil {
+			log.Fatalf("load storage failed, %s", err)
+		}
+	} else if snapshotfile != "" {
+		if _, err := os.Stat(snapshotfile); err == nil {
+			if err := g.LoadSnapshot(snapshotfile); err != nil {
+				log.Fatalf("load snapshot failed, %s", err)
+			}
+		}
+	}
+
+	var srv *http.Server
+	if serveAddr != "" {
+		if !servePublic && !isLoopbackAddr(serveAddr) {
+			log.Fatalf("serve api on %s not loopback, set -serve_public to allow", serveAddr)
+		}
+		srv = &http.Server{
+			Addr:              serveAddr,
+			Handler:           server.New(g.Query(), opts.NetParams),
+			ReadHeaderTimeout: SERVE_READ_HEADER_TIMEOUT,
+			ReadTimeout:       SERVE_READ_TIMEOUT,
+			WriteTimeout:      SERVE_WRITE_TIMEOUT,
+			IdleTimeout:       SERVE_IDLE_TIMEOUT,
+		}
+		go func() {
+			log.Printf("serving api on %s", serveAddr)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("serve api failed, %s", err)
+			}
+		}()
+	}
+
+	var grpcSrv *grpc.Server
+	if grpcAddr != "" {
+		if !servePublic && !isLoopbackAddr(grpcAddr) {
+			log.Fatalf("serve grpc on %s not loopback, set -serve_public to allow", grpcAddr)
+		}
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("listen grpc failed, %s", err)
+		}
+		grpcSrv = grpc.NewServer()
+		brc20pb.RegisterBRC20IndexerServer(grpcSrv, rpc.NewService(g))
+		go func() {
+			log.Printf("serving grpc on %s", grpcAddr)
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatalf("serve grpc failed, %s", err)
+			}
+		}()
+	}
+
+	if err := g.ProcessSource(context.Background(), g.ResumeSource(src)); err != nil {
+		log.Fatalf("invalid input, %s", err)
+	}
+	if keepBlocks > 0 && uint(g.BestHeight) >= keepBlocks {
+		cutoff := g.BestHeight - uint32(keepBlocks) + 1
+		archive := ""
+		if archiveDir != "" {
+			if err := os.MkdirAll(archiveDir, 0755); err != nil {
+				log.Fatalf("create archive dir failed, %s", err)
+			}
+			archive = filepath.Join(archiveDir, fmt.Sprintf("history-%d.archive", cutoff))
+		}
+		if err := g.PruneHistory(cutoff, archive); err != nil {
+			log.Fatalf("prune history failed, %s", err)
+		}
+	}
+	if snapshotfile != "" {
+		if err := g.SaveSnapshot(snapshotfile); err != nil {
+			log.Fatalf("save snapshot failed, %s", err)
+		}
+	}
+
+	// state in storage is read for dump
+	userTokensBalanceData := make(map[string]map[string]*model.BRC20TokenBalance, 0)
+	err = g.RangeUserBalances(func(pkScript string, userTokens map[string]*model.BRC20TokenBalance) bool {
+		userTokensBalanceData[pkScript] = userTokens
+		return true
+	})
+	if err != nil {
+		log.Fatalf("load balances failed, %s", err)
+	}
+	tokenUsersBalanceData := make(map[string]map[string]*model.BRC20TokenBalance, 0)
+	tickerInfoMap := make(map[string]*model.BRC20TokenInfo, 0)
+	for ticker := range g.InscriptionsTickerInfoMap {
+		tokenUsersBalanceData[ticker] = g.TokenHolders(ticker)
+		if tickerInfoMap[ticker], _, err = g.TickerInfo(ticker); err != nil {
+			log.Fatalf("load history of tickers failed, %s", err)
+		}
+	}
+	modulesInfoMap := make(map[string]*model.BRC20ModuleSwapInfo, 0)
+	for _, moduleId := range g.ModuleIds() {
+		moduleInfo, _ := g.Module(moduleId)
+		full := *moduleInfo
+		if full.History, err = g.ModuleHistory(moduleInfo); err != nil {
+			log.Fatalf("load history of modules failed, %s", err)
+		}
+		modulesInfoMap[moduleId] = &full
+	}
+
+	loader.DumpTickerInfoMapByHistory(outputfile,
+		g.GetHistoryData,
+		tickerInfoMap,
+		userTokensBalanceData,
+		tokenUsersBalanceData,
+		g.NetParams,
 	)
 
 	loader.DumpModuleInfoMap(outputModulefile,
-		g.ModulesInfoMap,
+		modulesInfoMap,
+		g.NetParams,
 	)
+	if srv != nil || grpcSrv != nil {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		<-ctx.Done()
+		if srv != nil {
+			srv.Shutdown(context.Background())
+		}
+		if grpcSrv != nil {
+			// streams only end by canceling
+			grpcSrv.Stop()
+		}
+	}
+}
+
+// isLoopbackAddr Host of addr only reachable from this machine, empty host is all interfaces.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
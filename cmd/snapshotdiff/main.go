@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+)
+
+var (
+	network string
+	limit   int
+)
+
+func init() {
+	flag.StringVar(&network, "network", "mainnet", "the network of snapshots, mainnet/testnet/testnet4/signet/regtest")
+	flag.IntVar(&limit, "limit", 0, "the max differences to print, default(0) all")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] snapshot-a snapshot-b\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+// list differences of two snapshots, exit status 1 if any, as diff
+func main() {
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := indexer.OptionsForNetwork(network)
+	if err != nil {
+		log.Fatalf("invalid network, %s", err)
+	}
+	opts.DisableHistory = true
+	opts.UndoDepth = -1
+
+	diffs, err := indexer.DiffSnapshots(flag.Arg(0), flag.Arg(1), opts)
+	if err != nil {
+		log.Fatalf("load snapshots failed, %s", err)
+	}
+
+	for i, d := range diffs {
+		if limit > 0 && i >= limit {
+			fmt.Printf("... %d more\n", len(diffs)-limit)
+			break
+		}
+		address := ""
+		if d.PkScript != "" {
+			if address, err = utils.GetAddressFromScript([]byte(d.PkScript), opts.NetParams); err != nil {
+				address = fmt.Sprintf("%x", d.PkScript)
+			}
+		}
+		fmt.Printf("module=%s ticker=%s address=%s %s: %s | %s\n", d.Module, d.Ticker, address, d.Field, d.A, d.B)
+	}
+	if len(diffs) > 0 {
+		os.Exit(1)
+	}
+}
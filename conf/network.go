@@ -0,0 +1,45 @@
+package conf
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Networks Names of networks supported.
+var Networks = []string{"mainnet", "testnet", "testnet4", "signet", "regtest"}
+
+// TestNet4Params Params of testnet4, not in btcd yet. Address encoding is the same as testnet3,
+// only fields for addresses and network identity are set.
+var TestNet4Params = func() chaincfg.Params {
+	params := chaincfg.TestNet3Params
+	params.Name = "testnet4"
+	params.Net = wire.BitcoinNet(0x283f161c)
+	params.DefaultPort = "48333"
+	params.DNSSeeds = nil
+	params.Checkpoints = nil
+	params.GenesisBlock = nil
+	genesisHash, _ := chainhash.NewHashFromStr("00000000da84f2bafbbc53dee25a72ae507ff4914b867c565be350b0da8bf043")
+	params.GenesisHash = genesisHash
+	return params
+}()
+
+// NetParamsByNetwork Params of a network, for address encoding.
+func NetParamsByNetwork(network string) (*chaincfg.Params, error) {
+	switch network {
+	case "mainnet":
+		return &chaincfg.MainNetParams, nil
+	case "testnet", "testnet3":
+		return &chaincfg.TestNet3Params, nil
+	case "testnet4":
+		return &TestNet4Params, nil
+	case "signet":
+		return &chaincfg.SigNetParams, nil
+	case "regtest":
+		return &chaincfg.RegressionNetParams, nil
+	default:
+		return nil, fmt.Errorf("unknown network: %s", network)
+	}
+}
@@ -0,0 +1,104 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ChainRules Consensus activations of a network. Height is the first block the rule is active.
+type ChainRules struct {
+	Network string `json:"network"`
+
+	// 5-byte ticker, mint by deployer only
+	SelfMintHeight uint32 `json:"self_mint_height"`
+	// swap module withdraw, and the changed commit/approve checks with it
+	SwapWithdrawHeight uint32 `json:"swap_withdraw_height"`
+
+	// source inscription of valid swap module
+	ModuleSwapSourceInscriptionId string `json:"module_swap_source_inscription_id"`
+}
+
+var (
+	MainnetRules = ChainRules{
+		Network:                       "mainnet",
+		SelfMintHeight:                837090,
+		SwapWithdrawHeight:            847090, // fixme: dummy height
+		ModuleSwapSourceInscriptionId: "d2a30f6131324e06b1366876c8c089d7ad2a9c2b0ea971c5b0dc6198615bda2ei0",
+	}
+
+	// testnet follows the mainnet heights, as the globals did
+	TestnetRules = ChainRules{
+		Network:                       "testnet",
+		SelfMintHeight:                837090,
+		SwapWithdrawHeight:            847090,
+		ModuleSwapSourceInscriptionId: "d2a30f6131324e06b1366876c8c089d7ad2a9c2b0ea971c5b0dc6198615bda2ei0",
+	}
+
+	// new networks, all active from genesis, set module source in rules file
+	Testnet4Rules = ChainRules{
+		Network: "testnet4",
+	}
+
+	SignetRules = ChainRules{
+		Network: "signet",
+	}
+
+	RegtestRules = ChainRules{
+		Network: "regtest",
+	}
+)
+
+// Activations Name and height of every activation.
+func (r *ChainRules) Activations() map[string]uint32 {
+	return map[string]uint32{
+		"self_mint":     r.SelfMintHeight,
+		"swap_withdraw": r.SwapWithdrawHeight,
+	}
+}
+
+// ChainRulesByNetwork Rules of a known network.
+func ChainRulesByNetwork(network string) (*ChainRules, error) {
+	var rules ChainRules
+	switch network {
+	case "mainnet":
+		rules = MainnetRules
+	case "testnet", "testnet3":
+		rules = TestnetRules
+	case "testnet4":
+		rules = Testnet4Rules
+	case "signet":
+		rules = SignetRules
+	case "regtest":
+		rules = RegtestRules
+	default:
+		return nil, fmt.Errorf("unknown network: %s", network)
+	}
+	return &rules, nil
+}
+
+// LoadChainRules Load rules from json file. Fields not in file are of the network, or mainnet.
+func LoadChainRules(fname string) (*ChainRules, error) {
+	content, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	var head struct {
+		Network string `json:"network"`
+	}
+	if err := json.Unmarshal(content, &head); err != nil {
+		return nil, fmt.Errorf("%s: %w", fname, err)
+	}
+	if head.Network == "" {
+		head.Network = "mainnet"
+	}
+	rules, err := ChainRulesByNetwork(head.Network)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fname, err)
+	}
+	if err := json.Unmarshal(content, rules); err != nil {
+		return nil, fmt.Errorf("%s: %w", fname, err)
+	}
+	return rules, nil
+}
@@ -2,6 +2,10 @@ package constant
 
 const MEMPOOL_HEIGHT = 0x3fffff // 3fffff, 2^22-1
 
+const DEFAULT_UNDO_DEPTH = 12 // blocks can be rolled back
+
+const DEFAULT_STORAGE_CACHE_SIZE = 100000 // entries of each kind kept in memory with storage
+
 // brc20 protocal
 const (
 	BRC20_P        = "brc-20"
@@ -69,6 +73,7 @@ const (
 const (
 	BRC20_HISTORY_MODULE_TYPE_INSCRIBE_MODULE   = "inscribe-module"
 	BRC20_HISTORY_MODULE_TYPE_INSCRIBE_WITHDRAW = "inscribe-withdraw"
+	BRC20_HISTORY_MODULE_TYPE_WITHDRAW          = "withdraw"
 	BRC20_HISTORY_MODULE_TYPE_WITHDRAW_FROM     = "withdraw-from"
 	BRC20_HISTORY_MODULE_TYPE_WITHDRAW_TO       = "withdraw-to"
 
@@ -96,6 +101,7 @@ var BRC20_HISTORY_TYPE_NAMES []string = []string{
 	// module
 	BRC20_HISTORY_MODULE_TYPE_INSCRIBE_MODULE,
 	BRC20_HISTORY_MODULE_TYPE_INSCRIBE_WITHDRAW,
+	BRC20_HISTORY_MODULE_TYPE_WITHDRAW,
 	BRC20_HISTORY_MODULE_TYPE_WITHDRAW_FROM,
 	BRC20_HISTORY_MODULE_TYPE_WITHDRAW_TO,
 
@@ -124,6 +130,7 @@ var BRC20_HISTORY_TYPES_TO_N map[string]uint8 = map[string]uint8{
 	// module
 	BRC20_HISTORY_MODULE_TYPE_INSCRIBE_MODULE:   BRC20_HISTORY_MODULE_TYPE_N_INSCRIBE_MODULE,
 	BRC20_HISTORY_MODULE_TYPE_INSCRIBE_WITHDRAW: BRC20_HISTORY_MODULE_TYPE_N_INSCRIBE_WITHDRAW,
+	BRC20_HISTORY_MODULE_TYPE_WITHDRAW:          BRC20_HISTORY_MODULE_TYPE_N_WITHDRAW,
 	BRC20_HISTORY_MODULE_TYPE_WITHDRAW_FROM:     BRC20_HISTORY_MODULE_TYPE_N_WITHDRAW_FROM,
 	BRC20_HISTORY_MODULE_TYPE_WITHDRAW_TO:       BRC20_HISTORY_MODULE_TYPE_N_WITHDRAW_TO,
 
@@ -153,3 +160,28 @@ const (
 )
 
 const ZERO_ADDRESS_PKSCRIPT = "\x6a\x20\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"
+
+// inscription outcome
+const (
+	BRC20_OUTCOME_VALID = "valid"
+
+	BRC20_REJECT_JSON_INVALID         = "json-invalid"
+	BRC20_REJECT_OP_INVALID           = "op-invalid"
+	BRC20_REJECT_TICK_LENGTH          = "tick-length"
+	BRC20_REJECT_TICK_NOT_ENABLED     = "tick-not-enabled"
+	BRC20_REJECT_TICK_NOT_EXIST       = "tick-not-exist"
+	BRC20_REJECT_SELF_MINT_DISABLED   = "self-mint-disabled"
+	BRC20_REJECT_SELF_MINT_PARENT     = "self-mint-parent"
+	BRC20_REJECT_DUP_DEPLOY           = "dup-deploy"
+	BRC20_REJECT_MAX_INVALID          = "max-invalid"
+	BRC20_REJECT_LIM_INVALID          = "lim-invalid"
+	BRC20_REJECT_DEC_INVALID          = "dec-invalid"
+	BRC20_REJECT_AMOUNT_INVALID       = "amount-invalid"
+	BRC20_REJECT_OVER_LIMIT           = "over-limit"
+	BRC20_REJECT_MINT_OUT             = "mint-out"
+	BRC20_REJECT_INSUFFICIENT_BALANCE = "insufficient-balance"
+	BRC20_REJECT_INSCRIPTION_INVALID  = "inscription-invalid" // moving an inscription which was invalid on inscribe
+	BRC20_REJECT_SENDER_MISSING       = "sender-missing"
+	BRC20_REJECT_DUP_TRANSFER         = "dup-transfer"
+	BRC20_REJECT_MODULE               = "module-rejected" // see message for details
+)
@@ -0,0 +1,14 @@
+package constant
+
+import "testing"
+
+func TestHistoryTypeNames(t *testing.T) {
+	if len(BRC20_HISTORY_TYPES_TO_N) != len(BRC20_HISTORY_TYPE_NAMES) {
+		t.Fatalf("names %d, numbers %d", len(BRC20_HISTORY_TYPE_NAMES), len(BRC20_HISTORY_TYPES_TO_N))
+	}
+	for n, name := range BRC20_HISTORY_TYPE_NAMES {
+		if got, ok := BRC20_HISTORY_TYPES_TO_N[name]; !ok || int(got) != n {
+			t.Fatalf("type %s is %d, want %d", name, got, n)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
@@ -68,7 +68,7 @@ func InitTickDataFromFile(fname string) (brc20Datas []*model.InscriptionBRC20Dat
 	return brc20Datas, nil
 }
 
-func GenerateBRC20InputDataFromEvents(fname string) (brc20Datas []*model.InscriptionBRC20Data, err error) {
+func GenerateBRC20InputDataFromEvents(fname string, netParams *chaincfg.Params) (brc20Datas []*model.InscriptionBRC20Data, err error) {
 	// Open our jsonFile
 	jsonFile, err := os.Open(fname)
 	// if we os.Open returns an error then handle it
@@ -115,12 +115,12 @@ func GenerateBRC20InputDataFromEvents(fname string) (brc20Datas []*model.Inscrip
 		data.CreateIdxKey = key.String()
 
 		var pkScriptFrom, pkScriptTo string
-		if pk, err := utils.GetPkScriptByAddress(e.AddressFrom, conf.GlobalNetParams); err != nil {
+		if pk, err := utils.GetPkScriptByAddress(e.AddressFrom, netParams); err != nil {
 			log.Printf("GenerateBRC20InputDataFromEvents [%d] pk invalid: %s", idx, err)
 		} else {
 			pkScriptFrom = string(pk)
 		}
-		if pk, err := utils.GetPkScriptByAddress(e.AddressTo, conf.GlobalNetParams); err != nil {
+		if pk, err := utils.GetPkScriptByAddress(e.AddressTo, netParams); err != nil {
 			pk, _ := hex.DecodeString(e.AddressTo)
 			pkScriptTo = string(pk)
 		} else {
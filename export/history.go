@@ -0,0 +1,285 @@
+package export
+
+import (
+	"container/heap"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/view"
+)
+
+const (
+	FORMAT_CSV     = "csv"
+	FORMAT_PARQUET = "parquet"
+
+	DEFAULT_PARTITION_BLOCKS = 10000
+
+	// <name>-<first height>-<last height>.<format>
+	PARTITION_NAME_FORMAT = "%s-%010d-%010d.%s"
+)
+
+// HistoryRow Row of history, see view.HistoryRow.
+type HistoryRow = view.HistoryRow
+
+// ModuleHistoryRow Row of module history, see view.ModuleHistoryRow.
+type ModuleHistoryRow = view.ModuleHistoryRow
+
+// HistoryOptions Config of history export, zero values are the defaults.
+type HistoryOptions struct {
+	Format          string // csv or parquet, default csv
+	PartitionBlocks uint32 // blocks of each file
+}
+
+// partitionWriter Rows of T into files of dir, a new file for each range of heights.
+type partitionWriter[T any] struct {
+	dir    string
+	name   string
+	format string
+	blocks uint32
+
+	first uint32 // height of current file
+	file  *os.File
+	csv   *csv.Writer
+	pq    *parquet.GenericWriter[T]
+	files int
+	rows  int
+}
+
+func (w *partitionWriter[T]) Write(height uint32, row T) error {
+	first := height / w.blocks * w.blocks
+	if w.file == nil || first != w.first {
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if err := w.open(first); err != nil {
+			return err
+		}
+	}
+	w.rows++
+	if w.pq != nil {
+		_, err := w.pq.Write([]T{row})
+		return err
+	}
+	return w.csv.Write(csvRecord(row))
+}
+
+func (w *partitionWriter[T]) open(first uint32) (err error) {
+	fname := filepath.Join(w.dir, fmt.Sprintf(PARTITION_NAME_FORMAT, w.name, first, first+w.blocks-1, w.format))
+	if w.file, err = os.Create(fname); err != nil {
+		return err
+	}
+	w.first = first
+	w.files++
+	if w.format == FORMAT_PARQUET {
+		w.pq = parquet.NewGenericWriter[T](w.file)
+		return nil
+	}
+	w.csv = csv.NewWriter(w.file)
+	return w.csv.Write(csvHeader(reflect.TypeOf((*T)(nil)).Elem()))
+}
+
+// Close Finish the current file.
+func (w *partitionWriter[T]) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	var err error
+	if w.pq != nil {
+		err = w.pq.Close()
+	} else {
+		w.csv.Flush()
+		err = w.csv.Error()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file, w.csv, w.pq = nil, nil, nil
+	return err
+}
+
+// csvHeader Names of columns, same as parquet.
+func csvHeader(t reflect.Type) (header []string) {
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("parquet"), ",")
+		header = append(header, name)
+	}
+	return header
+}
+
+func csvRecord(row any) (record []string) {
+	v := reflect.ValueOf(row)
+	for i := 0; i < v.NumField(); i++ {
+		record = append(record, fmt.Sprint(v.Field(i).Interface()))
+	}
+	return record
+}
+
+// ExportHistory Write decoded history and module history into files of dir, partitioned by height:
+//
+//	history-<first>-<last>.<format>
+//	module_history-<first>-<last>.<format>
+//
+// Blocks are not processed while exporting.
+func ExportHistory(q *indexer.Query, dir string, opts HistoryOptions) error {
+	if opts.Format == "" {
+		opts.Format = FORMAT_CSV
+	}
+	if opts.Format != FORMAT_CSV && opts.Format != FORMAT_PARQUET {
+		return fmt.Errorf("unknown format %s", opts.Format)
+	}
+	if opts.PartitionBlocks == 0 {
+		opts.PartitionBlocks = DEFAULT_PARTITION_BLOCKS
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	log.Printf("exporting brc20 history into %s ...", dir)
+
+	history := &partitionWriter[HistoryRow]{dir: dir, name: "history", format: opts.Format, blocks: opts.PartitionBlocks}
+	defer history.Close()
+	moduleHistory := &partitionWriter[ModuleHistoryRow]{dir: dir, name: "module_history", format: opts.Format, blocks: opts.PartitionBlocks}
+	defer moduleHistory.Close()
+
+	var err error
+	q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if err = writeHistory(history, g); err != nil {
+			return
+		}
+		err = writeModuleHistory(moduleHistory, g)
+	})
+	if err != nil {
+		return err
+	}
+	if err := history.Close(); err != nil {
+		return err
+	}
+	if err := moduleHistory.Close(); err != nil {
+		return err
+	}
+	log.Printf("export brc20 history ok, files: %d, rows: %d, module files: %d, rows: %d",
+		history.files, history.rows, moduleHistory.files, moduleHistory.rows)
+	return nil
+}
+
+// writeHistory Rows in order of index, which is in order of height.
+func writeHistory(w *partitionWriter[HistoryRow], g *indexer.BRC20ModuleIndexer) error {
+	for idx := g.HistoryStart; idx < g.HistoryCount; idx++ {
+		data, ok := g.GetHistoryData(idx)
+		if !ok {
+			return fmt.Errorf("history %d missing", idx)
+		}
+		h := &model.BRC20History{}
+		h.Unmarshal(data)
+		if err := w.Write(h.Height, view.DecodeHistory(idx, h, g.NetParams)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moduleHistoryList Rest of a history list of module, lists ranked by rank when at the same place.
+type moduleHistoryList struct {
+	moduleId string
+	list     []*model.BRC20ModuleHistory
+	rank     int
+}
+
+// moduleHistoryHeap Lists by (height, txidx) of their first history.
+type moduleHistoryHeap []*moduleHistoryList
+
+func (hp moduleHistoryHeap) Len() int { return len(hp) }
+func (hp moduleHistoryHeap) Less(i, j int) bool {
+	a, b := hp[i].list[0], hp[j].list[0]
+	if a.Height != b.Height {
+		return a.Height < b.Height
+	}
+	if a.TxIdx != b.TxIdx {
+		return a.TxIdx < b.TxIdx
+	}
+	return hp[i].rank < hp[j].rank
+}
+func (hp moduleHistoryHeap) Swap(i, j int) { hp[i], hp[j] = hp[j], hp[i] }
+func (hp *moduleHistoryHeap) Push(x any)   { *hp = append(*hp, x.(*moduleHistoryList)) }
+func (hp *moduleHistoryHeap) Pop() any {
+	old := *hp
+	x := old[len(old)-1]
+	*hp = old[:len(old)-1]
+	return x
+}
+
+// moduleHistoryKey Same history kept in several lists, pointers differ after load by gob.
+type moduleHistoryKey struct {
+	moduleId      string
+	inscriptionId string
+	historyType   uint8
+}
+
+// writeModuleHistory Rows of all modules, in order of height, as merged from the lists. History
+// of module and history of balances in module, as withdraw-from/to and approve-from/to are only
+// of balances.
+func writeModuleHistory(w *partitionWriter[ModuleHistoryRow], g *indexer.BRC20ModuleIndexer) error {
+	hp := &moduleHistoryHeap{}
+	add := func(moduleId string, list []*model.BRC20ModuleHistory) {
+		if len(list) > 0 {
+			*hp = append(*hp, &moduleHistoryList{moduleId: moduleId, list: list, rank: hp.Len()})
+		}
+	}
+	var historyErr error
+	err := g.RangeModules(func(moduleId string, m *model.BRC20ModuleSwapInfo) bool {
+		history, err := g.ModuleHistory(m)
+		if err != nil {
+			historyErr = err
+			return false
+		}
+		add(moduleId, history)
+		for _, pkScript := range view.SortedKeys(m.UsersTokenBalanceDataMap) {
+			userTokens := m.UsersTokenBalanceDataMap[pkScript]
+			for _, ticker := range view.SortedKeys(userTokens) {
+				add(moduleId, userTokens[ticker].History)
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = historyErr
+	}
+	if err != nil {
+		return err
+	}
+	heap.Init(hp)
+
+	// lists hold the same history at the same tx, only the keys of the tx are kept
+	var height, txIdx uint32
+	seen := make(map[moduleHistoryKey]struct{}, 0)
+	for hp.Len() > 0 {
+		next := (*hp)[0]
+		h := next.list[0]
+		if next.list = next.list[1:]; len(next.list) > 0 {
+			heap.Fix(hp, 0)
+		} else {
+			heap.Pop(hp)
+		}
+
+		if h.Height != height || h.TxIdx != txIdx {
+			height, txIdx = h.Height, h.TxIdx
+			seen = make(map[moduleHistoryKey]struct{}, 0)
+		}
+		key := moduleHistoryKey{moduleId: next.moduleId, inscriptionId: h.Inscription.InscriptionId, historyType: h.Type}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if err := w.Write(h.Height, view.DecodeModuleHistory(next.moduleId, h, g.NetParams)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer/indexertest"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+func TestExportHistory(t *testing.T) {
+	g := indexertest.NewIndexer()
+	names := []string{
+		"history-0000000100-0000000101",
+		"history-0000000102-0000000103",
+		"history-0000000104-0000000105",
+	}
+
+	csvDir := t.TempDir()
+	if err := ExportHistory(g.Query(), csvDir, HistoryOptions{PartitionBlocks: 2}); err != nil {
+		t.Fatalf("export csv failed: %s", err)
+	}
+	var csvRows [][]string
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(csvDir, name+".csv"))
+		if err != nil {
+			t.Fatalf("partition missing: %s", err)
+		}
+		records, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatalf("read csv failed: %s", err)
+		}
+		if records[0][0] != "idx" || records[0][len(records[0])-1] != "block_time" {
+			t.Fatalf("header: %v", records[0])
+		}
+		csvRows = append(csvRows, records[1:]...)
+	}
+	if uint64(len(csvRows)) != uint64(g.HistoryCount) {
+		t.Fatalf("csv rows: %d, expected %d", len(csvRows), g.HistoryCount)
+	}
+
+	pqDir := t.TempDir()
+	if err := ExportHistory(g.Query(), pqDir, HistoryOptions{Format: FORMAT_PARQUET, PartitionBlocks: 2}); err != nil {
+		t.Fatalf("export parquet failed: %s", err)
+	}
+	var pqRows []HistoryRow
+	for _, name := range names {
+		rows, err := parquet.ReadFile[HistoryRow](filepath.Join(pqDir, name+".parquet"))
+		if err != nil {
+			t.Fatalf("read parquet failed: %s", err)
+		}
+		pqRows = append(pqRows, rows...)
+	}
+	if len(pqRows) != len(csvRows) {
+		t.Fatalf("parquet rows: %d, expected %d", len(pqRows), len(csvRows))
+	}
+	for i, row := range pqRows {
+		if record := csvRecord(row); record[1] != csvRows[i][1] || record[10] != csvRows[i][10] || record[11] != csvRows[i][11] {
+			t.Fatalf("row %d: %v, csv %v", i, record, csvRows[i])
+		}
+	}
+	if last := pqRows[len(pqRows)-1]; last.Type != "receive" || last.Amount != "30" || last.OverallBalance != "130" {
+		t.Fatalf("last row: %+v", last)
+	}
+}
+
+func TestExportModuleHistory(t *testing.T) {
+	g := indexertest.NewIndexer()
+	record := func(height, txIdx uint32, historyType uint8, inscriptionId string) *model.BRC20ModuleHistory {
+		return &model.BRC20ModuleHistory{
+			BRC20HistoryBase: model.BRC20HistoryBase{Type: historyType, Valid: true, Height: height, TxIdx: txIdx},
+			Inscription:      model.InscriptionBRC20SwapInfoResp{InscriptionId: inscriptionId},
+		}
+	}
+	withdraw := record(104, 1, constant.BRC20_HISTORY_MODULE_TYPE_N_WITHDRAW_FROM, "withdraw")
+	// same history in both lists, not the same pointer as loaded by gob
+	withdrawLoaded := *withdraw
+	g.ModulesInfoMap["module"] = &model.BRC20ModuleSwapInfo{
+		ID: "module",
+		History: []*model.BRC20ModuleHistory{
+			record(100, 0, constant.BRC20_HISTORY_MODULE_TYPE_N_INSCRIBE_MODULE, "module"),
+			withdraw,
+		},
+		UsersTokenBalanceDataMap: map[string]map[string]*model.BRC20ModuleTokenBalance{
+			indexertest.USER_A: {"ordi": {History: []*model.BRC20ModuleHistory{
+				record(102, 0, constant.BRC20_HISTORY_SWAP_TYPE_N_APPROVE_FROM, "approve"),
+				&withdrawLoaded,
+				record(104, 1, constant.BRC20_HISTORY_MODULE_TYPE_N_WITHDRAW_TO, "withdraw"),
+			}}},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := ExportHistory(g.Query(), dir, HistoryOptions{}); err != nil {
+		t.Fatalf("export failed: %s", err)
+	}
+	f, err := os.Open(filepath.Join(dir, "module_history-0000000000-0000009999.csv"))
+	if err != nil {
+		t.Fatalf("module history missing: %s", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv failed: %s", err)
+	}
+	var got []string
+	for _, record := range records[1:] {
+		got = append(got, record[1]+"@"+record[14])
+	}
+	if fmt.Sprint(got) != "[inscribe-module@100 approve-from@102 withdraw-from@104 withdraw-to@104]" {
+		t.Fatalf("unexpected module history: %v", got)
+	}
+}
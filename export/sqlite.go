@@ -0,0 +1,224 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	_ "modernc.org/sqlite"
+)
+
+// SQLITE_SCHEMA Tables of sqlite export. Amounts are decimal strings, as precision of tokens is up
+// to 18. Addresses are of the network, or hex of pkscript if not standard.
+const SQLITE_SCHEMA = `
+-- network, best_height, history_start, history_count
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+-- tokens deployed, ticker in lower case
+CREATE TABLE tokens (
+	ticker             TEXT PRIMARY KEY,
+	ticker_original    TEXT NOT NULL,
+	inscription_id     TEXT NOT NULL,
+	inscription_number INTEGER NOT NULL,
+	deployer           TEXT NOT NULL,
+	deploy_height      INTEGER NOT NULL,
+	max                TEXT NOT NULL,
+	lim                TEXT NOT NULL,
+	decimals           INTEGER NOT NULL,
+	self_mint          INTEGER NOT NULL,
+	minted             TEXT NOT NULL,
+	confirmed_minted   TEXT NOT NULL,
+	burned             TEXT NOT NULL,
+	mint_times         INTEGER NOT NULL,
+	holders            INTEGER NOT NULL,
+	history_count      INTEGER NOT NULL
+);
+
+-- balance of each address and token
+CREATE TABLE balances (
+	ticker         TEXT NOT NULL,
+	address        TEXT NOT NULL,
+	pkscript       TEXT NOT NULL,
+	overall        TEXT NOT NULL,
+	available      TEXT NOT NULL,
+	available_safe TEXT NOT NULL,
+	transferable   TEXT NOT NULL,
+	update_height  INTEGER NOT NULL,
+	PRIMARY KEY (ticker, pkscript)
+);
+CREATE INDEX balances_address ON balances (address);
+
+-- transfer inscriptions not sent yet
+CREATE TABLE valid_transfers (
+	inscription_id     TEXT PRIMARY KEY,
+	inscription_number INTEGER NOT NULL,
+	ticker             TEXT NOT NULL,
+	address            TEXT NOT NULL,
+	amount             TEXT NOT NULL,
+	height             INTEGER NOT NULL
+);
+CREATE INDEX valid_transfers_address ON valid_transfers (address);
+
+-- history decoded, idx is the index of history in indexer
+-- type: inscribe-deploy/inscribe-mint/inscribe-transfer/transfer/send/receive
+CREATE TABLE history (
+	idx                  INTEGER PRIMARY KEY,
+	type                 TEXT NOT NULL,
+	valid                INTEGER NOT NULL,
+	ticker               TEXT NOT NULL,
+	inscription_id       TEXT NOT NULL,
+	inscription_number   INTEGER NOT NULL,
+	txid                 TEXT NOT NULL,
+	vout                 INTEGER NOT NULL,
+	offset               INTEGER NOT NULL,
+	from_address         TEXT NOT NULL,
+	to_address           TEXT NOT NULL,
+	amount               TEXT NOT NULL,
+	overall_balance      TEXT NOT NULL,
+	available_balance    TEXT NOT NULL,
+	transferable_balance TEXT NOT NULL,
+	satoshi              INTEGER NOT NULL,
+	fee                  INTEGER NOT NULL,
+	height               INTEGER NOT NULL,
+	tx_idx               INTEGER NOT NULL,
+	block_time           INTEGER NOT NULL
+);
+CREATE INDEX history_ticker ON history (ticker, height);
+CREATE INDEX history_from ON history (from_address);
+CREATE INDEX history_to ON history (to_address);
+
+-- swap modules
+CREATE TABLE modules (
+	module_id     TEXT PRIMARY KEY,
+	name          TEXT NOT NULL,
+	deployer      TEXT NOT NULL,
+	sequencer     TEXT NOT NULL,
+	gas_to        TEXT NOT NULL,
+	lp_fee_to     TEXT NOT NULL,
+	fee_rate_swap TEXT NOT NULL,
+	gas_tick      TEXT NOT NULL
+);
+
+-- balance of each address and token in module
+CREATE TABLE module_balances (
+	module_id             TEXT NOT NULL,
+	ticker                TEXT NOT NULL,
+	address               TEXT NOT NULL,
+	pkscript              TEXT NOT NULL,
+	swap_balance          TEXT NOT NULL,
+	swap_balance_safe     TEXT NOT NULL,
+	module_balance_safe   TEXT NOT NULL,
+	available             TEXT NOT NULL,
+	available_safe        TEXT NOT NULL,
+	approveable           TEXT NOT NULL,
+	cond_approveable      TEXT NOT NULL,
+	ready_to_withdraw     TEXT NOT NULL,
+	PRIMARY KEY (module_id, ticker, pkscript)
+);
+
+-- pools of module, pair is tick0/tick1
+CREATE TABLE pools (
+	module_id   TEXT NOT NULL,
+	pair        TEXT NOT NULL,
+	tick0       TEXT NOT NULL,
+	tick1       TEXT NOT NULL,
+	reserve0    TEXT NOT NULL,
+	reserve1    TEXT NOT NULL,
+	lp_supply   TEXT NOT NULL,
+	last_root_k TEXT NOT NULL,
+	PRIMARY KEY (module_id, pair)
+);
+
+-- lp of each address and pool
+CREATE TABLE lp_balances (
+	module_id TEXT NOT NULL,
+	pair      TEXT NOT NULL,
+	address   TEXT NOT NULL,
+	pkscript  TEXT NOT NULL,
+	balance   TEXT NOT NULL,
+	PRIMARY KEY (module_id, pair, pkscript)
+);
+`
+
+// ExportSQLite Write state and history into a new sqlite database of SQLITE_SCHEMA. The file is
+// replaced only if completely written. Blocks are not processed while exporting.
+func ExportSQLite(q *indexer.Query, fname string) error {
+	log.Printf("exporting brc20 into sqlite ...")
+	tmpName := fname + ".tmp"
+	os.Remove(tmpName)
+	defer os.Remove(tmpName)
+
+	db, err := sql.Open("sqlite", tmpName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(SQLITE_SCHEMA); err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	w := &sqliteWriter{tx: tx, stmts: make(map[string]*sql.Stmt, 0)}
+	q.View(func(g *indexer.BRC20ModuleIndexer) {
+		err = writeState(w, g)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, fname); err != nil {
+		return err
+	}
+	log.Printf("export brc20 into sqlite ok, rows: %d", w.rows)
+	return nil
+}
+
+// sqliteWriter Insert rows by statements prepared once for each table.
+type sqliteWriter struct {
+	tx    *sql.Tx
+	stmts map[string]*sql.Stmt
+	rows  int
+}
+
+func (w *sqliteWriter) Write(table string, columns []string, values ...any) error {
+	stmt, ok := w.stmts[table]
+	if !ok {
+		query := "INSERT INTO " + table + " ("
+		params := ""
+		for i, column := range columns {
+			if i > 0 {
+				query += ", "
+				params += ", "
+			}
+			query += column
+			params += "?"
+		}
+		query += ") VALUES (" + params + ")"
+
+		var err error
+		if stmt, err = w.tx.Prepare(query); err != nil {
+			return fmt.Errorf("prepare %s: %w", table, err)
+		}
+		w.stmts[table] = stmt
+	}
+	if _, err := stmt.Exec(values...); err != nil {
+		return fmt.Errorf("insert %s: %w", table, err)
+	}
+	w.rows++
+	return nil
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/indexer/indexertest"
+)
+
+func TestExportSQLite(t *testing.T) {
+	g := indexertest.NewIndexer()
+	fname := filepath.Join(t.TempDir(), "brc20.db")
+	if err := ExportSQLite(g.Query(), fname); err != nil {
+		t.Fatalf("export failed: %s", err)
+	}
+
+	db, err := sql.Open("sqlite", fname)
+	if err != nil {
+		t.Fatalf("open failed: %s", err)
+	}
+	defer db.Close()
+
+	for query, want := range map[string]string{
+		"SELECT value FROM meta WHERE key = 'best_height'":                                        "104",
+		"SELECT minted || ' ' || holders FROM tokens WHERE ticker = 'ordi'":                       "200 2",
+		"SELECT count(*) FROM history":                                                            fmt.Sprint(g.HistoryCount),
+		"SELECT group_concat(overall, ' ') FROM (SELECT overall FROM balances ORDER BY pkscript)": "70 130",
+		"SELECT count(*) FROM history WHERE type = 'inscribe-mint' AND valid = 1":                 "2",
+	} {
+		var got string
+		if err := db.QueryRow(query).Scan(&got); err != nil {
+			t.Fatalf("%s: %s", query, err)
+		}
+		if got != want {
+			t.Fatalf("%s: %s, expected %s", query, got, want)
+		}
+	}
+}
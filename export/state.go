@@ -0,0 +1,206 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/view"
+)
+
+// Table Name and columns of a table, same as SQLITE_SCHEMA.
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+var (
+	TableMeta = Table{"meta", []string{"key", "value"}}
+
+	TableTokens = Table{"tokens", []string{
+		"ticker", "ticker_original", "inscription_id", "inscription_number", "deployer", "deploy_height",
+		"max", "lim", "decimals", "self_mint", "minted", "confirmed_minted", "burned", "mint_times",
+		"holders", "history_count"}}
+
+	TableBalances = Table{"balances", []string{
+		"ticker", "address", "pkscript", "overall", "available", "available_safe", "transferable",
+		"update_height"}}
+
+	TableValidTransfers = Table{"valid_transfers", []string{
+		"inscription_id", "inscription_number", "ticker", "address", "amount", "height"}}
+
+	TableHistory = Table{"history", []string{
+		"idx", "type", "valid", "ticker", "inscription_id", "inscription_number", "txid", "vout", "offset",
+		"from_address", "to_address", "amount", "overall_balance", "available_balance", "transferable_balance",
+		"satoshi", "fee", "height", "tx_idx", "block_time"}}
+
+	TableModules = Table{"modules", []string{
+		"module_id", "name", "deployer", "sequencer", "gas_to", "lp_fee_to", "fee_rate_swap", "gas_tick"}}
+
+	TableModuleBalances = Table{"module_balances", []string{
+		"module_id", "ticker", "address", "pkscript", "swap_balance", "swap_balance_safe",
+		"module_balance_safe", "available", "available_safe", "approveable", "cond_approveable",
+		"ready_to_withdraw"}}
+
+	TablePools = Table{"pools", []string{
+		"module_id", "pair", "tick0", "tick1", "reserve0", "reserve1", "lp_supply", "last_root_k"}}
+
+	TableLpBalances = Table{"lp_balances", []string{
+		"module_id", "pair", "address", "pkscript", "balance"}}
+
+	// Tables All tables, in order of export.
+	Tables = []Table{TableMeta, TableTokens, TableBalances, TableValidTransfers, TableHistory,
+		TableModules, TableModuleBalances, TablePools, TableLpBalances}
+)
+
+// rowWriter Destination of rows, values in order of columns of table.
+type rowWriter interface {
+	Write(table string, columns []string, values ...any) error
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// writeState Write rows of all tables, in order of keys.
+func writeState(w rowWriter, g *indexer.BRC20ModuleIndexer) error {
+	write := func(t Table, values ...any) error {
+		return w.Write(t.Name, t.Columns, values...)
+	}
+	params := g.NetParams
+
+	for _, kv := range [][2]string{
+		{"network", g.Rules.Network},
+		{"best_height", fmt.Sprint(g.BestHeight)},
+		{"history_start", fmt.Sprint(g.HistoryStart)},
+		{"history_count", fmt.Sprint(g.HistoryCount)},
+	} {
+		if err := write(TableMeta, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, ticker := range view.SortedKeys(g.InscriptionsTickerInfoMap) {
+		info := g.InscriptionsTickerInfoMap[ticker]
+		deploy := info.Deploy
+		err := write(TableTokens,
+			ticker, deploy.Tick, deploy.GetInscriptionId(), deploy.InscriptionNumber,
+			view.Address(deploy.PkScript, params), deploy.Height,
+			deploy.Max.String(), deploy.Limit.String(), deploy.Decimal, boolInt(deploy.SelfMint),
+			deploy.TotalMinted.String(), deploy.ConfirmedMinted.String(), deploy.Burned.String(), deploy.MintTimes,
+			g.TokenHolderCount(ticker), info.HistoryLen())
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	rangeErr := g.RangeUserBalances(func(pkScript string, userTokens map[string]*model.BRC20TokenBalance) bool {
+		address := view.Address(pkScript, params)
+		for _, ticker := range view.SortedKeys(userTokens) {
+			balance := userTokens[ticker]
+			err = write(TableBalances,
+				ticker, address, fmt.Sprintf("%x", pkScript), balance.OverallBalance().String(),
+				balance.AvailableBalance.String(), balance.AvailableBalanceSafe.String(),
+				balance.TransferableBalance.String(), balance.UpdateHeight)
+			if err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if rangeErr != nil {
+		return rangeErr
+	} else if err != nil {
+		return err
+	}
+
+	rangeErr = g.RangeValidTransfers(func(_ string, transfer *model.InscriptionBRC20TickInfo) bool {
+		err = write(TableValidTransfers,
+			transfer.GetInscriptionId(), transfer.InscriptionNumber, transfer.Tick,
+			view.Address(transfer.PkScript, params), transfer.Amount.String(), transfer.Height)
+		return err == nil
+	})
+	if rangeErr != nil {
+		return rangeErr
+	} else if err != nil {
+		return err
+	}
+
+	for idx := g.HistoryStart; idx < g.HistoryCount; idx++ {
+		data, ok := g.GetHistoryData(idx)
+		if !ok {
+			return fmt.Errorf("history %d missing", idx)
+		}
+		h := &model.BRC20History{}
+		h.Unmarshal(data)
+		r := view.DecodeHistory(idx, h, params)
+		err := write(TableHistory,
+			r.Idx, r.Type, boolInt(r.Valid), r.Ticker, r.InscriptionId, r.InscriptionNumber, r.TxId, r.Vout,
+			int64(r.Offset), r.FromAddress, r.ToAddress, r.Amount, r.OverallBalance, r.AvailableBalance,
+			r.TransferableBalance, int64(r.Satoshi), r.Fee, r.Height, r.TxIdx, r.BlockTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	rangeErr = g.RangeModules(func(_ string, m *model.BRC20ModuleSwapInfo) bool {
+		err = writeModule(write, m, g)
+		return err == nil
+	})
+	if rangeErr != nil {
+		return rangeErr
+	}
+	return err
+}
+
+func writeModule(write func(t Table, values ...any) error, m *model.BRC20ModuleSwapInfo, g *indexer.BRC20ModuleIndexer) error {
+	params := g.NetParams
+	err := write(TableModules,
+		m.ID, m.Name, view.Address(m.DeployerPkScript, params), view.Address(m.SequencerPkScript, params),
+		view.Address(m.GasToPkScript, params), view.Address(m.LpFeePkScript, params), m.FeeRateSwap, m.GasTick)
+	if err != nil {
+		return err
+	}
+
+	for _, pkScript := range view.SortedKeys(m.UsersTokenBalanceDataMap) {
+		address := view.Address(pkScript, params)
+		userTokens := m.UsersTokenBalanceDataMap[pkScript]
+		for _, ticker := range view.SortedKeys(userTokens) {
+			b := userTokens[ticker]
+			err := write(TableModuleBalances,
+				m.ID, ticker, address, fmt.Sprintf("%x", pkScript),
+				b.SwapAccountBalance.String(), b.SwapAccountBalanceSafe.String(), b.ModuleAccountBalanceSafe.String(),
+				b.AvailableBalance.String(), b.AvailableBalanceSafe.String(), b.ApproveableBalance.String(),
+				b.CondApproveableBalance.String(), b.ReadyToWithdrawAmount.String())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pair := range view.SortedKeys(m.SwapPoolTotalBalanceDataMap) {
+		pool := m.SwapPoolTotalBalanceDataMap[pair]
+		err := write(TablePools,
+			m.ID, pair, pool.Tick[0], pool.Tick[1], pool.TickBalance[0].String(), pool.TickBalance[1].String(),
+			pool.LpBalance.String(), pool.LastRootK.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pkScript := range view.SortedKeys(m.UsersLPTokenBalanceMap) {
+		address := view.Address(pkScript, params)
+		lps := m.UsersLPTokenBalanceMap[pkScript]
+		for _, pair := range view.SortedKeys(lps) {
+			err := write(TableLpBalances, m.ID, pair, address, fmt.Sprintf("%x", pkScript), lps[pair].String())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
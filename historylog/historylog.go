@@ -0,0 +1,391 @@
+package historylog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// segment files in dir, named by index of first record and height of segment:
+//
+//	<first>-<height>.seg records, concatenated
+//	<first>-<height>.idx end offset of each record in .seg, 8 bytes big endian
+const (
+	SEGMENT_NAME_FORMAT = "%020d-%010d"
+	SEGMENT_DATA_EXT    = ".seg"
+	SEGMENT_INDEX_EXT   = ".idx"
+
+	DEFAULT_SEGMENT_BLOCKS = 10000
+)
+
+var ErrNotFound = errors.New("history not found")
+
+// Options Config of log, zero values are the defaults.
+type Options struct {
+	SegmentBlocks uint32 // blocks of each segment, new segment on crossing a multiple of it
+}
+
+type segment struct {
+	first  uint64
+	height uint32
+	count  uint64
+	size   int64 // of data file
+	data   *os.File
+	index  *os.File
+}
+
+// Log Append-only history records in segment files. Only the index of segments is kept in
+// memory, records are read from files when needed.
+type Log struct {
+	dir           string
+	segmentBlocks uint32
+
+	mu       sync.RWMutex
+	segments []*segment
+	count    uint64
+}
+
+// Open Log in dir, created if not exists. Records partly written at the end are dropped.
+func Open(dir string, opts Options) (*Log, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, segmentBlocks: opts.SegmentBlocks}
+	if l.segmentBlocks == 0 {
+		l.segmentBlocks = DEFAULT_SEGMENT_BLOCKS
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"+SEGMENT_DATA_EXT))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		seg := &segment{}
+		base := filepath.Base(name)
+		if _, err := fmt.Sscanf(base, SEGMENT_NAME_FORMAT+SEGMENT_DATA_EXT, &seg.first, &seg.height); err != nil {
+			continue
+		}
+		l.segments = append(l.segments, seg)
+	}
+	sort.Slice(l.segments, func(i, j int) bool {
+		return l.segments[i].first < l.segments[j].first
+	})
+
+	for i, seg := range l.segments {
+		if err := l.openSegment(seg); err != nil {
+			l.Close()
+			return nil, err
+		}
+		if err := seg.load(i == len(l.segments)-1); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("segment %d: %w", seg.first, err)
+		}
+		if i > 0 && seg.first != l.count {
+			l.Close()
+			return nil, fmt.Errorf("segment %d not following history %d", seg.first, l.count)
+		}
+		l.count = seg.first + seg.count
+	}
+	return l, nil
+}
+
+func (l *Log) segmentPath(seg *segment) string {
+	return filepath.Join(l.dir, fmt.Sprintf(SEGMENT_NAME_FORMAT, seg.first, seg.height))
+}
+
+func (l *Log) openSegment(seg *segment) (err error) {
+	name := l.segmentPath(seg)
+	if seg.data, err = os.OpenFile(name+SEGMENT_DATA_EXT, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return err
+	}
+	if seg.index, err = os.OpenFile(name+SEGMENT_INDEX_EXT, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		seg.data.Close()
+		seg.data = nil
+		return err
+	}
+	return nil
+}
+
+// load Count and size of segment from files. The last segment is repaired, as appending
+// may be interrupted between writing record and its offset.
+func (seg *segment) load(last bool) error {
+	indexInfo, err := seg.index.Stat()
+	if err != nil {
+		return err
+	}
+	dataInfo, err := seg.data.Stat()
+	if err != nil {
+		return err
+	}
+	seg.count = uint64(indexInfo.Size() / 8)
+	seg.size = dataInfo.Size()
+
+	for seg.count > 0 {
+		end, err := seg.offset(seg.count - 1)
+		if err != nil {
+			return err
+		}
+		if end <= seg.size {
+			break
+		}
+		if !last {
+			return errors.New("records missing")
+		}
+		seg.count--
+	}
+
+	if !last {
+		return nil
+	}
+	end := int64(0)
+	if seg.count > 0 {
+		if end, err = seg.offset(seg.count - 1); err != nil {
+			return err
+		}
+	}
+	return seg.truncate(seg.count, end)
+}
+
+// offset End offset of record i of segment.
+func (seg *segment) offset(i uint64) (int64, error) {
+	var buf [8]byte
+	if _, err := seg.index.ReadAt(buf[:], int64(i*8)); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(buf[:])), nil
+}
+
+func (seg *segment) truncate(count uint64, size int64) error {
+	if err := seg.index.Truncate(int64(count * 8)); err != nil {
+		return err
+	}
+	if err := seg.data.Truncate(size); err != nil {
+		return err
+	}
+	seg.count = count
+	seg.size = size
+	return nil
+}
+
+func (seg *segment) close() {
+	if seg.data != nil {
+		seg.data.Close()
+	}
+	if seg.index != nil {
+		seg.index.Close()
+	}
+}
+
+// Count Number of records, index of the next record.
+func (l *Log) Count() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.count
+}
+
+// First Index of the first record in log, records before it are pruned.
+func (l *Log) First() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.segments) == 0 {
+		return l.count
+	}
+	return l.segments[0].first
+}
+
+// Append Add record of block height, a new segment is started if height is of the next segment.
+func (l *Log) Append(height uint32, data []byte) (idx uint64, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var seg *segment
+	if len(l.segments) > 0 {
+		seg = l.segments[len(l.segments)-1]
+	}
+	if seg == nil || height/l.segmentBlocks > seg.height/l.segmentBlocks {
+		// not written any more, see Sync
+		if seg != nil {
+			if err := seg.data.Sync(); err != nil {
+				return 0, err
+			}
+			if err := seg.index.Sync(); err != nil {
+				return 0, err
+			}
+		}
+		seg = &segment{first: l.count, height: height}
+		if err := l.openSegment(seg); err != nil {
+			return 0, err
+		}
+		// left by a truncate before
+		if err := seg.truncate(0, 0); err != nil {
+			seg.close()
+			return 0, err
+		}
+		l.segments = append(l.segments, seg)
+	}
+
+	if _, err := seg.data.WriteAt(data, seg.size); err != nil {
+		return 0, err
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seg.size+int64(len(data))))
+	if _, err := seg.index.WriteAt(buf[:], int64(seg.count*8)); err != nil {
+		return 0, err
+	}
+	seg.size += int64(len(data))
+	seg.count++
+
+	idx = l.count
+	l.count++
+	return idx, nil
+}
+
+// findSegment Segment of record idx.
+func (l *Log) findSegment(idx uint64) *segment {
+	i := sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].first > idx
+	})
+	if i == 0 {
+		return nil
+	}
+	return l.segments[i-1]
+}
+
+// Get Record of index, ErrNotFound if not in log.
+func (l *Log) Get(idx uint64) ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if idx >= l.count {
+		return nil, ErrNotFound
+	}
+	seg := l.findSegment(idx)
+	if seg == nil {
+		return nil, ErrNotFound
+	}
+
+	i := idx - seg.first
+	start := int64(0)
+	if i > 0 {
+		var err error
+		if start, err = seg.offset(i - 1); err != nil {
+			return nil, err
+		}
+	}
+	end, err := seg.offset(i)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, end-start)
+	if _, err := seg.data.ReadAt(data, start); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Truncate Drop records from count, segments left empty are removed.
+func (l *Log) Truncate(count uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if count >= l.count {
+		return nil
+	}
+	for len(l.segments) > 0 {
+		seg := l.segments[len(l.segments)-1]
+		if seg.first < count {
+			break
+		}
+		if err := l.removeSegment(seg); err != nil {
+			return err
+		}
+		l.segments = l.segments[:len(l.segments)-1]
+	}
+	l.count = count
+
+	if len(l.segments) == 0 {
+		return nil
+	}
+	seg := l.segments[len(l.segments)-1]
+	keep := count - seg.first
+	end, err := seg.offset(keep - 1)
+	if err != nil {
+		return err
+	}
+	return seg.truncate(keep, end)
+}
+
+// removeSegment Close and delete files of segment.
+func (l *Log) removeSegment(seg *segment) error {
+	seg.close()
+	name := l.segmentPath(seg)
+	if err := os.Remove(name + SEGMENT_DATA_EXT); err != nil {
+		return err
+	}
+	return os.Remove(name + SEGMENT_INDEX_EXT)
+}
+
+// PruneBefore Remove segments of records all before idx. Records of the segment with idx are kept.
+func (l *Log) PruneBefore(idx uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.segments) > 1 && l.segments[1].first <= idx {
+		if err := l.removeSegment(l.segments[0]); err != nil {
+			return err
+		}
+		l.segments = l.segments[1:]
+	}
+	if len(l.segments) == 1 && l.count <= idx {
+		if err := l.removeSegment(l.segments[0]); err != nil {
+			return err
+		}
+		l.segments = nil
+	}
+	return nil
+}
+
+// Reset Remove all records, the next record is of index count.
+func (l *Log) Reset(count uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.segments) > 0 {
+		if err := l.removeSegment(l.segments[len(l.segments)-1]); err != nil {
+			return err
+		}
+		l.segments = l.segments[:len(l.segments)-1]
+	}
+	l.count = count
+	return nil
+}
+
+// Sync Flush the last segment to disk, the others are not written any more.
+func (l *Log) Sync() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if len(l.segments) == 0 {
+		return nil
+	}
+	seg := l.segments[len(l.segments)-1]
+	if err := seg.data.Sync(); err != nil {
+		return err
+	}
+	return seg.index.Sync()
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, seg := range l.segments {
+		seg.close()
+	}
+	l.segments = nil
+	return nil
+}
@@ -0,0 +1,115 @@
+package historylog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func record(i uint64) []byte {
+	return []byte(fmt.Sprintf("history %d", i))
+}
+
+func checkRecords(t *testing.T, l *Log, count uint64) {
+	if l.Count() != count {
+		t.Fatalf("count %d, expected %d", l.Count(), count)
+	}
+	for i := uint64(0); i < count; i++ {
+		data, err := l.Get(i)
+		if err != nil || !bytes.Equal(data, record(i)) {
+			t.Fatalf("record %d: %q, %v", i, data, err)
+		}
+	}
+	if _, err := l.Get(count); err != ErrNotFound {
+		t.Fatalf("record after end: %v", err)
+	}
+}
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{SegmentBlocks: 10})
+	if err != nil {
+		t.Fatalf("open failed: %s", err)
+	}
+
+	// 3 records of each height 0..29, 3 segments
+	for i := uint64(0); i < 90; i++ {
+		if idx, err := l.Append(uint32(i/3), record(i)); err != nil || idx != i {
+			t.Fatalf("append %d: %d, %v", i, idx, err)
+		}
+	}
+	checkRecords(t, l, 90)
+	segs, _ := filepath.Glob(filepath.Join(dir, "*"+SEGMENT_DATA_EXT))
+	if len(segs) != 3 {
+		t.Fatalf("segments: %v", segs)
+	}
+
+	// rollback into the second segment, then append again
+	if err := l.Truncate(40); err != nil {
+		t.Fatalf("truncate failed: %s", err)
+	}
+	checkRecords(t, l, 40)
+	for i := uint64(40); i < 90; i++ {
+		l.Append(uint32(i/3), record(i))
+	}
+	l.Close()
+
+	// reopen, with a record partly written at the end
+	l, err = Open(dir, Options{SegmentBlocks: 10})
+	if err != nil {
+		t.Fatalf("reopen failed: %s", err)
+	}
+	checkRecords(t, l, 90)
+	last := l.segments[len(l.segments)-1]
+	last.data.WriteAt([]byte("partly"), last.size)
+	last.index.WriteAt(make([]byte, 4), int64(last.count*8))
+	l.Close()
+
+	l, err = Open(dir, Options{SegmentBlocks: 10})
+	if err != nil {
+		t.Fatalf("reopen failed: %s", err)
+	}
+	defer l.Close()
+	checkRecords(t, l, 90)
+
+	// missing segment
+	os.Remove(filepath.Join(dir, fmt.Sprintf(SEGMENT_NAME_FORMAT, 30, 10)+SEGMENT_DATA_EXT))
+	if _, err := Open(dir, Options{SegmentBlocks: 10}); err == nil {
+		t.Fatal("opened log with segment missing")
+	}
+}
+
+func TestPruneBefore(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{SegmentBlocks: 10})
+	if err != nil {
+		t.Fatalf("open failed: %s", err)
+	}
+	defer l.Close()
+	for i := uint64(0); i < 90; i++ {
+		l.Append(uint32(i/3), record(i))
+	}
+
+	// the segment of 45 is kept
+	if err := l.PruneBefore(45); err != nil {
+		t.Fatalf("prune failed: %s", err)
+	}
+	if l.First() != 30 || l.Count() != 90 {
+		t.Fatalf("log from %d to %d", l.First(), l.Count())
+	}
+	if _, err := l.Get(29); err != ErrNotFound {
+		t.Fatalf("record pruned: %v", err)
+	}
+	if data, _ := l.Get(30); !bytes.Equal(data, record(30)) {
+		t.Fatalf("record kept: %q", data)
+	}
+
+	if err := l.Reset(100); err != nil {
+		t.Fatalf("reset failed: %s", err)
+	}
+	if idx, _ := l.Append(40, record(100)); idx != 100 || l.First() != 100 {
+		t.Fatalf("append after reset: %d", idx)
+	}
+}
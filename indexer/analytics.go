@@ -0,0 +1,310 @@
+package indexer
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+const (
+	DEFAULT_TOP_HOLDERS = 100
+	CONCENTRATION_TOP_N = 10
+)
+
+// HolderShare Balance of holder, with share in percent of circulating supply.
+type HolderShare struct {
+	PkScript            string
+	OverallBalance      *decimal.Decimal
+	AvailableBalance    *decimal.Decimal
+	TransferableBalance *decimal.Decimal
+	Share               float64
+}
+
+// TickerStats Distribution of supply of ticker among holders at Height. Supply is minted less
+// burned, tokens burned to OP_RETURN are not of any holder.
+type TickerStats struct {
+	Ticker string
+	Height uint32
+
+	Max    *decimal.Decimal
+	Minted *decimal.Decimal
+	Burned *decimal.Decimal
+	Supply *decimal.Decimal
+
+	Holders                 int
+	TransferableOnlyHolders int // all balance in transfer inscriptions, available is zero
+
+	TopHolders []*HolderShare // by overall balance desc
+	TopShare   float64        // percent of supply held by top CONCENTRATION_TOP_N
+	Gini       float64        // 0 if all hold the same, near 1 if one holds all
+}
+
+// HolderCount Holders of ticker from block Height on.
+type HolderCount struct {
+	Height  uint32
+	Holders int
+}
+
+// isBurnPkScript Tokens sent to OP_RETURN are burned.
+func isBurnPkScript(pkScript string) bool {
+	return len(pkScript) == 1 && pkScript[0] == 0x6a
+}
+
+// decimalFloat Value of d, approximate.
+func decimalFloat(d *decimal.Decimal) float64 {
+	if d == nil {
+		return 0
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Precition)), nil)
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(d.Value), new(big.Float).SetInt(scale)).Float64()
+	return f
+}
+
+// percentOf Percent of amt in total, 0 if total is zero.
+func percentOf(amt, total *decimal.Decimal) float64 {
+	if total.Sign() <= 0 {
+		return 0
+	}
+	return decimalFloat(amt) / decimalFloat(total) * 100
+}
+
+// TickerStats Holders of ticker from TokenUsersBalanceData, with topN of them, 0 for default.
+func (g *BRC20ModuleIndexer) TickerStats(ticker string, topN int) (*TickerStats, error) {
+	if topN <= 0 {
+		topN = DEFAULT_TOP_HOLDERS
+	}
+	uniqueLowerTicker := strings.ToLower(ticker)
+	tokenInfo, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]
+	if !ok {
+		return nil, fmt.Errorf("ticker %s not found", ticker)
+	}
+	deploy := tokenInfo.Deploy
+	stats := &TickerStats{
+		Ticker: tokenInfo.Ticker,
+		Height: g.BestHeight,
+		Max:    decimal.NewDecimalCopy(deploy.Max),
+		Minted: decimal.NewDecimalCopy(deploy.TotalMinted),
+		Burned: decimal.NewDecimalCopy(deploy.Burned),
+		Supply: deploy.TotalMinted.Sub(deploy.Burned),
+	}
+
+	var holders []*model.BRC20TokenBalance
+	for _, balance := range g.HolderRanking(uniqueLowerTicker) {
+		if !isHolder(balance) {
+			continue
+		}
+		holders = append(holders, balance)
+		if balance.AvailableBalance.Sign() == 0 {
+			stats.TransferableOnlyHolders++
+		}
+	}
+	stats.Holders = len(holders)
+
+	topAmount := decimal.NewDecimal(0, uint(deploy.Decimal))
+	for i, balance := range holders {
+		overall := balance.OverallBalance()
+		if i < CONCENTRATION_TOP_N {
+			topAmount = topAmount.Add(overall)
+		}
+		if i < topN {
+			stats.TopHolders = append(stats.TopHolders, &HolderShare{
+				PkScript:            balance.PkScript,
+				OverallBalance:      overall,
+				AvailableBalance:    decimal.NewDecimalCopy(balance.AvailableBalance),
+				TransferableBalance: decimal.NewDecimalCopy(balance.TransferableBalance),
+				Share:               percentOf(overall, stats.Supply),
+			})
+		}
+	}
+	stats.TopShare = percentOf(topAmount, stats.Supply)
+	stats.Gini = gini(holders)
+	return stats, nil
+}
+
+// gini Gini coefficient of overall balances of holders sorted desc.
+func gini(holders []*model.BRC20TokenBalance) float64 {
+	n := len(holders)
+	if n == 0 {
+		return 0
+	}
+	// sum of (2i-n-1)*x over balances ascending, i from 1
+	var sum, weighted float64
+	for j, balance := range holders {
+		x := decimalFloat(balance.OverallBalance())
+		i := n - j
+		sum += x
+		weighted += float64(2*i-n-1) * x
+	}
+	if sum == 0 {
+		return 0
+	}
+	return weighted / (float64(n) * sum)
+}
+
+// HolderRanking All balances of ticker by overall balance desc, then pkscript. Built on first use
+// and kept until a balance of ticker changes. The list is of the state, not to be modified.
+func (g *BRC20ModuleIndexer) HolderRanking(ticker string) []*model.BRC20TokenBalance {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	g.holderRankingMu.Lock()
+	defer g.holderRankingMu.Unlock()
+	if balances, ok := g.holderRanking[uniqueLowerTicker]; ok {
+		return balances
+	}
+
+	holders := g.TokenHolders(uniqueLowerTicker)
+	balances := make([]*model.BRC20TokenBalance, 0, len(holders))
+	overall := make(map[*model.BRC20TokenBalance]*decimal.Decimal, len(holders))
+	for _, balance := range holders {
+		balances = append(balances, balance)
+		overall[balance] = balance.OverallBalance()
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if c := overall[balances[i]].Cmp(overall[balances[j]]); c != 0 {
+			return c > 0
+		}
+		return balances[i].PkScript < balances[j].PkScript
+	})
+
+	if g.holderRanking == nil {
+		g.holderRanking = make(map[string][]*model.BRC20TokenBalance, 0)
+	}
+	g.holderRanking[uniqueLowerTicker] = balances
+	return balances
+}
+
+// resetHolderRanking Drop ranking of ticker, or of all tickers if empty.
+func (g *BRC20ModuleIndexer) resetHolderRanking(tickers ...string) {
+	g.holderRankingMu.Lock()
+	defer g.holderRankingMu.Unlock()
+	if len(tickers) == 0 {
+		g.holderRanking = nil
+		return
+	}
+	for _, ticker := range tickers {
+		delete(g.holderRanking, ticker)
+	}
+}
+
+// isHolder Balance counted as holder of ticker.
+func isHolder(balance *model.BRC20TokenBalance) bool {
+	return !isBurnPkScript(balance.PkScript) && balance.OverallBalance().Sign() > 0
+}
+
+// touchHolderCount Balance of ticker changes in this block, keep if it held before.
+func (g *BRC20ModuleIndexer) touchHolderCount(balance *model.BRC20TokenBalance) {
+	if g.holderCountTouched == nil {
+		g.holderCountTouched = make(map[string]map[string]bool, 0)
+	}
+	uniqueLowerTicker := strings.ToLower(balance.Ticker)
+	g.resetHolderRanking(uniqueLowerTicker)
+	users, ok := g.holderCountTouched[uniqueLowerTicker]
+	if !ok {
+		users = make(map[string]bool, 0)
+		g.holderCountTouched[uniqueLowerTicker] = users
+	}
+	if _, ok := users[balance.PkScript]; !ok {
+		users[balance.PkScript] = isHolder(balance)
+	}
+}
+
+// updateHolderCounts Append holders of tickers changed in block of height, if the count changed.
+func (g *BRC20ModuleIndexer) updateHolderCounts(height uint32) {
+	if height == constant.MEMPOOL_HEIGHT {
+		return
+	}
+	if g.HolderCountSeries == nil {
+		g.HolderCountSeries = make(map[string][]HolderCount, 0)
+	}
+	for uniqueLowerTicker, users := range g.holderCountTouched {
+		counts := g.HolderCountSeries[uniqueLowerTicker]
+		holders := 0
+		if len(counts) > 0 {
+			holders = counts[len(counts)-1].Holders
+		}
+		for userPkScript, held := range users {
+			balance, ok := g.UserBalance(uniqueLowerTicker, userPkScript)
+			holding := ok && isHolder(balance)
+			if holding && !held {
+				holders++
+			} else if held && !holding {
+				holders--
+			}
+		}
+
+		switch {
+		case len(counts) > 0 && counts[len(counts)-1].Height == height:
+			// rest of a block stopped in the middle
+			counts[len(counts)-1].Holders = holders
+		case len(counts) == 0 || counts[len(counts)-1].Holders != holders:
+			counts = append(counts, HolderCount{Height: height, Holders: holders})
+		}
+		g.HolderCountSeries[uniqueLowerTicker] = counts
+		g.markStorageDirty(STORAGE_PREFIX_HOLDER_COUNT, uniqueLowerTicker)
+	}
+	g.holderCountTouched = nil
+}
+
+// rollbackHolderCounts Drop counts of blocks after height.
+func (g *BRC20ModuleIndexer) rollbackHolderCounts(height uint32) {
+	for uniqueLowerTicker, counts := range g.HolderCountSeries {
+		n := sort.Search(len(counts), func(i int) bool { return counts[i].Height > height })
+		if n == len(counts) {
+			continue
+		}
+		if n == 0 {
+			delete(g.HolderCountSeries, uniqueLowerTicker)
+		} else {
+			g.HolderCountSeries[uniqueLowerTicker] = counts[:n]
+		}
+		g.markStorageDirty(STORAGE_PREFIX_HOLDER_COUNT, uniqueLowerTicker)
+	}
+	g.holderCountTouched = nil
+}
+
+// seedHolderCounts Current holders of all tickers at best height, for state saved without counts.
+func (g *BRC20ModuleIndexer) seedHolderCounts() {
+	g.HolderCountSeries = make(map[string][]HolderCount, 0)
+	for uniqueLowerTicker, holders := range g.TokenUsersBalanceData {
+		n := 0
+		for _, balance := range holders {
+			if isHolder(balance) {
+				n++
+			}
+		}
+		g.HolderCountSeries[uniqueLowerTicker] = []HolderCount{{Height: g.BestHeight, Holders: n}}
+	}
+}
+
+// HolderCounts Holders of ticker after each block the count changed, kept by block. State saved
+// without counts starts at the height it was loaded. The list is of the state, not to be modified.
+func (g *BRC20ModuleIndexer) HolderCounts(ticker string) ([]HolderCount, error) {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	if _, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]; !ok {
+		return nil, fmt.Errorf("ticker %s not found", ticker)
+	}
+	return g.HolderCountSeries[uniqueLowerTicker], nil
+}
+
+// TickerStats Holders of ticker, see BRC20ModuleIndexer.TickerStats.
+func (q *Query) TickerStats(ticker string, topN int) (stats *TickerStats, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		stats, err = g.TickerStats(ticker, topN)
+	})
+	return stats, err
+}
+
+// HolderCounts Holders of ticker over blocks, see BRC20ModuleIndexer.HolderCounts.
+func (q *Query) HolderCounts(ticker string) (counts []HolderCount, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		var list []HolderCount
+		if list, err = g.HolderCounts(ticker); err == nil {
+			counts = append([]HolderCount{}, list...)
+		}
+	})
+	return counts, err
+}
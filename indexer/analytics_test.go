@@ -0,0 +1,63 @@
+package indexer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+func TestTickerStats(t *testing.T) {
+	g := newTestIndexer()
+	blocks := testBlocks()
+	// all balance of B in a transfer inscription
+	key := &model.NFTCreateIdxKey{Height: 106, IdxInBlock: 0}
+	blocks = append(blocks, []*model.InscriptionBRC20Data{{
+		TxId:         testTxId("tx-106-0"),
+		Satoshi:      546,
+		PkScript:     testUserB,
+		ContentBody:  []byte(`{"p":"brc-20","op":"transfer","tick":"ordi","amt":"60"}`),
+		CreateIdxKey: key.String(),
+		Height:       106,
+		BlockTime:    1700000106,
+	}})
+	processTestBlocks(g, blocks[:len(blocks)-1])
+	// ranking of the block before is not kept
+	if ranking := g.HolderRanking("ordi"); len(ranking) == 0 {
+		t.Fatal("empty ranking")
+	}
+	processTestBlocks(g, blocks[len(blocks)-1:])
+
+	stats, err := g.TickerStats("ORDI", 1)
+	if err != nil {
+		t.Fatalf("stats failed: %s", err)
+	}
+	got := fmt.Sprintf("%s %s %s %d %d", stats.Minted, stats.Burned, stats.Supply, stats.Holders, stats.TransferableOnlyHolders)
+	if got != "250 0 250 2 1" {
+		t.Fatalf("unexpected stats: %s", got)
+	}
+	if len(stats.TopHolders) != 1 || stats.TopHolders[0].PkScript != testUserA || stats.TopHolders[0].Share != 76 {
+		t.Fatalf("unexpected top holders: %+v", stats.TopHolders)
+	}
+	if stats.TopShare != 100 || math.Abs(stats.Gini-0.26) > 1e-9 {
+		t.Fatalf("unexpected concentration: %v %v", stats.TopShare, stats.Gini)
+	}
+
+	counts, err := g.HolderCounts("ordi")
+	if err != nil {
+		t.Fatalf("holder counts failed: %s", err)
+	}
+	if got := fmt.Sprint(counts); got != "[{100 0} {101 2}]" {
+		t.Fatalf("unexpected holder counts: %s", got)
+	}
+	if err := g.RollbackToHeight(100); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	if counts, _ := g.HolderCounts("ordi"); fmt.Sprint(counts) != "[{100 0}]" {
+		t.Fatalf("unexpected holder counts after rollback: %v", counts)
+	}
+	if _, err := g.TickerStats("none", 0); err == nil {
+		t.Fatal("stats of unknown ticker should fail")
+	}
+}
@@ -0,0 +1,410 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// ErrHistoryPruned Balance at the height depends on history pruned, attach archives to query.
+var ErrHistoryPruned = errors.New("history of height pruned")
+
+// BalanceAtHeight Balance of user after block Height. UpdateHeight is of the last change at or
+// before Height, 0 if never changed.
+type BalanceAtHeight struct {
+	Ticker       string
+	PkScript     string
+	Height       uint32
+	UpdateHeight uint32
+
+	OverallBalance      *decimal.Decimal
+	AvailableBalance    *decimal.Decimal
+	TransferableBalance *decimal.Decimal
+}
+
+// ModuleBalanceCheckpoint Balance in module after block Height, decimals only.
+type ModuleBalanceCheckpoint struct {
+	Height  uint32
+	Balance *model.BRC20ModuleTokenBalance
+}
+
+// BalanceAt Balance of user after block height, from the balances saved in the last history of
+// the user and ticker at or before height.
+func (g *BRC20ModuleIndexer) BalanceAt(userPkScript, ticker string, height uint32) (*BalanceAtHeight, error) {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	tokenInfo, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]
+	if !ok {
+		return nil, fmt.Errorf("ticker %s not found", ticker)
+	}
+	if !g.EnableHistory {
+		return nil, errors.New("history disabled")
+	}
+	// first history after height
+	end, err := g.firstHistoryAt(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	return g.balanceAt(tokenInfo, userPkScript, height, end)
+}
+
+func (g *BRC20ModuleIndexer) balanceAt(tokenInfo *model.BRC20TokenInfo, userPkScript string, height uint32, end uint64) (*BalanceAtHeight, error) {
+	precision := int(tokenInfo.Deploy.Decimal)
+	result := &BalanceAtHeight{
+		Ticker:              tokenInfo.Ticker,
+		PkScript:            userPkScript,
+		Height:              height,
+		OverallBalance:      decimal.NewDecimal(0, uint(precision)),
+		AvailableBalance:    decimal.NewDecimal(0, uint(precision)),
+		TransferableBalance: decimal.NewDecimal(0, uint(precision)),
+	}
+
+	var list []uint64
+	var pruned uint64
+	if balance, ok := g.UserBalance(tokenInfo.Ticker, userPkScript); ok {
+		list, pruned = balance.History, balance.HistoryPruned
+	}
+	n := sort.Search(len(list), func(i int) bool { return list[i] >= end })
+	if n == 0 {
+		// the last history at or before height is pruned
+		if pruned > 0 {
+			return nil, ErrHistoryPruned
+		}
+		return result, nil
+	}
+
+	data, ok := g.GetHistoryData(list[n-1])
+	if !ok {
+		return nil, fmt.Errorf("history %d missing", list[n-1])
+	}
+	h := &model.BRC20History{}
+	h.Unmarshal(data)
+
+	var err error
+	if result.OverallBalance, err = decimal.NewDecimalFromString(h.OverallBalance, precision); err != nil {
+		return nil, fmt.Errorf("history %d overall balance: %w", list[n-1], err)
+	}
+	if result.AvailableBalance, err = decimal.NewDecimalFromString(h.AvailableBalance, precision); err != nil {
+		return nil, fmt.Errorf("history %d available balance: %w", list[n-1], err)
+	}
+	if result.TransferableBalance, err = decimal.NewDecimalFromString(h.TransferableBalance, precision); err != nil {
+		return nil, fmt.Errorf("history %d transferable balance: %w", list[n-1], err)
+	}
+	// balances of valid inscribe-transfer are saved before amount is moved to transferable
+	if h.Type == constant.BRC20_HISTORY_TYPE_N_INSCRIBE_TRANSFER && h.Valid {
+		amt, err := decimal.NewDecimalFromString(h.Amount, precision)
+		if err != nil {
+			return nil, fmt.Errorf("history %d amount: %w", list[n-1], err)
+		}
+		result.AvailableBalance = result.AvailableBalance.Sub(amt)
+		result.TransferableBalance = result.TransferableBalance.Add(amt)
+	}
+	result.UpdateHeight = h.Height
+	return result, nil
+}
+
+// HoldersAt Balances of all holders of ticker after block height, by overall balance desc.
+func (g *BRC20ModuleIndexer) HoldersAt(ticker string, height uint32) ([]*BalanceAtHeight, error) {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	tokenInfo, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]
+	if !ok {
+		return nil, fmt.Errorf("ticker %s not found", ticker)
+	}
+	if !g.EnableHistory {
+		return nil, errors.New("history disabled")
+	}
+	end, err := g.firstHistoryAt(height + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var holders []*BalanceAtHeight
+	for _, userPkScript := range g.tokenHoldersEver(uniqueLowerTicker) {
+		balance, err := g.balanceAt(tokenInfo, userPkScript, height, end)
+		if err != nil {
+			return nil, err
+		}
+		if balance.OverallBalance.Sign() > 0 {
+			holders = append(holders, balance)
+		}
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if c := holders[i].OverallBalance.Cmp(holders[j].OverallBalance); c != 0 {
+			return c > 0
+		}
+		return holders[i].PkScript < holders[j].PkScript
+	})
+	return holders, nil
+}
+
+// tokenHoldersEver Users of ticker with a balance, even if the balance is zero now. The index
+// is built on first use, by readers too, then kept by addTokenHolderEver. Users of blocks rolled
+// back are not removed, their balances are zero at heights kept.
+func (g *BRC20ModuleIndexer) tokenHoldersEver(uniqueLowerTicker string) (users []string) {
+	g.holdersEverMu.Lock()
+	defer g.holdersEverMu.Unlock()
+	if g.holdersEver == nil {
+		g.holdersEver = make(map[string]map[string]struct{}, len(g.InscriptionsTickerInfoMap))
+		err := g.RangeUserBalances(func(userPkScript string, userTokens map[string]*model.BRC20TokenBalance) bool {
+			for ticker := range userTokens {
+				g.addTokenHolderEverLocked(ticker, userPkScript)
+			}
+			return true
+		})
+		if err != nil {
+			log.Printf("load balances from storage failed: %s", err)
+		}
+	}
+	for userPkScript := range g.holdersEver[uniqueLowerTicker] {
+		users = append(users, userPkScript)
+	}
+	return users
+}
+
+func (g *BRC20ModuleIndexer) addTokenHolderEver(uniqueLowerTicker, userPkScript string) {
+	g.holdersEverMu.Lock()
+	defer g.holdersEverMu.Unlock()
+	if g.holdersEver == nil {
+		return
+	}
+	g.addTokenHolderEverLocked(uniqueLowerTicker, userPkScript)
+}
+
+func (g *BRC20ModuleIndexer) addTokenHolderEverLocked(uniqueLowerTicker, userPkScript string) {
+	users, ok := g.holdersEver[uniqueLowerTicker]
+	if !ok {
+		users = make(map[string]struct{}, 0)
+		g.holdersEver[uniqueLowerTicker] = users
+	}
+	users[userPkScript] = struct{}{}
+}
+
+// markCheckpointModuleDirty Balances of module may change in this block.
+func (g *BRC20ModuleIndexer) markCheckpointModuleDirty(moduleId string) {
+	if !g.EnableBalanceCheckpoints {
+		return
+	}
+	if g.checkpointDirtyModules == nil {
+		g.checkpointDirtyModules = make(map[string]struct{}, 0)
+	}
+	g.checkpointDirtyModules[moduleId] = struct{}{}
+}
+
+// updateBalanceCheckpoints Save module balances changed in block height. All balances are saved
+// on the first block, as checkpoints start there.
+func (g *BRC20ModuleIndexer) updateBalanceCheckpoints(height uint32) {
+	if !g.EnableBalanceCheckpoints || height == constant.MEMPOOL_HEIGHT {
+		return
+	}
+	if g.ModuleBalanceCheckpoints == nil {
+		g.ModuleBalanceCheckpoints = make(map[string]map[string]map[string][]*ModuleBalanceCheckpoint, 0)
+	}
+
+	all := g.ModuleCheckpointsStart == 0
+	if all {
+		g.ModuleCheckpointsStart = height
+	}
+	update := func(moduleId string, moduleInfo *model.BRC20ModuleSwapInfo) bool {
+		for userPkScript, userTokens := range moduleInfo.UsersTokenBalanceDataMap {
+			for uniqueLowerTicker, balance := range userTokens {
+				if balance.UpdateHeight != height && !all {
+					continue
+				}
+				g.addBalanceCheckpoint(moduleId, userPkScript, uniqueLowerTicker, &ModuleBalanceCheckpoint{
+					Height:  balance.UpdateHeight,
+					Balance: checkpointBalance(balance),
+				})
+			}
+		}
+		return true
+	}
+	if all {
+		if err := g.RangeModules(update); err != nil {
+			log.Panicf("load modules from storage failed: %s", err)
+		}
+	} else {
+		for moduleId := range g.checkpointDirtyModules {
+			if moduleInfo, ok := g.moduleOf(moduleId); ok {
+				update(moduleId, moduleInfo)
+			}
+		}
+	}
+	g.checkpointDirtyModules = nil
+}
+
+func (g *BRC20ModuleIndexer) addBalanceCheckpoint(moduleId, userPkScript, uniqueLowerTicker string, checkpoint *ModuleBalanceCheckpoint) {
+	moduleUsers, ok := g.ModuleBalanceCheckpoints[moduleId]
+	if !ok {
+		moduleUsers = make(map[string]map[string][]*ModuleBalanceCheckpoint, 0)
+		g.ModuleBalanceCheckpoints[moduleId] = moduleUsers
+	}
+	userTokens, ok := moduleUsers[userPkScript]
+	if !ok {
+		userTokens = make(map[string][]*ModuleBalanceCheckpoint, 0)
+		moduleUsers[userPkScript] = userTokens
+	}
+	userTokens[uniqueLowerTicker] = append(userTokens[uniqueLowerTicker], checkpoint)
+	g.markStorageDirty(STORAGE_PREFIX_CHECKPOINT, moduleId)
+}
+
+// checkpointBalance Copy of decimals of balance.
+func checkpointBalance(balance *model.BRC20ModuleTokenBalance) *model.BRC20ModuleTokenBalance {
+	return &model.BRC20ModuleTokenBalance{
+		UpdateHeight: balance.UpdateHeight,
+
+		Tick:     balance.Tick,
+		PkScript: balance.PkScript,
+
+		SwapAccountBalanceSafe:   decimal.NewDecimalCopy(balance.SwapAccountBalanceSafe),
+		ModuleAccountBalanceSafe: decimal.NewDecimalCopy(balance.ModuleAccountBalanceSafe),
+
+		SwapAccountBalance:     decimal.NewDecimalCopy(balance.SwapAccountBalance),
+		AvailableBalanceSafe:   decimal.NewDecimalCopy(balance.AvailableBalanceSafe),
+		AvailableBalance:       decimal.NewDecimalCopy(balance.AvailableBalance),
+		ApproveableBalance:     decimal.NewDecimalCopy(balance.ApproveableBalance),
+		CondApproveableBalance: decimal.NewDecimalCopy(balance.CondApproveableBalance),
+		ReadyToWithdrawAmount:  decimal.NewDecimalCopy(balance.ReadyToWithdrawAmount),
+	}
+}
+
+// rollbackBalanceCheckpoints Drop checkpoints after height.
+func (g *BRC20ModuleIndexer) rollbackBalanceCheckpoints(height uint32) {
+	g.checkpointDirtyModules = nil
+	for moduleId := range g.ModuleBalanceCheckpoints {
+		g.markStorageDirty(STORAGE_PREFIX_CHECKPOINT, moduleId)
+	}
+	if g.ModuleCheckpointsStart > height {
+		// saved again from the next block
+		g.ModuleBalanceCheckpoints = nil
+		g.ModuleCheckpointsStart = 0
+		return
+	}
+	for _, moduleUsers := range g.ModuleBalanceCheckpoints {
+		for _, userTokens := range moduleUsers {
+			for uniqueLowerTicker, list := range userTokens {
+				n := sort.Search(len(list), func(i int) bool { return list[i].Height > height })
+				userTokens[uniqueLowerTicker] = list[:n]
+			}
+		}
+	}
+}
+
+// pruneBalanceCheckpoints Drop checkpoints before cutoffHeight, except the last of each balance
+// which is still the balance at cutoffHeight.
+func (g *BRC20ModuleIndexer) pruneBalanceCheckpoints(cutoffHeight uint32) {
+	if g.ModuleCheckpointsStart == 0 || cutoffHeight <= g.ModuleCheckpointsStart {
+		return
+	}
+	for moduleId, moduleUsers := range g.ModuleBalanceCheckpoints {
+		g.markStorageDirty(STORAGE_PREFIX_CHECKPOINT, moduleId)
+		for _, userTokens := range moduleUsers {
+			for uniqueLowerTicker, list := range userTokens {
+				n := sort.Search(len(list), func(i int) bool { return list[i].Height >= cutoffHeight })
+				if n > 1 {
+					userTokens[uniqueLowerTicker] = append([]*ModuleBalanceCheckpoint{}, list[n-1:]...)
+				}
+			}
+		}
+	}
+	g.ModuleCheckpointsStart = cutoffHeight
+}
+
+// ModuleBalanceAt Balance of user in module after block height, nil if none. Module history has
+// no balances, so only heights since checkpoints are enabled can be queried.
+func (g *BRC20ModuleIndexer) ModuleBalanceAt(moduleId, userPkScript, ticker string, height uint32) (*model.BRC20ModuleTokenBalance, error) {
+	if err := g.checkModuleBalanceAt(moduleId, height); err != nil {
+		return nil, err
+	}
+	list := g.ModuleBalanceCheckpoints[moduleId][userPkScript][strings.ToLower(ticker)]
+	return checkpointAt(list, height), nil
+}
+
+// ModuleHoldersAt Balances of all users of ticker in module after block height, by module
+// balance desc.
+func (g *BRC20ModuleIndexer) ModuleHoldersAt(moduleId, ticker string, height uint32) ([]*model.BRC20ModuleTokenBalance, error) {
+	if err := g.checkModuleBalanceAt(moduleId, height); err != nil {
+		return nil, err
+	}
+	uniqueLowerTicker := strings.ToLower(ticker)
+	var holders []*model.BRC20ModuleTokenBalance
+	for _, userTokens := range g.ModuleBalanceCheckpoints[moduleId] {
+		balance := checkpointAt(userTokens[uniqueLowerTicker], height)
+		if balance == nil {
+			continue
+		}
+		if balance.SwapAccountBalance.Sign() > 0 || balance.ModuleBalance().Sign() > 0 {
+			holders = append(holders, balance)
+		}
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if c := holders[i].ModuleBalance().Cmp(holders[j].ModuleBalance()); c != 0 {
+			return c > 0
+		}
+		if c := holders[i].SwapAccountBalance.Cmp(holders[j].SwapAccountBalance); c != 0 {
+			return c > 0
+		}
+		return holders[i].PkScript < holders[j].PkScript
+	})
+	return holders, nil
+}
+
+func (g *BRC20ModuleIndexer) checkModuleBalanceAt(moduleId string, height uint32) error {
+	if !g.EnableBalanceCheckpoints {
+		return errors.New("balance checkpoints disabled")
+	}
+	if _, ok := g.Module(moduleId); !ok {
+		return fmt.Errorf("module %s not found", moduleId)
+	}
+	if g.ModuleCheckpointsStart == 0 || height < g.ModuleCheckpointsStart {
+		return fmt.Errorf("balance checkpoints start at %d", g.ModuleCheckpointsStart)
+	}
+	return nil
+}
+
+// checkpointAt Copy of the last balance at or before height, nil if none.
+func checkpointAt(list []*ModuleBalanceCheckpoint, height uint32) *model.BRC20ModuleTokenBalance {
+	n := sort.Search(len(list), func(i int) bool { return list[i].Height > height })
+	if n == 0 {
+		return nil
+	}
+	return checkpointBalance(list[n-1].Balance)
+}
+
+// BalanceAt Balance of user after block height, see BRC20ModuleIndexer.BalanceAt.
+func (q *Query) BalanceAt(userPkScript, ticker string, height uint32) (balance *BalanceAtHeight, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		balance, err = g.BalanceAt(userPkScript, ticker, height)
+	})
+	return balance, err
+}
+
+// HoldersAt Holders of ticker after block height, see BRC20ModuleIndexer.HoldersAt.
+func (q *Query) HoldersAt(ticker string, height uint32) (holders []*BalanceAtHeight, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		holders, err = g.HoldersAt(ticker, height)
+	})
+	return holders, err
+}
+
+// ModuleBalanceAt Balance of user in module after block height, see
+// BRC20ModuleIndexer.ModuleBalanceAt.
+func (q *Query) ModuleBalanceAt(moduleId, userPkScript, ticker string, height uint32) (balance *model.BRC20ModuleTokenBalance, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		balance, err = g.ModuleBalanceAt(moduleId, userPkScript, ticker, height)
+	})
+	return balance, err
+}
+
+// ModuleHoldersAt Users of ticker in module after block height, see
+// BRC20ModuleIndexer.ModuleHoldersAt.
+func (q *Query) ModuleHoldersAt(moduleId, ticker string, height uint32) (holders []*model.BRC20ModuleTokenBalance, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		holders, err = g.ModuleHoldersAt(moduleId, ticker, height)
+	})
+	return holders, err
+}
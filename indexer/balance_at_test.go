@@ -0,0 +1,166 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+func TestBalanceAt(t *testing.T) {
+	g := newTestIndexer()
+	processTestBlocks(g, testBlocks())
+
+	for _, c := range []struct {
+		pkScript string
+		height   uint32
+		expected string
+	}{
+		{testUserA, 100, "0 0 0 100"},
+		{testUserB, 100, "0 0 0 0"},
+		{testUserA, 101, "100 100 0 101"},
+		{testUserA, 102, "150 90 60 102"},
+		{testUserA, 103, "90 90 0 103"},
+		{testUserB, 103, "160 160 0 103"},
+		{testUserB, 104, "160 60 100 104"},
+		{testUserA, 200, "190 190 0 105"},
+	} {
+		balance, err := g.BalanceAt(c.pkScript, "ORDI", c.height)
+		if err != nil {
+			t.Fatalf("balance at %d failed: %s", c.height, err)
+		}
+		got := fmt.Sprintf("%s %s %s %d", balance.OverallBalance, balance.AvailableBalance, balance.TransferableBalance, balance.UpdateHeight)
+		if got != c.expected {
+			t.Fatalf("balance of %x at %d: %s, expected %s", c.pkScript, c.height, got, c.expected)
+		}
+	}
+	if _, err := g.BalanceAt(testUserA, "none", 102); err == nil {
+		t.Fatal("balance of unknown ticker should fail")
+	}
+
+	holdersAt := func(height uint32) string {
+		holders, err := g.HoldersAt("ordi", height)
+		if err != nil {
+			t.Fatalf("holders at %d failed: %s", height, err)
+		}
+		var list []string
+		for _, h := range holders {
+			list = append(list, fmt.Sprintf("%s:%s", h.PkScript[2:], h.OverallBalance))
+		}
+		return strings.Join(list, " ")
+	}
+	for height, expected := range map[uint32]string{
+		99:  "",
+		101: "aaaa:100 bbbb:100",
+		103: "bbbb:160 aaaa:90",
+		105: "aaaa:190 bbbb:60",
+	} {
+		if got := holdersAt(height); got != expected {
+			t.Fatalf("holders at %d: %s, expected %s", height, got, expected)
+		}
+	}
+
+	// heights rolled back are the current state
+	if err := g.RollbackToHeight(103); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	if got := holdersAt(105); got != "bbbb:160 aaaa:90" {
+		t.Fatalf("holders after rollback: %s", got)
+	}
+}
+
+func TestModuleBalanceAt(t *testing.T) {
+	g := newTestIndexer()
+	g.EnableBalanceCheckpoints = true
+	moduleInfo := &model.BRC20ModuleSwapInfo{
+		ID:                       "module",
+		UsersTokenBalanceDataMap: make(map[string]map[string]*model.BRC20ModuleTokenBalance, 0),
+		TokenUsersBalanceDataMap: make(map[string]map[string]*model.BRC20ModuleTokenBalance, 0),
+	}
+	g.ModulesInfoMap[moduleInfo.ID] = moduleInfo
+
+	setBalance := func(height uint32, pkScript string, amt uint64) {
+		g.touchModule(moduleInfo)
+		g.undoModule(moduleInfo)
+		balance := g.getModuleUserTokenBalance(moduleInfo, "ordi", pkScript)
+		balance.AvailableBalance = decimal.NewDecimal(amt, 0)
+		balance.UpdateHeight = height
+	}
+	setBalance(99, testUserA, 50)
+	g.finishBlock(100)
+	setBalance(101, testUserA, 30)
+	setBalance(101, testUserB, 20)
+	g.finishBlock(101)
+	g.finishBlock(102)
+	setBalance(103, testUserB, 40)
+	g.finishBlock(103)
+
+	if _, err := g.ModuleBalanceAt("module", testUserA, "ordi", 99); err == nil {
+		t.Fatal("balance before checkpoints should fail")
+	}
+	balanceAt := func(pkScript string, height uint32) string {
+		balance, err := g.ModuleBalanceAt("module", pkScript, "ORDI", height)
+		if err != nil {
+			t.Fatalf("module balance at %d failed: %s", height, err)
+		}
+		if balance == nil {
+			return "none"
+		}
+		return balance.ModuleBalance().String()
+	}
+	for _, c := range []struct {
+		pkScript string
+		height   uint32
+		expected string
+	}{
+		{testUserA, 100, "50"},
+		{testUserA, 102, "30"},
+		{testUserB, 100, "none"},
+		{testUserB, 102, "20"},
+		{testUserB, 103, "40"},
+	} {
+		if got := balanceAt(c.pkScript, c.height); got != c.expected {
+			t.Fatalf("module balance of %x at %d: %s, expected %s", c.pkScript, c.height, got, c.expected)
+		}
+	}
+
+	holders, err := g.ModuleHoldersAt("module", "ordi", 103)
+	if err != nil || len(holders) != 2 || holders[0].PkScript != testUserB {
+		t.Fatalf("unexpected module holders: %v %v", holders, err)
+	}
+
+	g.rollbackBalanceCheckpoints(101)
+	if got := balanceAt(testUserB, 103); got != "20" {
+		t.Fatalf("module balance after rollback: %s", got)
+	}
+}
+
+func TestBalanceAtPruned(t *testing.T) {
+	g := newTestIndexer()
+	processTestBlocks(g, testBlocks())
+	if err := g.PruneHistory(103, ""); err != nil {
+		t.Fatalf("prune failed: %s", err)
+	}
+
+	// sats is deployed after the cutoff, nothing of it is pruned
+	balance, err := g.BalanceAt(testUserA, "sats", 104)
+	if err != nil || balance.OverallBalance.Sign() != 0 {
+		t.Fatalf("sats balance at 104: %v %v", balance, err)
+	}
+	if holders, err := g.HoldersAt("sats", 104); err != nil || len(holders) != 0 {
+		t.Fatalf("sats holders at 104: %v %v", holders, err)
+	}
+	if holders, err := g.HoldersAt("sats", 105); err != nil || len(holders) != 1 {
+		t.Fatalf("sats holders at 105: %v %v", holders, err)
+	}
+	// history of ordi after the cutoff is kept
+	if balance, err := g.BalanceAt(testUserB, "ordi", 104); err != nil || balance.OverallBalance.String() != "160" {
+		t.Fatalf("ordi balance at 104: %v %v", balance, err)
+	}
+	// history of ordi before the cutoff is pruned
+	if _, err := g.BalanceAt(testUserA, "ordi", 102); err != ErrHistoryPruned {
+		t.Fatalf("ordi balance at 102 should be pruned: %v", err)
+	}
+}
@@ -1,12 +1,10 @@
 package indexer
 
 import (
-	"errors"
 	"log"
 	"strconv"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
@@ -16,46 +14,42 @@ import (
 func (g *BRC20ModuleIndexer) ProcessDeploy(data *model.InscriptionBRC20Data) error {
 	body := new(model.InscriptionBRC20DeployContent)
 	if err := body.Unmarshal(data.ContentBody); err != nil {
-		return nil
+		return newQuietRejectError(constant.BRC20_REJECT_JSON_INVALID, "deploy, json invalid")
 	}
 
 	// check tick
 	uniqueLowerTicker, err := utils.GetValidUniqueLowerTickerTicker(body.BRC20Tick)
 	if err != nil {
-		return nil
-		// return errors.New("deploy, tick length not 4 or 5")
+		return newQuietRejectError(constant.BRC20_REJECT_TICK_LENGTH, "deploy, tick length not 4 or 5")
 	}
 
 	if len(body.BRC20Tick) == 5 {
 		if body.BRC20SelfMint != "true" {
-			return nil
-			// return errors.New("deploy, tick length 5, but not self_mint")
+			return newQuietRejectError(constant.BRC20_REJECT_SELF_MINT_DISABLED, "deploy, tick length 5, but not self_mint")
 		}
-		if data.Height < conf.ENABLE_SELF_MINT_HEIGHT {
-			return nil
-			// return errors.New("deploy, tick length 5, but not enabled")
+		if data.Height < g.Rules.SelfMintHeight {
+			return newQuietRejectError(constant.BRC20_REJECT_SELF_MINT_DISABLED, "deploy, tick length 5, but not enabled")
 		}
 	}
 
 	// tick enable, fixme: test only, not support space in ticker
-	if conf.TICKS_ENABLED != "" {
+	if g.TicksEnabled != "" {
 		if strings.Contains(uniqueLowerTicker, " ") {
-			return nil
+			return newQuietRejectError(constant.BRC20_REJECT_TICK_NOT_ENABLED, "deploy, tick not enabled")
 		}
-		if !strings.Contains(conf.TICKS_ENABLED, uniqueLowerTicker) {
-			return nil
+		if !strings.Contains(g.TicksEnabled, uniqueLowerTicker) {
+			return newQuietRejectError(constant.BRC20_REJECT_TICK_NOT_ENABLED, "deploy, tick not enabled")
 		}
 	}
 
 	if _, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]; ok { // dup ticker
-		return nil
-		// return errors.New("deploy, but tick exist")
+		return newQuietRejectError(constant.BRC20_REJECT_DUP_DEPLOY, "deploy, but tick exist")
 	}
 	if body.BRC20Max == "" { // without max
 		log.Printf("deploy, but max missing. ticker: %s",
 			uniqueLowerTicker,
 		)
-		return errors.New("deploy, but max missing")
+		return newRejectError(constant.BRC20_REJECT_MAX_INVALID, "deploy, but max missing")
 	}
 
 	tinfo := model.NewInscriptionBRC20TickInfo(body.BRC20Tick, body.Operation, data)
@@ -77,7 +71,7 @@ func (g *BRC20ModuleIndexer) ProcessDeploy(data *model.InscriptionBRC20Data) err
 			uniqueLowerTicker,
 			tinfo.Data.BRC20Decimal,
 		)
-		return errors.New("deploy, but dec invalid")
+		return newRejectError(constant.BRC20_REJECT_DEC_INVALID, "deploy, but dec invalid")
 	} else {
 		tinfo.Decimal = uint8(dec)
 	}
@@ -89,18 +83,17 @@ func (g *BRC20ModuleIndexer) ProcessDeploy(data *model.InscriptionBRC20Data) err
 			uniqueLowerTicker,
 			body.BRC20Max,
 		)
-		return errors.New("deploy, but max invalid")
+		return newRejectError(constant.BRC20_REJECT_MAX_INVALID, "deploy, but max invalid")
 	} else {
 		if max.Sign() < 0 || max.IsOverflowUint64() {
-			return nil
-			// return errors.New("deploy, but max invalid (range)")
+			return newQuietRejectError(constant.BRC20_REJECT_MAX_INVALID, "deploy, but max invalid (range)")
 		}
 
 		if max.Sign() == 0 {
 			if tinfo.SelfMint {
 				tinfo.Max = max.GetMaxUint64()
 			} else {
-				return errors.New("deploy, but max invalid (0)")
+				return newRejectError(constant.BRC20_REJECT_MAX_INVALID, "deploy, but max invalid (0)")
 			}
 		} else {
 			tinfo.Max = max
@@ -114,16 +107,16 @@ func (g *BRC20ModuleIndexer) ProcessDeploy(data *model.InscriptionBRC20Data) err
 			uniqueLowerTicker,
 			tinfo.Data.BRC20Limit,
 		)
-		return errors.New("deploy, but lim invalid")
+		return newRejectError(constant.BRC20_REJECT_LIM_INVALID, "deploy, but lim invalid")
 	} else {
 		if lim.Sign() < 0 || lim.IsOverflowUint64() {
-			return errors.New("deploy, but lim invalid (range)")
+			return newRejectError(constant.BRC20_REJECT_LIM_INVALID, "deploy, but lim invalid (range)")
 		}
 		if lim.Sign() == 0 {
 			if tinfo.SelfMint {
 				tinfo.Limit = lim.GetMaxUint64()
 			} else {
-				return errors.New("deploy, but lim invalid (0)")
+				return newRejectError(constant.BRC20_REJECT_LIM_INVALID, "deploy, but lim invalid (0)")
 			}
 		} else {
 			tinfo.Limit = lim
@@ -131,9 +124,13 @@ func (g *BRC20ModuleIndexer) ProcessDeploy(data *model.InscriptionBRC20Data) err
 	}
 
 	tokenInfo := &model.BRC20TokenInfo{Ticker: body.BRC20Tick, Deploy: tinfo}
+	undoMapEntry(g, g.InscriptionsTickerInfoMap, uniqueLowerTicker)
 	g.InscriptionsTickerInfoMap[uniqueLowerTicker] = tokenInfo
+	g.markStateTickDirty(uniqueLowerTicker)
+	g.markStorageDirty(STORAGE_PREFIX_TICK, uniqueLowerTicker)
 
 	tokenBalance := &model.BRC20TokenBalance{Ticker: body.BRC20Tick, PkScript: data.PkScript}
+	g.touchHolderCount(tokenBalance)
 
 	if g.EnableHistory {
 		historyObj := model.NewBRC20History(constant.BRC20_HISTORY_TYPE_N_INSCRIBE_DEPLOY, true, false, tinfo, nil, data)
@@ -154,19 +151,27 @@ func (g *BRC20ModuleIndexer) ProcessDeploy(data *model.InscriptionBRC20Data) err
 
 	// init user tokens
 	var userTokens map[string]*model.BRC20TokenBalance
-	if tokens, ok := g.UserTokensBalanceData[string(data.PkScript)]; !ok {
+	if tokens, ok := g.userTokensOf(string(data.PkScript)); !ok {
 		userTokens = make(map[string]*model.BRC20TokenBalance, 0)
+		undoMapEntry(g, g.UserTokensBalanceData, string(data.PkScript))
 		g.UserTokensBalanceData[string(data.PkScript)] = userTokens
 	} else {
 		userTokens = tokens
 	}
+	undoMapEntry(g, userTokens, uniqueLowerTicker)
 	userTokens[uniqueLowerTicker] = tokenBalance
+	g.markStorageDirty(STORAGE_PREFIX_BALANCE, string(data.PkScript))
 
 	// init token users
 	tokenUsers := make(map[string]*model.BRC20TokenBalance, 0)
 	tokenUsers[string(data.PkScript)] = tokenBalance
+	undoMapEntry(g, g.TokenUsersBalanceData, uniqueLowerTicker)
 	g.TokenUsersBalanceData[uniqueLowerTicker] = tokenUsers
 
+	undoMapEntry(g, g.InscriptionsValidBRC20DataMap, data.CreateIdxKey)
 	g.InscriptionsValidBRC20DataMap[data.CreateIdxKey] = tinfo.Data
+	g.markStorageDirty(STORAGE_PREFIX_VALID, data.CreateIdxKey)
+
+	g.notify(func(o Observer) { o.OnTokenDeployed(data.Height, tokenInfo) })
 	return nil
 }
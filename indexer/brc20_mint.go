@@ -1,7 +1,6 @@
 package indexer
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
@@ -14,34 +13,34 @@ import (
 func (g *BRC20ModuleIndexer) ProcessMint(data *model.InscriptionBRC20Data) error {
 	body := new(model.InscriptionBRC20MintTransferContent)
 	if err := body.Unmarshal(data.ContentBody); err != nil {
-		return nil
+		return newQuietRejectError(constant.BRC20_REJECT_JSON_INVALID, "mint, json invalid")
 	}
 
 	// check tick
 	uniqueLowerTicker, err := utils.GetValidUniqueLowerTickerTicker(body.BRC20Tick)
 	if err != nil {
-		return nil
-		// return errors.New("mint, tick length not 4 or 5")
+		return newQuietRejectError(constant.BRC20_REJECT_TICK_LENGTH, "mint, tick length not 4 or 5")
 	}
 	tokenInfo, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]
 	if !ok {
-		return nil
-		// return errors.New(fmt.Sprintf("mint %s, but tick not exist", body.BRC20Tick))
+		return newQuietRejectError(constant.BRC20_REJECT_TICK_NOT_EXIST, fmt.Sprintf("mint %s, but tick not exist", body.BRC20Tick))
 	}
+	g.touchTokenInfo(tokenInfo)
+	g.undoTokenInfo(tokenInfo)
 	tinfo := tokenInfo.Deploy
 	if tinfo.SelfMint {
 		if utils.DecodeInscriptionFromBin(data.Parent) != tinfo.GetInscriptionId() {
-			return errors.New(fmt.Sprintf("self mint %s, but parent invalid", body.BRC20Tick))
+			return newRejectError(constant.BRC20_REJECT_SELF_MINT_PARENT, fmt.Sprintf("self mint %s, but parent invalid", body.BRC20Tick))
 		}
 	}
 
 	// check mint amount
 	amt, err := decimal.NewDecimalFromString(body.BRC20Amount, int(tinfo.Decimal))
 	if err != nil {
-		return errors.New(fmt.Sprintf("mint %s, but invalid amount(%s)", body.BRC20Tick, body.BRC20Amount))
+		return newRejectError(constant.BRC20_REJECT_AMOUNT_INVALID, fmt.Sprintf("mint %s, but invalid amount(%s)", body.BRC20Tick, body.BRC20Amount))
 	}
 	if amt.Sign() <= 0 || amt.Cmp(tinfo.Limit) > 0 {
-		return errors.New(fmt.Sprintf("mint %s, invalid amount(%s), limit(%s)", body.BRC20Tick, body.BRC20Amount, tinfo.Limit))
+		return newRejectError(constant.BRC20_REJECT_OVER_LIMIT, fmt.Sprintf("mint %s, invalid amount(%s), limit(%s)", body.BRC20Tick, body.BRC20Amount, tinfo.Limit))
 	}
 
 	// get user's tokens to update
@@ -64,7 +63,7 @@ func (g *BRC20ModuleIndexer) ProcessMint(data *model.InscriptionBRC20Data) error
 			tokenInfo.History = append(tokenInfo.History, history)
 			tokenInfo.HistoryMint = append(tokenInfo.HistoryMint, history)
 		}
-		return errors.New(fmt.Sprintf("mint %s, but mint out", body.BRC20Tick))
+		return newRejectError(constant.BRC20_REJECT_MINT_OUT, fmt.Sprintf("mint %s, but mint out", body.BRC20Tick))
 	}
 
 	// update tinfo
@@ -131,5 +130,7 @@ func (g *BRC20ModuleIndexer) ProcessMint(data *model.InscriptionBRC20Data) error
 		g.AllHistory = append(g.AllHistory, history)
 	}
 	// g.InscriptionsValidBRC20DataMap[data.CreateIdxKey] = mintInfo.Data
+
+	g.notify(func(o Observer) { o.OnBalanceChanged(data.Height, data.PkScript, tokenBalance) })
 	return nil
 }
@@ -1,11 +1,10 @@
 package indexer
 
 import (
-	"errors"
+	"fmt"
 	"log"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
@@ -16,9 +15,9 @@ func (g *BRC20ModuleIndexer) GetTransferInfoByKey(createIdxKey string) (
 	transferInfo *model.InscriptionBRC20TickInfo, isInvalid bool) {
 	var ok bool
 	// transfer
-	transferInfo, ok = g.InscriptionsValidTransferMap[createIdxKey]
+	transferInfo, ok = peekEntry(g, STORAGE_PREFIX_TRANSFER, g.InscriptionsValidTransferMap, createIdxKey, decodeGob[*model.InscriptionBRC20TickInfo])
 	if !ok {
-		transferInfo, ok = g.InscriptionsInvalidTransferMap[createIdxKey]
+		transferInfo, ok = peekEntry(g, STORAGE_PREFIX_INVALID_TRANSFER, g.InscriptionsInvalidTransferMap, createIdxKey, decodeGob[*model.InscriptionBRC20TickInfo])
 		if !ok {
 			transferInfo = nil
 		} else {
@@ -42,8 +41,10 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 		log.Printf("ProcessBRC20Transfer send transfer, but ticker invalid. txid: %s",
 			utils.HashString([]byte(data.TxId)),
 		)
-		return errors.New("transfer, invalid ticker")
+		return newRejectError(constant.BRC20_REJECT_TICK_NOT_EXIST, "transfer, invalid ticker")
 	}
+	g.touchTokenInfo(tokenInfo)
+	g.undoTokenInfo(tokenInfo)
 
 	// to
 	senderPkScript := string(transferInfo.PkScript)
@@ -68,13 +69,13 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 
 	// from
 	// get user's tokens to update
-	fromUserTokens, ok := g.UserTokensBalanceData[senderPkScript]
+	fromUserTokens, ok := g.userTokensOf(senderPkScript)
 	if !ok {
 		log.Printf("ProcessBRC20Transfer send from user missing. height: %d, txidx: %d",
 			data.Height,
 			data.TxIdx,
 		)
-		return errors.New("transfer, invalid from data")
+		return newRejectError(constant.BRC20_REJECT_SENDER_MISSING, "transfer, invalid from data")
 	}
 	// get tokenBalance to update
 	fromTokenBalance, ok := fromUserTokens[uniqueLowerTicker]
@@ -83,8 +84,10 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 			data.Height,
 			data.TxIdx,
 		)
-		return errors.New("transfer, invalid from balance")
+		return newRejectError(constant.BRC20_REJECT_SENDER_MISSING, "transfer, invalid from balance")
 	}
+	g.touchTokenBalance(fromTokenBalance)
+	g.undoTokenBalance(fromTokenBalance)
 
 	if isInvalid {
 		if g.EnableHistory {
@@ -105,7 +108,7 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 			data.Height,
 			data.TxIdx,
 		)
-		return errors.New("transfer, invalid transfer")
+		return newRejectError(constant.BRC20_REJECT_DUP_TRANSFER, "transfer, invalid transfer")
 	}
 
 	// set from
@@ -152,6 +155,12 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 		userHistoryTo.History = append(userHistoryTo.History, toHistory)
 	}
 
+	g.notify(func(o Observer) {
+		o.OnTransferSpent(data.Height, transferInfo, receiverPkScript)
+		o.OnBalanceChanged(data.Height, senderPkScript, fromTokenBalance)
+		o.OnBalanceChanged(data.Height, receiverPkScript, tokenBalance)
+	})
+
 	////////////////////////////////////////////////////////////////
 	// skip module deposit if self mint for now
 	if tokenInfo.Deploy.SelfMint {
@@ -165,7 +174,7 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 		g.ThisTxId = data.TxId
 	}
 
-	if data.Height < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if data.Height < g.Rules.SwapWithdrawHeight {
 		inscriptionId := transferInfo.Meta.GetInscriptionId()
 		events := g.GenerateApproveEventsByTransfer(inscriptionId, transferInfo.Tick, senderPkScript, receiverPkScript, transferInfo.Amount)
 		if err := g.ProcessConditionalApproveEvents(events); err != nil {
@@ -179,11 +188,13 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 		// errors.New("module transfer, not module")
 		return nil
 	}
-	moduleInfo, ok := g.ModulesInfoMap[moduleId]
+	moduleInfo, ok := g.moduleOf(moduleId)
 	if !ok { // invalid module
 		return nil
 		// return errors.New(fmt.Sprintf("module transfer, module(%s) not exist", moduleId))
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
 	// global history
 	mHistory := model.NewBRC20ModuleHistory(true, constant.BRC20_HISTORY_TYPE_N_TRANSFER, transferInfo.Meta, data, nil, true)
@@ -191,7 +202,7 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 
 	// get user's tokens to update
 
-	moduleTokenBalance := moduleInfo.GetUserTokenBalance(transferInfo.Tick, senderPkScript)
+	moduleTokenBalance := g.getModuleUserTokenBalance(moduleInfo, transferInfo.Tick, senderPkScript)
 	moduleTokenBalance.UpdateHeight = data.Height
 	// set module deposit
 	if data.BlockTime > 0 { // how many confirmes ok
@@ -200,49 +211,51 @@ func (g *BRC20ModuleIndexer) ProcessTransfer(data *model.InscriptionBRC20Data, t
 	moduleTokenBalance.SwapAccountBalance = moduleTokenBalance.SwapAccountBalance.Add(transferInfo.Amount)
 
 	// record state
-	stateBalance := moduleInfo.GetTickConditionalApproveStateBalance(transferInfo.Tick)
+	stateBalance := g.getModuleConditionalApproveStateBalance(moduleInfo, transferInfo.Tick)
 	stateBalance.BalanceDeposite = stateBalance.BalanceDeposite.Add(transferInfo.Amount)
 
+	g.notify(func(o Observer) {
+		o.OnModuleDeposit(data.Height, moduleId, transferInfo.Tick, senderPkScript, transferInfo.Amount)
+	})
 	return nil
 }
 
 func (g *BRC20ModuleIndexer) ProcessInscribeTransfer(data *model.InscriptionBRC20Data) error {
 	body := new(model.InscriptionBRC20MintTransferContent)
 	if err := body.Unmarshal(data.ContentBody); err != nil {
-		return nil
+		return newQuietRejectError(constant.BRC20_REJECT_JSON_INVALID, "transfer, json invalid")
 	}
 
 	// check tick
 	uniqueLowerTicker, err := utils.GetValidUniqueLowerTickerTicker(body.BRC20Tick)
 	if err != nil {
-		return nil
-		// return errors.New("transfer, tick length not 4 or 5")
+		return newQuietRejectError(constant.BRC20_REJECT_TICK_LENGTH, "transfer, tick length not 4 or 5")
 	}
 
 	tokenInfo, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]
 	if !ok {
-		return nil
-		// return errors.New(fmt.Sprintf("transfer %s, but tick not exist", body.BRC20Tick))
+		return newQuietRejectError(constant.BRC20_REJECT_TICK_NOT_EXIST, fmt.Sprintf("transfer %s, but tick not exist", body.BRC20Tick))
 	}
+	g.touchTokenInfo(tokenInfo)
+	g.undoTokenInfo(tokenInfo)
 	tinfo := tokenInfo.Deploy
 
 	// check amount
 	amt, err := decimal.NewDecimalFromString(body.BRC20Amount, int(tinfo.Decimal))
 	if err != nil {
-		return nil
-		// return errors.New("transfer, but invalid amount")
+		return newQuietRejectError(constant.BRC20_REJECT_AMOUNT_INVALID, "transfer, but invalid amount")
 	}
 	if amt.Sign() <= 0 || amt.Cmp(tinfo.Max) > 0 {
-		return nil
-		// return errors.New("transfer, invalid amount(range)")
+		return newQuietRejectError(constant.BRC20_REJECT_AMOUNT_INVALID, "transfer, invalid amount(range)")
 	}
 
 	balanceTransfer := decimal.NewDecimalCopy(amt)
 
 	// get user's tokens to update
 	var userTokens map[string]*model.BRC20TokenBalance
-	if tokens, ok := g.UserTokensBalanceData[string(data.PkScript)]; !ok {
+	if tokens, ok := g.userTokensOf(string(data.PkScript)); !ok {
 		userTokens = make(map[string]*model.BRC20TokenBalance, 0)
+		undoMapEntry(g, g.UserTokensBalanceData, string(data.PkScript))
 		g.UserTokensBalanceData[string(data.PkScript)] = userTokens
 	} else {
 		userTokens = tokens
@@ -251,15 +264,19 @@ func (g *BRC20ModuleIndexer) ProcessInscribeTransfer(data *model.InscriptionBRC2
 	var tokenBalance *model.BRC20TokenBalance
 	if token, ok := userTokens[uniqueLowerTicker]; !ok {
 		tokenBalance = &model.BRC20TokenBalance{Ticker: tokenInfo.Ticker, PkScript: data.PkScript}
+		undoMapEntry(g, userTokens, uniqueLowerTicker)
 		userTokens[uniqueLowerTicker] = tokenBalance
 	} else {
 		tokenBalance = token
 	}
+	g.touchTokenBalance(tokenBalance)
+	g.undoTokenBalance(tokenBalance)
 	// set token's users
 	tokenUsers, ok := g.TokenUsersBalanceData[uniqueLowerTicker]
 	if !ok {
 		log.Panicf("g.TokenUsersBalanceData[%s] not exist, tick: %s", uniqueLowerTicker, uniqueLowerTicker)
 	}
+	undoMapEntry(g, tokenUsers, string(data.PkScript))
 	tokenUsers[string(data.PkScript)] = tokenBalance
 
 	body.BRC20Tick = tokenInfo.Ticker
@@ -308,7 +325,12 @@ func (g *BRC20ModuleIndexer) ProcessInscribeTransfer(data *model.InscriptionBRC2
 
 	// If use the safe version of the available balance, it will cause the unconfirmed balance to not be able to be used to create a valid transfer inscription.
 	if tokenBalance.AvailableBalance.Cmp(balanceTransfer) < 0 {
+		undoMapEntry(g, g.InscriptionsInvalidTransferMap, data.CreateIdxKey)
 		g.InscriptionsInvalidTransferMap[data.CreateIdxKey] = transferInfo
+		g.markStorageDirty(STORAGE_PREFIX_INVALID_TRANSFER, data.CreateIdxKey)
+		// rejected, but kept as invalid transfer
+		g.Durty = true
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, "transfer, insufficient balance")
 	} else {
 		// Update available balance
 
@@ -322,9 +344,17 @@ func (g *BRC20ModuleIndexer) ProcessInscribeTransfer(data *model.InscriptionBRC2
 			tokenBalance.ValidTransferMap = make(map[string]*model.InscriptionBRC20TickInfo, 1)
 		}
 		tokenBalance.ValidTransferMap[data.CreateIdxKey] = transferInfo
+		undoMapEntry(g, g.InscriptionsValidTransferMap, data.CreateIdxKey)
+		undoMapEntry(g, g.InscriptionsValidBRC20DataMap, data.CreateIdxKey)
 		g.InscriptionsValidTransferMap[data.CreateIdxKey] = transferInfo
 		g.InscriptionsValidBRC20DataMap[data.CreateIdxKey] = transferInfo.Data
+		g.markStorageDirty(STORAGE_PREFIX_TRANSFER, data.CreateIdxKey)
+		g.markStorageDirty(STORAGE_PREFIX_VALID, data.CreateIdxKey)
 	}
 
+	g.notify(func(o Observer) {
+		o.OnTransferCreated(data.Height, transferInfo)
+		o.OnBalanceChanged(data.Height, data.PkScript, tokenBalance)
+	})
 	return nil
 }
@@ -0,0 +1,232 @@
+package indexer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// StateDiff A value differs between two states, empty if not exist in the state.
+type StateDiff struct {
+	Module   string
+	Ticker   string
+	PkScript string
+	Field    string
+	A        string
+	B        string
+}
+
+func (d *StateDiff) String() string {
+	return fmt.Sprintf("module=%s ticker=%s pkscript=%s %s: %s | %s",
+		d.Module, d.Ticker, hex.EncodeToString([]byte(d.PkScript)), d.Field, d.A, d.B)
+}
+
+type stateDiffer struct {
+	module   string
+	ticker   string
+	pkScript string
+	diffs    []StateDiff
+}
+
+func (d *stateDiffer) add(field, a, b string) {
+	if a == b {
+		return
+	}
+	d.diffs = append(d.diffs, StateDiff{
+		Module:   d.module,
+		Ticker:   d.ticker,
+		PkScript: d.pkScript,
+		Field:    field,
+		A:        a,
+		B:        b,
+	})
+}
+
+func (d *stateDiffer) addDecimal(field string, a, b *decimal.Decimal) {
+	d.add(field, a.String(), b.String())
+}
+
+// addSet Members in only one of the sets, as field[member].
+func addSet[V any](d *stateDiffer, field string, a, b map[string]V, name func(key string, v V) string) {
+	for _, key := range unionKeys(a, b) {
+		va, inA := a[key]
+		vb, inB := b[key]
+		if inA && inB {
+			continue
+		}
+		if inA {
+			d.add(fmt.Sprintf("%s[%s]", field, name(key, va)), "yes", "")
+		} else {
+			d.add(fmt.Sprintf("%s[%s]", field, name(key, vb)), "", "yes")
+		}
+	}
+}
+
+func unionKeys[V any](a, b map[string]V) (keys []string) {
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func existence(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return ""
+}
+
+func keyName[V any](key string, v V) string {
+	return key
+}
+
+func transferName(key string, info *model.InscriptionBRC20TickInfo) string {
+	if info != nil && info.Meta != nil {
+		return info.Meta.GetInscriptionId()
+	}
+	return hex.EncodeToString([]byte(key))
+}
+
+// diffEntries All entries of m and storage of g.
+func diffEntries[V any](g *BRC20ModuleIndexer, prefix string, m map[string]V, decode func([]byte) (V, error)) map[string]V {
+	all, err := allEntries(g, prefix, m, decode)
+	if err != nil {
+		log.Panicf("load %s from storage failed: %s", prefix, err)
+	}
+	return all
+}
+
+// DiffStates Differences of state a and b: supply and balances of tokens, valid transfers, and
+// balances, pools, lp and commits of modules. History is not compared.
+func DiffStates(a, b *BRC20ModuleIndexer) []StateDiff {
+	d := &stateDiffer{}
+	d.add("best_height", fmt.Sprint(a.BestHeight), fmt.Sprint(b.BestHeight))
+	d.add("history_count", fmt.Sprint(a.HistoryCount), fmt.Sprint(b.HistoryCount))
+
+	// tokens
+	for _, ticker := range unionKeys(a.InscriptionsTickerInfoMap, b.InscriptionsTickerInfoMap) {
+		d.ticker = ticker
+		infoA, inA := a.InscriptionsTickerInfoMap[ticker]
+		infoB, inB := b.InscriptionsTickerInfoMap[ticker]
+		if !inA || !inB {
+			d.add("deploy", existence(inA), existence(inB))
+			continue
+		}
+		d.addDecimal("max", infoA.Deploy.Max, infoB.Deploy.Max)
+		d.addDecimal("minted", infoA.Deploy.TotalMinted, infoB.Deploy.TotalMinted)
+		d.addDecimal("burned", infoA.Deploy.Burned, infoB.Deploy.Burned)
+		d.add("mint_times", fmt.Sprint(infoA.Deploy.MintTimes), fmt.Sprint(infoB.Deploy.MintTimes))
+	}
+
+	// balances
+	balancesA, balancesB := diffEntries(a, STORAGE_PREFIX_BALANCE, a.UserTokensBalanceData, decodeGob[map[string]*model.BRC20TokenBalance]),
+		diffEntries(b, STORAGE_PREFIX_BALANCE, b.UserTokensBalanceData, decodeGob[map[string]*model.BRC20TokenBalance])
+	for _, pkScript := range unionKeys(balancesA, balancesB) {
+		d.pkScript = pkScript
+		tokensA, tokensB := balancesA[pkScript], balancesB[pkScript]
+		for _, ticker := range unionKeys(tokensA, tokensB) {
+			d.ticker = ticker
+			balanceA, balanceB := tokensA[ticker], tokensB[ticker]
+			if balanceA == nil || balanceB == nil {
+				d.add("balance", existence(balanceA != nil), existence(balanceB != nil))
+				continue
+			}
+			d.addDecimal("available", balanceA.AvailableBalance, balanceB.AvailableBalance)
+			d.addDecimal("available_safe", balanceA.AvailableBalanceSafe, balanceB.AvailableBalanceSafe)
+			d.addDecimal("transferable", balanceA.TransferableBalance, balanceB.TransferableBalance)
+			addSet(d, "valid_transfer", balanceA.ValidTransferMap, balanceB.ValidTransferMap, transferName)
+		}
+	}
+	d.ticker, d.pkScript = "", ""
+
+	// modules
+	modulesA, modulesB := diffEntries(a, STORAGE_PREFIX_MODULE, a.ModulesInfoMap, decodeModule),
+		diffEntries(b, STORAGE_PREFIX_MODULE, b.ModulesInfoMap, decodeModule)
+	for _, moduleId := range unionKeys(modulesA, modulesB) {
+		d.module = moduleId
+		moduleA, inA := modulesA[moduleId]
+		moduleB, inB := modulesB[moduleId]
+		if !inA || !inB {
+			d.add("module", existence(inA), existence(inB))
+			continue
+		}
+		diffModule(d, moduleA, moduleB)
+	}
+	return d.diffs
+}
+
+func diffModule(d *stateDiffer, a, b *model.BRC20ModuleSwapInfo) {
+	for _, pkScript := range unionKeys(a.UsersTokenBalanceDataMap, b.UsersTokenBalanceDataMap) {
+		d.pkScript = pkScript
+		tokensA, tokensB := a.UsersTokenBalanceDataMap[pkScript], b.UsersTokenBalanceDataMap[pkScript]
+		for _, ticker := range unionKeys(tokensA, tokensB) {
+			d.ticker = ticker
+			balanceA, balanceB := tokensA[ticker], tokensB[ticker]
+			if balanceA == nil || balanceB == nil {
+				d.add("module_balance", existence(balanceA != nil), existence(balanceB != nil))
+				continue
+			}
+			d.addDecimal("swap_balance", balanceA.SwapAccountBalance, balanceB.SwapAccountBalance)
+			d.addDecimal("swap_balance_safe", balanceA.SwapAccountBalanceSafe, balanceB.SwapAccountBalanceSafe)
+			d.addDecimal("module_balance_safe", balanceA.ModuleAccountBalanceSafe, balanceB.ModuleAccountBalanceSafe)
+			d.addDecimal("module_available", balanceA.AvailableBalance, balanceB.AvailableBalance)
+			d.addDecimal("module_available_safe", balanceA.AvailableBalanceSafe, balanceB.AvailableBalanceSafe)
+			d.addDecimal("approveable", balanceA.ApproveableBalance, balanceB.ApproveableBalance)
+			d.addDecimal("cond_approveable", balanceA.CondApproveableBalance, balanceB.CondApproveableBalance)
+			d.addDecimal("ready_to_withdraw", balanceA.ReadyToWithdrawAmount, balanceB.ReadyToWithdrawAmount)
+		}
+	}
+	d.ticker, d.pkScript = "", ""
+
+	// pools, by pair as ticker
+	for _, pair := range unionKeys(a.SwapPoolTotalBalanceDataMap, b.SwapPoolTotalBalanceDataMap) {
+		d.ticker = pair
+		poolA, poolB := a.SwapPoolTotalBalanceDataMap[pair], b.SwapPoolTotalBalanceDataMap[pair]
+		if poolA == nil || poolB == nil {
+			d.add("pool", existence(poolA != nil), existence(poolB != nil))
+			continue
+		}
+		for i := 0; i < 2; i++ {
+			d.addDecimal(fmt.Sprintf("reserve[%s]", poolA.Tick[i]), poolA.TickBalance[i], poolB.TickBalance[i])
+		}
+		d.addDecimal("lp_supply", poolA.LpBalance, poolB.LpBalance)
+	}
+
+	for _, pkScript := range unionKeys(a.UsersLPTokenBalanceMap, b.UsersLPTokenBalanceMap) {
+		d.pkScript = pkScript
+		lpsA, lpsB := a.UsersLPTokenBalanceMap[pkScript], b.UsersLPTokenBalanceMap[pkScript]
+		for _, pair := range unionKeys(lpsA, lpsB) {
+			d.ticker = pair
+			d.addDecimal("lp", lpsA[pair], lpsB[pair])
+		}
+	}
+	d.ticker, d.pkScript = "", ""
+
+	addSet(d, "commit", a.CommitIdMap, b.CommitIdMap, keyName[struct{}])
+	addSet(d, "invalid_commit", a.CommitInvalidMap, b.CommitInvalidMap, keyName[struct{}])
+	addSet(d, "commit_chain", a.CommitIdChainMap, b.CommitIdChainMap, keyName[struct{}])
+}
+
+// DiffSnapshots Differences of two snapshot files, loaded by indexers of opts, in memory.
+func DiffSnapshots(fileA, fileB string, opts Options) ([]StateDiff, error) {
+	opts.Storage, opts.HistoryLog = nil, nil
+	a := New(opts)
+	if err := a.LoadSnapshot(fileA); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileA, err)
+	}
+	b := New(opts)
+	if err := b.LoadSnapshot(fileB); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileB, err)
+	}
+	return DiffStates(a, b), nil
+}
@@ -0,0 +1,47 @@
+package indexer
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	a := newTestIndexer()
+	processTestBlocks(a, testBlocks())
+	b := newTestIndexer()
+	processTestBlocks(b, testBlocks()[:4])
+
+	dir := t.TempDir()
+	fileA, fileB := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := a.SaveSnapshot(fileA); err != nil {
+		t.Fatalf("save snapshot failed: %s", err)
+	}
+	if err := b.SaveSnapshot(fileB); err != nil {
+		t.Fatalf("save snapshot failed: %s", err)
+	}
+
+	if diffs, err := DiffSnapshots(fileA, fileA, Options{}); err != nil || len(diffs) != 0 {
+		t.Fatalf("same snapshot differs: %v, %v", diffs, err)
+	}
+
+	diffs, err := DiffSnapshots(fileA, fileB, Options{})
+	if err != nil {
+		t.Fatalf("diff failed: %s", err)
+	}
+	var lines []string
+	for _, d := range diffs {
+		lines = append(lines, d.String())
+	}
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"best_height: 105 | 103",
+		"ticker=sats pkscript= deploy: yes | ",
+		"ticker=ordi pkscript=" + hex.EncodeToString([]byte(testUserB)) + " available: 60 | 160",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("difference %q not found in:\n%s", want, got)
+		}
+	}
+}
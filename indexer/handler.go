@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"log"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// InscribeHandler Process an inscribe of its protocol and operation. Return *RejectError
+// for a typed outcome.
+type InscribeHandler func(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) error
+
+// MoveResult Inscription moved and the result, for its outcome.
+type MoveResult struct {
+	InscriptionId string
+	Proto         string
+	Operation     string
+	Ticker        string
+	Err           error
+}
+
+// MoveHandler Process a move of inscription. Return nil if the inscription is not of the handler,
+// the next handler is tried then.
+type MoveHandler func(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult
+
+// HandlerRegistry Handlers of inscribe by protocol and operation, and of move in order. The same
+// handlers run on speculative copies of the indexer, see BRC20ModuleIndexer.Speculative.
+type HandlerRegistry struct {
+	inscribe map[string]map[string]InscribeHandler // [proto][op]handler
+	move     []MoveHandler
+}
+
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{
+		inscribe: make(map[string]map[string]InscribeHandler, 0),
+	}
+}
+
+// DefaultHandlerRegistry Registry of built-in brc-20, brc20-module and brc20-swap operations.
+func DefaultHandlerRegistry() *HandlerRegistry {
+	r := NewHandlerRegistry()
+
+	r.RegisterInscribe(constant.BRC20_P, constant.BRC20_OP_DEPLOY, (*BRC20ModuleIndexer).ProcessDeploy)
+	r.RegisterInscribe(constant.BRC20_P, constant.BRC20_OP_MINT, (*BRC20ModuleIndexer).ProcessMint)
+	r.RegisterInscribe(constant.BRC20_P, constant.BRC20_OP_TRANSFER, (*BRC20ModuleIndexer).ProcessInscribeTransfer)
+	r.RegisterInscribe(constant.BRC20_P_MODULE, constant.BRC20_OP_MODULE_DEPLOY, (*BRC20ModuleIndexer).ProcessCreateModule)
+	r.RegisterInscribe(constant.BRC20_P_MODULE, constant.BRC20_OP_MODULE_WITHDRAW, (*BRC20ModuleIndexer).ProcessInscribeWithdraw)
+	r.RegisterInscribe(constant.BRC20_P_SWAP, constant.BRC20_OP_SWAP_APPROVE, (*BRC20ModuleIndexer).ProcessInscribeApprove)
+	r.RegisterInscribe(constant.BRC20_P_SWAP, constant.BRC20_OP_SWAP_CONDITIONAL_APPROVE, (*BRC20ModuleIndexer).ProcessInscribeConditionalApprove)
+	r.RegisterInscribe(constant.BRC20_P_SWAP, constant.BRC20_OP_SWAP_COMMIT, (*BRC20ModuleIndexer).ProcessInscribeCommit)
+
+	// conditional approve moves at any sequence, others at first move only
+	r.RegisterMove(moveConditionalApprove)
+	r.RegisterMove(moveTransfer)
+	r.RegisterMove(moveApprove)
+	r.RegisterMove(moveWithdraw)
+	r.RegisterMove(moveCommit)
+	return r
+}
+
+// RegisterInscribe Set handler of inscribe of proto and op, replacing a registered one.
+func (r *HandlerRegistry) RegisterInscribe(proto, op string, handler InscribeHandler) {
+	ops, ok := r.inscribe[proto]
+	if !ok {
+		ops = make(map[string]InscribeHandler, 0)
+		r.inscribe[proto] = ops
+	}
+	ops[op] = handler
+}
+
+// RegisterMove Add handler of move, after the registered ones.
+func (r *HandlerRegistry) RegisterMove(handler MoveHandler) {
+	r.move = append(r.move, handler)
+}
+
+// inscribeHandler Handler of proto and op, protoKnown false if no op of proto registered.
+func (r *HandlerRegistry) inscribeHandler(proto, op string) (handler InscribeHandler, protoKnown bool) {
+	ops, ok := r.inscribe[proto]
+	if !ok {
+		return nil, false
+	}
+	return ops[op], true
+}
+
+func moveConditionalApprove(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult {
+	if data.Height >= g.Rules.SwapWithdrawHeight {
+		return nil
+	}
+	condApproveInfo, isInvalid := g.GetConditionalApproveInfoByKey(data.CreateIdxKey)
+	if condApproveInfo == nil {
+		return nil
+	}
+
+	err := g.ProcessConditionalApprove(data, condApproveInfo, isInvalid)
+	if err != nil {
+		log.Printf("process conditional approve move failed: %s", err)
+	} else {
+		g.Durty = true
+	}
+	return &MoveResult{condApproveInfo.Data.GetInscriptionId(), constant.BRC20_P_SWAP,
+		constant.BRC20_OP_SWAP_CONDITIONAL_APPROVE, condApproveInfo.Tick, moveOutcomeError(err, isInvalid)}
+}
+
+func moveTransfer(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult {
+	if data.Sequence != 1 {
+		return nil
+	}
+	transferInfo, isInvalid := g.GetTransferInfoByKey(data.CreateIdxKey)
+	if transferInfo == nil {
+		return nil
+	}
+
+	undoMapEntry(g, g.InscriptionsTransferRemoveMap, data.CreateIdxKey)
+	g.InscriptionsTransferRemoveMap[data.CreateIdxKey] = data.Height
+	g.Durty = true
+
+	err := g.ProcessTransfer(data, transferInfo, isInvalid)
+	if err != nil {
+		log.Printf("process transfer move failed: %s", err)
+	}
+	return &MoveResult{transferInfo.GetInscriptionId(), constant.BRC20_P,
+		constant.BRC20_OP_TRANSFER, transferInfo.Tick, moveOutcomeError(err, isInvalid)}
+}
+
+func moveApprove(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult {
+	if data.Sequence != 1 {
+		return nil
+	}
+	approveInfo, isInvalid := g.GetApproveInfoByKey(data.CreateIdxKey)
+	if approveInfo == nil {
+		return nil
+	}
+
+	undoMapEntry(g, g.InscriptionsApproveRemoveMap, data.CreateIdxKey)
+	g.InscriptionsApproveRemoveMap[data.CreateIdxKey] = data.Height
+	g.Durty = true
+
+	err := g.ProcessApprove(data, approveInfo, isInvalid)
+	if err != nil {
+		log.Printf("process approve move failed: %s", err)
+	}
+	return &MoveResult{approveInfo.Data.GetInscriptionId(), constant.BRC20_P_SWAP,
+		constant.BRC20_OP_SWAP_APPROVE, approveInfo.Tick, moveOutcomeError(err, isInvalid)}
+}
+
+func moveWithdraw(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult {
+	if data.Sequence != 1 {
+		return nil
+	}
+	withdrawInfo := g.GetWithdrawInfoByKey(data.CreateIdxKey)
+	if withdrawInfo == nil {
+		return nil
+	}
+
+	undoMapEntry(g, g.InscriptionsWithdrawRemoveMap, data.CreateIdxKey)
+	g.InscriptionsWithdrawRemoveMap[data.CreateIdxKey] = data.Height
+	g.Durty = true
+
+	err := g.ProcessWithdraw(data, withdrawInfo)
+	if err != nil {
+		log.Printf("process withdraw move failed: %s", err)
+	} else {
+		undoMapEntry(g, g.InscriptionsValidWithdrawMap, withdrawInfo.Data.GetInscriptionId())
+		g.InscriptionsValidWithdrawMap[withdrawInfo.Data.GetInscriptionId()] = data.Height
+	}
+	return &MoveResult{withdrawInfo.Data.GetInscriptionId(), constant.BRC20_P_MODULE,
+		constant.BRC20_OP_MODULE_WITHDRAW, withdrawInfo.Tick, err}
+}
+
+func moveCommit(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult {
+	if data.Sequence != 1 {
+		return nil
+	}
+	commitFrom, isInvalid := g.GetCommitInfoByKey(data.CreateIdxKey)
+	if commitFrom == nil {
+		return nil
+	}
+
+	undoMapEntry(g, g.InscriptionsCommitRemoveMap, data.CreateIdxKey)
+	g.InscriptionsCommitRemoveMap[data.CreateIdxKey] = data.Height
+	g.Durty = true
+
+	err := g.ProcessCommit(commitFrom, data, isInvalid)
+	if err != nil {
+		log.Printf("process commit move failed: %s", err)
+	}
+	return &MoveResult{commitFrom.GetInscriptionId(), constant.BRC20_P_SWAP,
+		constant.BRC20_OP_SWAP_COMMIT, "", err}
+}
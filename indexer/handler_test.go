@@ -0,0 +1,95 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+func TestHandlerRegistry(t *testing.T) {
+	notes := make(map[string]string) // [createIdxKey]pkScript
+	r := DefaultHandlerRegistry()
+	r.RegisterInscribe("exp-note", "note", func(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) error {
+		notes[data.CreateIdxKey] = data.PkScript
+		return nil
+	})
+	r.RegisterMove(func(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) *MoveResult {
+		if _, ok := notes[data.CreateIdxKey]; !ok {
+			return nil
+		}
+		notes[data.CreateIdxKey] = data.PkScript
+		return &MoveResult{InscriptionId: data.GetInscriptionId(), Proto: "exp-note", Operation: "note"}
+	})
+
+	// built-in ops on the same registry
+	g := New(Options{Handlers: r, EnableOutcomes: true})
+	processTestBlocks(g, testBlocks())
+	full := newTestIndexer()
+	full.EnableOutcomes = true
+	processTestBlocks(full, testBlocks())
+	if got, want := stateDigest(g), stateDigest(full); got != want {
+		t.Fatalf("state of built-in ops differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	note := &model.InscriptionBRC20Data{
+		TxId:         testTxId("note"),
+		Satoshi:      546,
+		PkScript:     testUserA,
+		ContentBody:  []byte(`{"p":"exp-note","op":"note","text":"hello brc-20"}`),
+		CreateIdxKey: (&model.NFTCreateIdxKey{Height: 106}).String(),
+		Height:       106,
+	}
+	moveNote := *note
+	moveNote.IsTransfer = true
+	moveNote.PkScript = testUserB
+	moveNote.Sequence = 1
+	unknownOp := *note
+	unknownOp.TxId = testTxId("unknown-op")
+	unknownOp.ContentBody = []byte(`{"p":"exp-note","op":"erase","text":"hello brc-20"}`)
+	processTestBlocks(g, [][]*model.InscriptionBRC20Data{{note, &moveNote, &unknownOp}})
+
+	if notes[note.CreateIdxKey] != testUserB {
+		t.Fatal("note not moved by handler")
+	}
+	outcomes := g.GetInscriptionOutcomes(note.GetInscriptionId())
+	if len(outcomes) != 2 || outcomes[0].Reason != constant.BRC20_OUTCOME_VALID || outcomes[1].Reason != constant.BRC20_OUTCOME_VALID {
+		t.Fatalf("unexpected outcomes of note: %v", outcomes)
+	}
+	outcomes = g.GetInscriptionOutcomes(unknownOp.GetInscriptionId())
+	if len(outcomes) != 1 || outcomes[0].Reason != constant.BRC20_REJECT_OP_INVALID {
+		t.Fatalf("unexpected outcomes of unknown op: %v", outcomes)
+	}
+}
+
+func TestHandlerSpeculative(t *testing.T) {
+	var notes int
+	r := DefaultHandlerRegistry()
+	r.RegisterInscribe("exp-note", "note", func(g *BRC20ModuleIndexer, data *model.InscriptionBRC20Data) error {
+		if !g.Speculative {
+			notes++
+		}
+		return nil
+	})
+	g := New(Options{Handlers: r})
+	processTestBlocks(g, testBlocks())
+
+	note := &model.InscriptionBRC20Data{
+		TxId:         testTxId("note"),
+		Satoshi:      546,
+		PkScript:     testUserA,
+		ContentBody:  []byte(`{"p":"exp-note","op":"note","text":"hello brc-20"}`),
+		CreateIdxKey: (&model.NFTCreateIdxKey{Height: constant.MEMPOOL_HEIGHT}).String(),
+		Height:       constant.MEMPOOL_HEIGHT,
+	}
+	overlay := g.BeginMempoolOverlay()
+	if !overlay.Speculative || g.Speculative {
+		t.Fatal("only the overlay should be speculative")
+	}
+	if err := g.ApplyPending(note); err != nil {
+		t.Fatalf("apply pending failed: %s", err)
+	}
+	if notes != 0 {
+		t.Fatal("handler side effect on mempool overlay")
+	}
+}
@@ -0,0 +1,273 @@
+package indexer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+const DEFAULT_HISTORY_PAGE_LIMIT = 20
+
+// HistoryFilter Conditions of history to query, zero values match all. Heights and times are
+// inclusive.
+type HistoryFilter struct {
+	PkScript string  // history of user
+	Ticker   string  // history of token, any case
+	Types    []uint8 // see constant.BRC20_HISTORY_TYPE_N_*
+	Valid    *bool   // only valid or only invalid
+
+	FromHeight uint32
+	ToHeight   uint32
+	FromTime   uint32
+	ToTime     uint32
+
+	Reverse bool // newest first
+}
+
+// HistoryRecord History decoded, with its index.
+type HistoryRecord struct {
+	Idx     uint64
+	History *model.BRC20History
+}
+
+// HistoryPage Records of a query, in order of index, or reverse. Next is the cursor of the
+// following page, empty if no more.
+type HistoryPage struct {
+	Records []HistoryRecord
+	Next    string
+}
+
+// match History within the conditions, the ticker of list is matched already.
+func (f *HistoryFilter) match(h *model.BRC20History, ticker string) bool {
+	if ticker != "" && (h.Inscription.Data == nil || strings.ToLower(h.Inscription.Data.BRC20Tick) != ticker) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if t == h.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Valid != nil && *f.Valid != h.Valid {
+		return false
+	}
+	if f.FromHeight > 0 && h.Height < f.FromHeight {
+		return false
+	}
+	if f.ToHeight > 0 && h.Height > f.ToHeight {
+		return false
+	}
+	if f.FromTime > 0 && h.BlockTime < f.FromTime {
+		return false
+	}
+	if f.ToTime > 0 && h.BlockTime > f.ToTime {
+		return false
+	}
+	return true
+}
+
+// singleType The only type of history in filter, false if any or several.
+func (f *HistoryFilter) singleType() (uint8, bool) {
+	if len(f.Types) == 0 {
+		return 0, false
+	}
+	for _, t := range f.Types[1:] {
+		if t != f.Types[0] {
+			return 0, false
+		}
+	}
+	return f.Types[0], true
+}
+
+// historyList Indexes of history to scan, ascending, or all history if all is set. The list of
+// the type is used if filter is of one type kept in a list.
+func (g *BRC20ModuleIndexer) historyList(f *HistoryFilter, ticker string) (list storedList[uint64], all bool) {
+	historyType, single := f.singleType()
+	switch {
+	case f.PkScript != "" && ticker != "":
+		balance, ok := g.UserBalance(ticker, f.PkScript)
+		if !ok {
+			return list, false
+		}
+		list.tail = balance.History
+		if single {
+			switch historyType {
+			case constant.BRC20_HISTORY_TYPE_N_INSCRIBE_MINT:
+				list.tail = balance.HistoryMint
+			case constant.BRC20_HISTORY_TYPE_N_INSCRIBE_TRANSFER:
+				list.tail = balance.HistoryInscribeTransfer
+			case constant.BRC20_HISTORY_TYPE_N_SEND:
+				list.tail = balance.HistorySend
+			case constant.BRC20_HISTORY_TYPE_N_RECEIVE:
+				list.tail = balance.HistoryReceive
+			}
+		}
+		return list, false
+	case f.PkScript != "":
+		if userHistory, ok := g.UserHistory(f.PkScript); ok {
+			list.tail = userHistory.History
+		}
+		return list, false
+	case ticker != "":
+		info, ok := g.InscriptionsTickerInfoMap[ticker]
+		if !ok {
+			return list, false
+		}
+		// same order as historyListsOfToken
+		lists := g.tokenHistoryLists(ticker, info)
+		if single {
+			switch historyType {
+			case constant.BRC20_HISTORY_TYPE_N_INSCRIBE_MINT:
+				return lists[1], false
+			case constant.BRC20_HISTORY_TYPE_N_INSCRIBE_TRANSFER:
+				return lists[2], false
+			case constant.BRC20_HISTORY_TYPE_N_TRANSFER:
+				return lists[3], false
+			}
+		}
+		return lists[0], false
+	}
+	return list, true
+}
+
+// firstHistoryAt Index of first history at height or after.
+func (g *BRC20ModuleIndexer) firstHistoryAt(height uint32) (uint64, error) {
+	if idx, ok := g.FirstHistoryByHeight[height]; ok {
+		return idx, nil
+	}
+	if g.LastHistoryHeight == 0 || height > g.LastHistoryHeight {
+		return g.HistoryCount, nil
+	}
+	if g.stateKV != nil {
+		key := string(binary.BigEndian.AppendUint32(nil, height))
+		idx, ok, err := readEntry(g.stateKV, STORAGE_PREFIX_FIRST_HISTORY, key, decodeIndex)
+		if err != nil {
+			return 0, fmt.Errorf("load first history of height %d: %w", height, err)
+		}
+		if ok {
+			return idx, nil
+		}
+	}
+	// before first history, or pruned
+	return g.HistoryStart, nil
+}
+
+// QueryHistory Page of history matching filter, after cursor of the previous page, or from the
+// first (or the last if reverse) if cursor is empty. Cursors stay valid while blocks are added.
+// History pruned is skipped. The range of heights is found by binary search, times are matched
+// while scanning it as block times are not in order of height.
+func (g *BRC20ModuleIndexer) QueryHistory(f *HistoryFilter, cursor string, limit int) (*HistoryPage, error) {
+	if limit <= 0 {
+		limit = DEFAULT_HISTORY_PAGE_LIMIT
+	}
+	ticker := strings.ToLower(f.Ticker)
+	list, all := g.historyList(f, ticker)
+	n := list.Len()
+	// error of list in storage, returned after the page
+	var listErr error
+	at := func(i int) uint64 {
+		idx, err := list.At(i)
+		if err != nil && listErr == nil {
+			listErr = err
+		}
+		return idx
+	}
+	// position of first history at height or after
+	seek := func(height uint32) int {
+		return sort.Search(n, func(i int) bool {
+			data, ok := g.GetHistoryData(at(i))
+			if !ok {
+				// pruned, before all kept
+				return false
+			}
+			h := &model.BRC20History{}
+			h.Unmarshal(data)
+			return h.Height >= height
+		})
+	}
+	if all {
+		n = int(g.HistoryCount - g.HistoryStart)
+		at = func(i int) uint64 { return g.HistoryStart + uint64(i) }
+		seek = func(height uint32) int {
+			idx, err := g.firstHistoryAt(height)
+			if err != nil && listErr == nil {
+				listErr = err
+			}
+			if idx > g.HistoryStart {
+				return int(idx - g.HistoryStart)
+			}
+			return 0
+		}
+	}
+
+	// positions of heights in range
+	lo, hi := 0, n
+	if f.FromHeight > 0 {
+		lo = seek(f.FromHeight)
+	}
+	if f.ToHeight > 0 && f.ToHeight < math.MaxUint32 {
+		hi = seek(f.ToHeight + 1)
+	}
+
+	// position of first record to scan, after cursor
+	pos, step := lo, 1
+	if f.Reverse {
+		pos, step = hi-1, -1
+	}
+	if cursor != "" {
+		after, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %s", cursor)
+		}
+		if f.Reverse {
+			pos = min(pos, sort.Search(n, func(i int) bool { return at(i) >= after })-1)
+		} else {
+			pos = max(pos, sort.Search(n, func(i int) bool { return at(i) > after }))
+		}
+	}
+
+	page := &HistoryPage{}
+	for ; pos >= lo && pos < hi; pos += step {
+		idx := at(pos)
+		data, ok := g.GetHistoryData(idx)
+		if !ok {
+			continue
+		}
+		h := &model.BRC20History{}
+		h.Unmarshal(data)
+		if !f.match(h, ticker) {
+			continue
+		}
+		page.Records = append(page.Records, HistoryRecord{Idx: idx, History: h})
+		if len(page.Records) == limit {
+			if next := pos + step; next >= lo && next < hi {
+				page.Next = strconv.FormatUint(idx, 10)
+			}
+			break
+		}
+	}
+	if listErr != nil {
+		return nil, listErr
+	}
+	return page, nil
+}
+
+// QueryHistory Page of history matching filter, see BRC20ModuleIndexer.QueryHistory.
+func (q *Query) QueryHistory(f *HistoryFilter, cursor string, limit int) (page *HistoryPage, err error) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		page, err = g.QueryHistory(f, cursor, limit)
+	})
+	return page, err
+}
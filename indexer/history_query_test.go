@@ -0,0 +1,94 @@
+package indexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+)
+
+// queryIdxs Indexes of all pages of the query, and the pages.
+func queryIdxs(t *testing.T, g *BRC20ModuleIndexer, f *HistoryFilter, limit int) (idxs []uint64, pages int) {
+	cursor := ""
+	for {
+		page, err := g.QueryHistory(f, cursor, limit)
+		if err != nil {
+			t.Fatalf("query failed: %s", err)
+		}
+		if limit > 0 && len(page.Records) > limit {
+			t.Fatalf("page over limit: %d", len(page.Records))
+		}
+		for _, r := range page.Records {
+			idxs = append(idxs, r.Idx)
+		}
+		pages++
+		if page.Next == "" {
+			return idxs, pages
+		}
+		cursor = page.Next
+	}
+}
+
+func TestQueryHistory(t *testing.T) {
+	g := newTestIndexer()
+	processTestBlocks(g, testBlocks())
+	invalid := false
+
+	for _, c := range []struct {
+		filter   HistoryFilter
+		limit    int
+		expected string
+	}{
+		{HistoryFilter{}, 5, "[0 1 2 3 4 5 6 7 8 9 10 11 12 13]"},
+		{HistoryFilter{Reverse: true}, 4, "[13 12 11 10 9 8 7 6 5 4 3 2 1 0]"},
+		{HistoryFilter{PkScript: testUserA}, 3, "[0 1 3 4 6 12 13]"},
+		{HistoryFilter{PkScript: testUserA, Ticker: "SATS"}, 3, "[13]"},
+		{HistoryFilter{Ticker: "ordi", FromHeight: 102, ToHeight: 104}, 2, "[3 4 5 8]"},
+		{HistoryFilter{FromTime: 1700000105}, 0, "[10 11 12 13]"},
+		// heights sought in all history, and in lists of one type
+		{HistoryFilter{FromHeight: 102, ToHeight: 104}, 3, "[3 4 5 6 7 8 9]"},
+		{HistoryFilter{FromHeight: 102, ToHeight: 104, Reverse: true}, 3, "[9 8 7 6 5 4 3]"},
+		{HistoryFilter{FromHeight: 106}, 0, "[]"},
+		{HistoryFilter{ToHeight: 99}, 0, "[]"},
+		{HistoryFilter{Ticker: "ordi", Types: []uint8{constant.BRC20_HISTORY_TYPE_N_INSCRIBE_MINT}, FromHeight: 102}, 0, "[4]"},
+		{HistoryFilter{PkScript: testUserA, Ticker: "ordi", Types: []uint8{constant.BRC20_HISTORY_TYPE_N_SEND}, Reverse: true}, 0, "[6]"},
+		{HistoryFilter{Valid: &invalid}, 0, "[]"},
+		{HistoryFilter{PkScript: testUserB, Ticker: "none"}, 0, "[]"},
+		// last transfers of token for user
+		{HistoryFilter{
+			PkScript: testUserA,
+			Ticker:   "ordi",
+			Types:    []uint8{constant.BRC20_HISTORY_TYPE_N_SEND, constant.BRC20_HISTORY_TYPE_N_RECEIVE},
+			Reverse:  true,
+		}, 1, "[12 6]"},
+	} {
+		idxs, _ := queryIdxs(t, g, &c.filter, c.limit)
+		if fmt.Sprint(idxs) != c.expected {
+			t.Errorf("%+v: %v, expected %s", c.filter, idxs, c.expected)
+		}
+	}
+
+	if _, err := g.QueryHistory(&HistoryFilter{}, "x", 1); err == nil {
+		t.Error("invalid cursor should fail")
+	}
+}
+
+func TestQueryHistoryCursorStable(t *testing.T) {
+	g := newTestIndexer()
+	blocks := testBlocks()
+	processTestBlocks(g, blocks[:3])
+
+	page, err := g.Query().QueryHistory(&HistoryFilter{}, "", 3)
+	if err != nil || page.Next != "2" {
+		t.Fatalf("first page: %+v %v", page, err)
+	}
+	processTestBlocks(g, blocks[3:])
+
+	page, err = g.Query().QueryHistory(&HistoryFilter{}, page.Next, 3)
+	if err != nil || len(page.Records) != 3 || page.Records[0].Idx != 3 || page.Next != "5" {
+		t.Fatalf("next page: %+v %v", page, err)
+	}
+	if h := page.Records[2].History; h.TypeName() != "transfer" || h.Height != 103 {
+		t.Fatalf("record not decoded: %+v", h)
+	}
+}
@@ -2,9 +2,9 @@ package indexer
 
 import (
 	"bytes"
+	"context"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 )
@@ -25,163 +25,44 @@ func isJson(contentBody []byte) bool {
 	return true
 }
 
-// ProcessUpdateLatestBRC20Loop
+// ProcessUpdateLatestBRC20Loop Process events from channel until closed, each block under write
+// lock as ProcessSource. Events are sent to brc20DatasDump once their block is applied.
 func (g *BRC20ModuleIndexer) ProcessUpdateLatestBRC20Loop(brc20Datas, brc20DatasDump chan interface{}) {
 	if brc20Datas == nil {
 		return
 	}
 
-	g.Durty = false
-	for dataIn := range brc20Datas {
-
-		for {
-			data := dataIn.(*model.InscriptionBRC20Data)
-
-			// update latest height
-			g.BestHeight = data.Height
-
-			// is sending transfer
-			if data.IsTransfer {
-
-				if data.Height < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
-					// module conditional approve
-					if condApproveInfo, isInvalid := g.GetConditionalApproveInfoByKey(data.CreateIdxKey); condApproveInfo != nil {
-						if err := g.ProcessConditionalApprove(data, condApproveInfo, isInvalid); err != nil {
-							log.Printf("process conditional approve move failed: %s", err)
-						} else {
-							g.Durty = true
-						}
-						break
-					}
-				}
-
-				// not first move
-				if data.Sequence != 1 {
-					break
-				}
-
-				// transfer
-				if transferInfo, isInvalid := g.GetTransferInfoByKey(data.CreateIdxKey); transferInfo != nil {
-					g.InscriptionsTransferRemoveMap[data.CreateIdxKey] = data.Height
-					g.Durty = true
-
-					if err := g.ProcessTransfer(data, transferInfo, isInvalid); err != nil {
-						log.Printf("process transfer move failed: %s", err)
-					}
-					break
-				}
-
-				// module approve
-				if approveInfo, isInvalid := g.GetApproveInfoByKey(data.CreateIdxKey); approveInfo != nil {
-					g.InscriptionsApproveRemoveMap[data.CreateIdxKey] = data.Height
-					g.Durty = true
-
-					if err := g.ProcessApprove(data, approveInfo, isInvalid); err != nil {
-						log.Printf("process approve move failed: %s", err)
-					}
-					break
-				}
-
-				// module withdraw
-				if withdrawInfo := g.GetWithdrawInfoByKey(data.CreateIdxKey); withdrawInfo != nil {
-					g.InscriptionsWithdrawRemoveMap[data.CreateIdxKey] = data.Height
-					g.Durty = true
-
-					if err := g.ProcessWithdraw(data, withdrawInfo); err != nil {
-						log.Printf("process withdraw move failed: %s", err)
-					} else {
-						g.InscriptionsValidWithdrawMap[withdrawInfo.Data.GetInscriptionId()] = data.Height
-					}
-					break
-				}
-
-				// module commit
-				if commitFrom, isInvalid := g.GetCommitInfoByKey(data.CreateIdxKey); commitFrom != nil {
-					g.InscriptionsCommitRemoveMap[data.CreateIdxKey] = data.Height
-					g.Durty = true
-
-					if err := g.ProcessCommit(commitFrom, data, isInvalid); err != nil {
-						log.Printf("process commit move failed: %s", err)
-					}
-					break
-				}
-
-				break
-			}
-
-			// inscribe as fee
-			if data.Satoshi == 0 {
-				break
-			}
-
-			if ok := isJson(data.ContentBody); !ok {
-				// log.Println("not json")
-				break
-			}
-
-			// protocal, lower case only
-			body := new(model.InscriptionBRC20ProtocalContent)
-			if err := body.Unmarshal(data.ContentBody); err != nil {
-				// log.Println("Unmarshal failed", err, string(data.ContentBody))
-				break
-			}
-
-			// is inscribe deploy/mint/transfer
-			if body.Proto != constant.BRC20_P &&
-				body.Proto != constant.BRC20_P_MODULE &&
-				body.Proto != constant.BRC20_P_SWAP {
-				// log.Println("not proto")
-				break
-			}
-
-			var process func(*model.InscriptionBRC20Data) error
-			if body.Proto == constant.BRC20_P && body.Operation == constant.BRC20_OP_DEPLOY {
-				process = g.ProcessDeploy
-			} else if body.Proto == constant.BRC20_P && body.Operation == constant.BRC20_OP_MINT {
-				process = g.ProcessMint
-			} else if body.Proto == constant.BRC20_P && body.Operation == constant.BRC20_OP_TRANSFER {
-				process = g.ProcessInscribeTransfer
-			} else if body.Proto == constant.BRC20_P_MODULE && body.Operation == constant.BRC20_OP_MODULE_DEPLOY {
-				process = g.ProcessCreateModule
-			} else if body.Proto == constant.BRC20_P_MODULE && body.Operation == constant.BRC20_OP_MODULE_WITHDRAW {
-				process = g.ProcessInscribeWithdraw
-			} else if body.Proto == constant.BRC20_P_SWAP && body.Operation == constant.BRC20_OP_SWAP_APPROVE {
-				process = g.ProcessInscribeApprove
-			} else if body.Proto == constant.BRC20_P_SWAP && body.Operation == constant.BRC20_OP_SWAP_CONDITIONAL_APPROVE {
-				process = g.ProcessInscribeConditionalApprove
-			} else if body.Proto == constant.BRC20_P_SWAP && body.Operation == constant.BRC20_OP_SWAP_COMMIT {
-				process = g.ProcessInscribeCommit
-			} else {
-				break
-			}
+	src := &chanSource{in: brc20Datas, dump: brc20DatasDump}
+	if err := g.ProcessSource(context.Background(), src); err != nil {
+		log.Printf("process failed: %s", err)
+	}
+	src.flushDump()
+}
 
-			if err := process(data); err != nil {
-				if body.Operation == constant.BRC20_OP_MINT {
-					if conf.DEBUG {
-						log.Printf("(%d) process failed: %s", g.BestHeight, err)
-					}
-				} else {
-					log.Printf("(%d) process failed: %s", g.BestHeight, err)
-				}
-			} else {
-				g.Durty = true
-			}
-			break
-		}
-		if brc20DatasDump != nil {
-			brc20DatasDump <- dataIn
+// processData Apply the event on mempool overlay if pending, otherwise on confirmed state.
+// Pending events never change the confirmed state.
+func (g *BRC20ModuleIndexer) processData(data *model.InscriptionBRC20Data) {
+	if data.Height == constant.MEMPOOL_HEIGHT {
+		// unconfirmed, only apply on overlay
+		if err := g.ApplyPending(data); err != nil {
+			log.Printf("apply pending failed: %s", err)
 		}
+	} else {
+		// new block arrives, pending state is stale
+		g.DiscardOverlay()
+		g.ProcessUpdateLatestBRC20(data)
 	}
+}
 
-	for _, holdersBalanceMap := range g.TokenUsersBalanceData {
-		for key, balance := range holdersBalanceMap {
-			if balance.AvailableBalance.Sign() == 0 && balance.TransferableBalance.Sign() == 0 {
-				delete(holdersBalanceMap, key)
-			}
-		}
+// finishProcess End of input, clean up and log stats.
+func (g *BRC20ModuleIndexer) finishProcess() error {
+	g.removeEmptyTokenHolders()
+	g.updateStateRoot()
+	if err := g.flushStorage(); err != nil {
+		return err
 	}
 	if !g.Durty {
-		return
+		return nil
 	}
 
 	log.Printf("process swap finish. ticker: %d, users: %d, tokens: %d, validInscription: %d, validTransfer: %d, invalidTransfer: %d, history: %d",
@@ -218,6 +99,88 @@ func (g *BRC20ModuleIndexer) ProcessUpdateLatestBRC20Loop(brc20Datas, brc20Datas
 		len(g.InscriptionsValidCommitMap),
 		len(g.InscriptionsInvalidCommitMap),
 	)
+	return nil
+}
+
+// ProcessUpdateLatestBRC20 process one inscription event
+func (g *BRC20ModuleIndexer) ProcessUpdateLatestBRC20(data *model.InscriptionBRC20Data) {
+	// state root of block is saved by finishBlock
+	g.markStatePending()
+
+	// new block, open undo journal
+	g.BeginBlockUndo(data.Height)
+
+	// update latest height
+	g.BestHeight = data.Height
+	g.LastCreateIdxKey = data.CreateIdxKey
+	g.LastSequence = data.Sequence
+
+	// is sending transfer
+	if data.IsTransfer {
+		for _, handler := range g.Handlers.move {
+			if result := handler(g, data); result != nil {
+				g.recordOutcome(data, result.InscriptionId, result.Proto, result.Operation, result.Ticker, result.Err)
+				return
+			}
+		}
+		return
+	}
+
+	// inscribe as fee
+	if data.Satoshi == 0 {
+		return
+	}
+
+	if ok := isJson(data.ContentBody); !ok {
+		// log.Println("not json")
+		return
+	}
+
+	// protocal, lower case only
+	body := new(model.InscriptionBRC20ProtocalContent)
+	if err := body.Unmarshal(data.ContentBody); err != nil {
+		// log.Println("Unmarshal failed", err, string(data.ContentBody))
+		return
+	}
+
+	// is inscribe of registered protocal
+	process, protoKnown := g.Handlers.inscribeHandler(body.Proto, body.Operation)
+	if !protoKnown {
+		// log.Println("not proto")
+		return
+	}
+	if process == nil {
+		g.recordOutcome(data, data.GetInscriptionId(), body.Proto, body.Operation, body.BRC20Tick,
+			newQuietRejectError(constant.BRC20_REJECT_OP_INVALID, "op invalid"))
+		return
+	}
+
+	err := process(g, data)
+	g.recordOutcome(data, data.GetInscriptionId(), body.Proto, body.Operation, body.BRC20Tick, err)
+	if err != nil {
+		if isQuietReject(err) {
+			// common invalid inscription, skip log
+		} else if body.Operation == constant.BRC20_OP_MINT {
+			if g.Debug {
+				log.Printf("(%d) process failed: %s", g.BestHeight, err)
+			}
+		} else {
+			log.Printf("(%d) process failed: %s", g.BestHeight, err)
+		}
+	} else {
+		g.Durty = true
+	}
+}
+
+func (g *BRC20ModuleIndexer) removeEmptyTokenHolders() {
+	for _, holdersBalanceMap := range g.TokenUsersBalanceData {
+		for key, balance := range holdersBalanceMap {
+			if balance.AvailableBalance.Sign() == 0 && balance.TransferableBalance.Sign() == 0 {
+				undoMapEntry(g, holdersBalanceMap, key)
+				delete(holdersBalanceMap, key)
+			}
+		}
+	}
 }
 
 func (g *BRC20ModuleIndexer) Init() {
@@ -0,0 +1,60 @@
+package indexertest
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// pkscripts of users in Blocks, taproot of mainnet
+const (
+	USER_A = "\x51\x20aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	USER_B = "\x51\x20bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+// Inscribe Inscription of content to pkScript, the only one of block height.
+func Inscribe(height uint32, pkScript, content string) *model.InscriptionBRC20Data {
+	key := &model.NFTCreateIdxKey{Height: height}
+	return &model.InscriptionBRC20Data{
+		TxId:         fmt.Sprintf("%032d", height),
+		Satoshi:      546,
+		PkScript:     pkScript,
+		ContentBody:  []byte(content),
+		CreateIdxKey: key.String(),
+		Height:       height,
+		BlockTime:    1700000000 + height,
+	}
+}
+
+// Blocks A deploy, mints and a transfer of 30 ordi from A to B, one block each of 100 to 104.
+func Blocks() []*model.InscriptionBRC20Data {
+	transfer := Inscribe(103, USER_A, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"30"}`)
+	move := *transfer
+	move.IsTransfer, move.PkScript, move.Height, move.BlockTime, move.Sequence = true, USER_B, 104, 1700000104, 1
+	return []*model.InscriptionBRC20Data{
+		Inscribe(100, USER_A, `{"p":"brc-20","op":"deploy","tick":"ordi","max":"1000","lim":"100"}`),
+		Inscribe(101, USER_A, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"100"}`),
+		Inscribe(102, USER_B, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"100"}`),
+		transfer,
+		&move,
+	}
+}
+
+// Process Process datas by the loop of indexer.
+func Process(g *indexer.BRC20ModuleIndexer, datas []*model.InscriptionBRC20Data) {
+	brc20Datas := make(chan interface{}, len(datas))
+	for _, data := range datas {
+		brc20Datas <- data
+	}
+	close(brc20Datas)
+	g.ProcessUpdateLatestBRC20Loop(brc20Datas, nil)
+}
+
+// NewIndexer Indexer of mainnet with Blocks processed.
+func NewIndexer() *indexer.BRC20ModuleIndexer {
+	g := indexer.New(indexer.Options{NetParams: &chaincfg.MainNetParams})
+	Process(g, Blocks())
+	return g
+}
@@ -3,10 +3,16 @@ package indexer
 import (
 	"log"
 	"strings"
+	"sync"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/historylog"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/stateroot"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
 )
 
 type BRC20ModuleIndexer struct {
@@ -14,15 +20,50 @@ type BRC20ModuleIndexer struct {
 	Durty         bool // save flag
 	EnableHistory bool
 
-	HistoryCount uint32
-	HistoryData  [][]byte
+	// config of instance, default by network preset if not set
+	Rules           *conf.ChainRules                      // consensus activations of network
+	NetParams       *chaincfg.Params                      // network of addresses
+	TicksEnabled    string                                // test only, deploy of other ticks invalid
+	Debug           bool                                  // log failed mints
+	ResultsExternal []*model.SwapFunctionResultCheckState // expected results of commits to verify
+	Handlers        *HandlerRegistry                      // handlers of protocal operations, shared with copies
+
+	// copy by DeepCopy or CherryPick, for mempool overlay and commit verify. Handlers with side
+	// effects outside of the indexer should skip them if set.
+	Speculative bool
+
+	// last processed event, for resume in the middle of block
+	LastCreateIdxKey string
+	LastSequence     uint16
+
+	HistoryCount uint64
+	HistoryStart uint64   // history before it is pruned
+	HistoryData  [][]byte // history from historyOffset, before it in storage
+
+	// history of archive attached, from HistoryStart
+	historyArchived [][]byte
+
+	// persistent storage of primary instance, history is appended to it instead of memory if set
+	Storage       storage.KV
+	HistoryLog    *historylog.Log // history is appended to it instead of storage if set
+	history       historyStore    // read history before historyOffset, shared by copies
+	historyOffset uint64
+
+	// state in storage, maps of entries are a cache of it if set, shared by DeepCopy
+	StorageCacheSize     int                            // entries of each map kept in memory after block
+	stateKV              storage.KV                     // nil until storage has all state
+	storageDirty         map[string]map[string]struct{} // [prefix][key] changed since last write
+	storageHistoryHeight uint32                         // FirstHistoryByHeight written
+	storagePending       *storage.Batch                 // deletes written before entries changed
+	storageErr           error                          // storage failed, state is to be loaded again
+	allHistoryStored     model.HistoryListStored        // AllHistory in storage before it
 
 	// history height
-	FirstHistoryByHeight map[uint32]uint32
+	FirstHistoryByHeight map[uint32]uint64
 	LastHistoryHeight    uint32
 
 	// brc20 base
-	AllHistory     []uint32 // all valid history
+	AllHistory     []uint64 // all valid history
 	UserAllHistory map[string]*model.BRC20UserHistory
 
 	InscriptionsTickerInfoMap     map[string]*model.BRC20TokenInfo
@@ -30,6 +71,11 @@ type BRC20ModuleIndexer struct {
 	TokenUsersBalanceData         map[string]map[string]*model.BRC20TokenBalance // [ticker][address]balance
 	InscriptionsValidBRC20DataMap map[string]*model.InscriptionBRC20InfoResp
 
+	// outcome of inscriptions, counts by ticker are always kept
+	EnableOutcomes         bool
+	InscriptionOutcomesMap map[string][]*model.BRC20InscriptionOutcome // [inscriptionId]outcomes
+	TickOutcomeCountMap    map[string]map[string]uint32                // [ticker][reason]count
+
 	// inner valid transfer
 	InscriptionsTransferRemoveMap map[string]uint32 // remove at height
 	InscriptionsValidTransferMap  map[string]*model.InscriptionBRC20TickInfo
@@ -71,9 +117,51 @@ type BRC20ModuleIndexer struct {
 	// for gen approve event
 	ThisTxId                                    string
 	TxStaticTransferStatesForConditionalApprove []*model.TransferStateForConditionalApprove
+
+	// state root by height
+	EnableStateRoot    bool
+	StateRootsByHeight map[uint32]stateroot.Hash
+	stateRootHeights   []uint32 // sorted heights of roots
+	stateRoot          *stateRootTracker
+
+	// users ever holding each ticker, for HoldersAt, built on first use
+	holdersEver   map[string]map[string]struct{} // [ticker][address]
+	holdersEverMu sync.Mutex
+
+	// module balances by height, for ModuleBalanceAt
+	EnableBalanceCheckpoints bool
+	ModuleBalanceCheckpoints map[string]map[string]map[string][]*ModuleBalanceCheckpoint // [module][address][ticker]
+	ModuleCheckpointsStart   uint32                                                      // first height of checkpoints, 0 if none
+	checkpointDirtyModules   map[string]struct{}
+
+	// holders of tickers by block, for HolderCounts
+	HolderCountSeries  map[string][]HolderCount   // [ticker]
+	holderCountTouched map[string]map[string]bool // [ticker][address] holder before block
+
+	// balances of tickers ranked, for HolderRanking, built on first use
+	holderRanking   map[string][]*model.BRC20TokenBalance // [ticker]
+	holderRankingMu sync.Mutex
+
+	// speculative state of mempool on top of this
+	MempoolOverlay *BRC20ModuleIndexer
+
+	// state change callbacks, not copied
+	observers []Observer
+
+	// held by writer for a whole block, readers of Query see block boundaries only
+	rw sync.RWMutex
+
+	// undo journal for reorg
+	UndoDepth       int    // max blocks to keep, 0 disable
+	UndoFloorHeight uint32 // lowest height can rollback to
+	undoJournals    []*BRC20BlockUndo
+	undoCurrent     *BRC20BlockUndo
 }
 
 func (g *BRC20ModuleIndexer) GetBRC20HistoryByUser(pkScript string) (userHistory *model.BRC20UserHistory) {
+	g.userHistoryOf(pkScript)
+	g.markStorageDirty(STORAGE_PREFIX_USER_HIST, pkScript)
+	g.undoUserHistory(pkScript)
 	if history, ok := g.UserAllHistory[pkScript]; !ok {
 		userHistory = &model.BRC20UserHistory{}
 		g.UserAllHistory[pkScript] = userHistory
@@ -84,7 +172,7 @@ func (g *BRC20ModuleIndexer) GetBRC20HistoryByUser(pkScript string) (userHistory
 }
 
 func (g *BRC20ModuleIndexer) GetBRC20HistoryByUserForAPI(pkScript string) (userHistory *model.BRC20UserHistory) {
-	if history, ok := g.UserAllHistory[pkScript]; !ok {
+	if history, ok := g.UserHistory(pkScript); !ok {
 		userHistory = &model.BRC20UserHistory{}
 	} else {
 		userHistory = history
@@ -92,10 +180,10 @@ func (g *BRC20ModuleIndexer) GetBRC20HistoryByUserForAPI(pkScript string) (userH
 	return userHistory
 }
 
-func (g *BRC20ModuleIndexer) UpdateHistoryHeightAndGetHistoryIndex(historyObj *model.BRC20History) uint32 {
+func (g *BRC20ModuleIndexer) UpdateHistoryHeightAndGetHistoryIndex(historyObj *model.BRC20History) uint64 {
 	height := historyObj.Height
 	history := g.HistoryCount
-	g.HistoryData = append(g.HistoryData, historyObj.Marshal())
+	g.appendHistory(height, historyObj.Marshal())
 	g.HistoryCount += 1
 
 	if height == g.LastHistoryHeight || height == constant.MEMPOOL_HEIGHT {
@@ -117,15 +205,51 @@ func (g *BRC20ModuleIndexer) UpdateHistoryHeightAndGetHistoryIndex(historyObj *m
 func (g *BRC20ModuleIndexer) initBRC20() {
 	g.EnableHistory = true
 	g.BestHeight = 0
+	// defaults of the network preset, package variables of conf are never read
+	if g.Rules == nil {
+		network := "mainnet"
+		if g.NetParams != nil {
+			network = g.NetParams.Name
+		}
+		rules, err := conf.ChainRulesByNetwork(network)
+		if err != nil {
+			mainnet := conf.MainnetRules
+			rules = &mainnet
+		}
+		g.Rules = rules
+	}
+	if g.NetParams == nil {
+		params, err := conf.NetParamsByNetwork(g.Rules.Network)
+		if err != nil {
+			params = &chaincfg.MainNetParams
+		}
+		g.NetParams = params
+	}
+	if g.Handlers == nil {
+		g.Handlers = DefaultHandlerRegistry()
+	}
+	if g.StorageCacheSize <= 0 {
+		g.StorageCacheSize = constant.DEFAULT_STORAGE_CACHE_SIZE
+	}
+	g.stateKV = nil
+	g.storageDirty = nil
+	g.storageHistoryHeight = 0
+	g.storagePending = nil
+	g.storageErr = nil
+	g.allHistoryStored = model.HistoryListStored{}
 
 	g.HistoryCount = 0
+	g.HistoryStart = 0
 	g.HistoryData = make([][]byte, 0)
+	g.historyArchived = nil
+	g.history = g.primaryHistory()
+	g.historyOffset = 0
 
-	g.FirstHistoryByHeight = make(map[uint32]uint32, 0)
+	g.FirstHistoryByHeight = make(map[uint32]uint64, 0)
 	g.LastHistoryHeight = 0
 
 	// all history
-	g.AllHistory = make([]uint32, 0)
+	g.AllHistory = make([]uint64, 0)
 
 	// user history
 	g.UserAllHistory = make(map[string]*model.BRC20UserHistory, 0)
@@ -142,6 +266,27 @@ func (g *BRC20ModuleIndexer) initBRC20() {
 	// valid brc20 inscriptions
 	g.InscriptionsValidBRC20DataMap = make(map[string]*model.InscriptionBRC20InfoResp, 0)
 
+	// outcome of inscriptions
+	g.InscriptionOutcomesMap = make(map[string][]*model.BRC20InscriptionOutcome, 0)
+	g.TickOutcomeCountMap = make(map[string]map[string]uint32, 0)
+
+	// state root by height
+	g.StateRootsByHeight = make(map[uint32]stateroot.Hash, 0)
+	g.stateRootHeights = nil
+	g.stateRoot = nil
+
+	g.holdersEver = nil
+
+	// module balances by height
+	g.ModuleBalanceCheckpoints = make(map[string]map[string]map[string][]*ModuleBalanceCheckpoint, 0)
+	g.ModuleCheckpointsStart = 0
+	g.checkpointDirtyModules = nil
+
+	// holders of tickers by block
+	g.HolderCountSeries = make(map[string][]HolderCount, 0)
+	g.holderCountTouched = nil
+	g.resetHolderRanking()
+
 	// inner valid transfer
 	g.InscriptionsTransferRemoveMap = make(map[string]uint32, 0)
 	g.InscriptionsValidTransferMap = make(map[string]*model.InscriptionBRC20TickInfo, 0)
@@ -176,14 +321,20 @@ func (g *BRC20ModuleIndexer) initModule() {
 	g.InscriptionsInvalidCommitMap = make(map[string]*model.InscriptionBRC20Data, 0)
 
 	g.InscriptionsValidCommitMapById = make(map[string]*model.InscriptionBRC20Data, 0) // inner valid commit
+
+	// runtime for withdraw
+	g.InscriptionsWithdrawRemoveMap = make(map[string]uint32, 0)
+	g.InscriptionsWithdrawMap = make(map[string]*model.InscriptionBRC20SwapInfo, 0)
+	g.InscriptionsValidWithdrawMap = make(map[string]uint32, 0)
 }
 
 func (g *BRC20ModuleIndexer) GetUserTokenBalance(ticker, userPkScript string) (tokenBalance *model.BRC20TokenBalance) {
 	uniqueLowerTicker := strings.ToLower(ticker)
 	// get user's tokens to update
 	var userTokens map[string]*model.BRC20TokenBalance
-	if tokens, ok := g.UserTokensBalanceData[userPkScript]; !ok {
+	if tokens, ok := g.userTokensOf(userPkScript); !ok {
 		userTokens = make(map[string]*model.BRC20TokenBalance, 0)
+		undoMapEntry(g, g.UserTokensBalanceData, userPkScript)
 		g.UserTokensBalanceData[userPkScript] = userTokens
 	} else {
 		userTokens = tokens
@@ -191,15 +342,19 @@ func (g *BRC20ModuleIndexer) GetUserTokenBalance(ticker, userPkScript string) (t
 	// get tokenBalance to update
 	if tb, ok := userTokens[uniqueLowerTicker]; !ok {
 		tokenBalance = &model.BRC20TokenBalance{Ticker: ticker, PkScript: userPkScript}
+		undoMapEntry(g, userTokens, uniqueLowerTicker)
 		userTokens[uniqueLowerTicker] = tokenBalance
 	} else {
 		tokenBalance = tb
 	}
+	g.touchTokenBalance(tokenBalance)
+	g.undoTokenBalance(tokenBalance)
 	// set token's users
 	tokenUsers, ok := g.TokenUsersBalanceData[uniqueLowerTicker]
 	if !ok {
 		log.Panicf("g.TokenUsersBalanceData[%s], not exists", uniqueLowerTicker)
 	}
+	undoMapEntry(g, tokenUsers, userPkScript)
 	tokenUsers[userPkScript] = tokenBalance
 
 	return tokenBalance
@@ -217,7 +372,8 @@ func (g *BRC20ModuleIndexer) GenerateApproveEventsByTransfer(inscription, tick,
 	// First, globally save the transfer status.
 	g.TxStaticTransferStatesForConditionalApprove = append(g.TxStaticTransferStatesForConditionalApprove, transStateStatic)
 
-	// Then process each module one by one.
+	// Then process each module one by one. Modules with approve states are of this block, so
+	// in memory.
 	for _, moduleInfo := range g.ModulesInfoMap {
 		if g.ThisTxId != moduleInfo.ThisTxId {
 			// For the first time processing the transfer event within the module, you need to clear the status first.
@@ -230,6 +386,8 @@ func (g *BRC20ModuleIndexer) GenerateApproveEventsByTransfer(inscription, tick,
 		if len(moduleInfo.ApproveStatesForConditionalApprove) == 0 {
 			continue
 		}
+		g.touchModule(moduleInfo)
+		g.undoModule(moduleInfo)
 
 		transState := &model.TransferStateForConditionalApprove{
 			Tick:          tick,
@@ -248,8 +406,10 @@ func (g *BRC20ModuleIndexer) GenerateApproveEventsByTransfer(inscription, tick,
 
 func (g *BRC20ModuleIndexer) GenerateApproveEventsByApprove(owner string, balance *decimal.Decimal,
 	data *model.InscriptionBRC20Data, approveInfo *model.InscriptionBRC20SwapConditionalApproveInfo) (events []*model.ConditionalApproveEvent) {
-	if moduleInfo, ok := g.ModulesInfoMap[approveInfo.Module]; ok {
+	if moduleInfo, ok := g.moduleOf(approveInfo.Module); ok {
 		log.Printf("generate approve event. module: %s", moduleInfo.ID)
+		g.touchModule(moduleInfo)
+		g.undoModule(moduleInfo)
 
 		if g.ThisTxId != moduleInfo.ThisTxId {
 			// First appearance, clear status
@@ -279,7 +439,11 @@ func (copyDup *BRC20ModuleIndexer) deepCopyBRC20Data(base *BRC20ModuleIndexer) {
 	// history
 	copyDup.BestHeight = base.BestHeight
 	copyDup.EnableHistory = base.EnableHistory
+	copyDup.LastCreateIdxKey = base.LastCreateIdxKey
+	copyDup.LastSequence = base.LastSequence
 	copyDup.HistoryCount = base.HistoryCount
+	copyDup.HistoryStart = base.HistoryStart
+	copyDup.historyArchived = base.historyArchived
 
 	for height, history := range base.FirstHistoryByHeight {
 		copyDup.FirstHistoryByHeight[height] = history
@@ -289,40 +453,25 @@ func (copyDup *BRC20ModuleIndexer) deepCopyBRC20Data(base *BRC20ModuleIndexer) {
 	for _, h := range base.HistoryData {
 		copyDup.HistoryData = append(copyDup.HistoryData, h)
 	}
+	copyDup.history = base.history
+	copyDup.historyOffset = base.historyOffset
+	copyDup.stateKV = base.stateKV
 
-	copyDup.AllHistory = make([]uint32, len(base.AllHistory))
+	copyDup.AllHistory = make([]uint64, len(base.AllHistory))
 	copy(copyDup.AllHistory, base.AllHistory)
+	copyDup.allHistoryStored = base.allHistoryStored
 
 	// userhistory
 	for u, userHistory := range base.UserAllHistory {
 		h := &model.BRC20UserHistory{
-			History: make([]uint32, len(userHistory.History)),
+			History: make([]uint64, len(userHistory.History)),
 		}
 		copy(h.History, userHistory.History)
 		copyDup.UserAllHistory[u] = h
 	}
 
 	for k, v := range base.InscriptionsTickerInfoMap {
-		tinfo := &model.BRC20TokenInfo{
-			Ticker: v.Ticker,
-			Deploy: v.Deploy.DeepCopy(),
-		}
-
-		// history
-		tinfo.History = make([]uint32, len(v.History))
-		copy(tinfo.History, v.History)
-
-		tinfo.HistoryMint = make([]uint32, len(v.HistoryMint))
-		copy(tinfo.HistoryMint, v.HistoryMint)
-
-		tinfo.HistoryInscribeTransfer = make([]uint32, len(v.HistoryInscribeTransfer))
-		copy(tinfo.HistoryInscribeTransfer, v.HistoryInscribeTransfer)
-
-		tinfo.HistoryTransfer = make([]uint32, len(v.HistoryTransfer))
-		copy(tinfo.HistoryTransfer, v.HistoryTransfer)
-
-		// set info
-		copyDup.InscriptionsTickerInfoMap[k] = tinfo
+		copyDup.InscriptionsTickerInfoMap[k] = v.DeepCopy()
 	}
 
 	for u, userTokens := range base.UserTokensBalanceData {
@@ -344,8 +493,38 @@ func (copyDup *BRC20ModuleIndexer) deepCopyBRC20Data(base *BRC20ModuleIndexer) {
 	for k, v := range base.InscriptionsValidBRC20DataMap {
 		copyDup.InscriptionsValidBRC20DataMap[k] = v
 	}
+	// outcome
+	copyDup.EnableOutcomes = base.EnableOutcomes
+	for k, v := range base.InscriptionOutcomesMap {
+		outcomes := make([]*model.BRC20InscriptionOutcome, len(v))
+		copy(outcomes, v)
+		copyDup.InscriptionOutcomesMap[k] = outcomes
+	}
+	for tick, counts := range base.TickOutcomeCountMap {
+		countsCopy := make(map[string]uint32, len(counts))
+		for reason, n := range counts {
+			countsCopy[reason] = n
+		}
+		copyDup.TickOutcomeCountMap[tick] = countsCopy
+	}
+	// state root, tree is rebuilt on next root
+	copyDup.EnableStateRoot = base.EnableStateRoot
+	for h, root := range base.StateRootsByHeight {
+		copyDup.StateRootsByHeight[h] = root
+	}
+	copyDup.stateRootHeights = append(copyDup.stateRootHeights, base.stateRootHeights...)
+
+	// deploy data of copy
+	for _, tinfo := range copyDup.InscriptionsTickerInfoMap {
+		if _, ok := copyDup.InscriptionsValidBRC20DataMap[tinfo.Deploy.CreateIdxKey]; ok {
+			copyDup.InscriptionsValidBRC20DataMap[tinfo.Deploy.CreateIdxKey] = tinfo.Deploy.Data
+		}
+	}
 
 	// transferInfo
+	for k, v := range base.InscriptionsTransferRemoveMap {
+		copyDup.InscriptionsTransferRemoveMap[k] = v
+	}
 	for k, v := range base.InscriptionsValidTransferMap {
 		copyDup.InscriptionsValidTransferMap[k] = v
 	}
@@ -372,8 +551,8 @@ func (copyDup *BRC20ModuleIndexer) cherryPickBRC20Data(base *BRC20ModuleIndexer,
 		copyDup.InscriptionsTickerInfoMap[lowerTick] = tinfo
 	}
 	for u := range pickUsersPkScript {
-		userTokens, ok := base.UserTokensBalanceData[u]
-		if !ok {
+		userTokens := base.UserBalances(u)
+		if userTokens == nil {
 			continue
 		}
 		userTokensCopy := make(map[string]*model.BRC20TokenBalance, 0)
@@ -418,6 +597,9 @@ func (copyDup *BRC20ModuleIndexer) deepCopyModuleData(base *BRC20ModuleIndexer)
 	}
 
 	// approveInfo
+	for k, v := range base.InscriptionsApproveRemoveMap {
+		copyDup.InscriptionsApproveRemoveMap[k] = v
+	}
 	for k, v := range base.InscriptionsValidApproveMap {
 		copyDup.InscriptionsValidApproveMap[k] = v
 	}
@@ -426,6 +608,9 @@ func (copyDup *BRC20ModuleIndexer) deepCopyModuleData(base *BRC20ModuleIndexer)
 	}
 
 	// conditional approveInfo
+	for k, v := range base.InscriptionsCondApproveRemoveMap {
+		copyDup.InscriptionsCondApproveRemoveMap[k] = v
+	}
 	for k, v := range base.InscriptionsValidConditionalApproveMap {
 		copyDup.InscriptionsValidConditionalApproveMap[k] = v.DeepCopy()
 	}
@@ -434,6 +619,9 @@ func (copyDup *BRC20ModuleIndexer) deepCopyModuleData(base *BRC20ModuleIndexer)
 	}
 
 	// commitInfo
+	for k, v := range base.InscriptionsCommitRemoveMap {
+		copyDup.InscriptionsCommitRemoveMap[k] = v
+	}
 	for k, v := range base.InscriptionsValidCommitMap {
 		copyDup.InscriptionsValidCommitMap[k] = v
 	}
@@ -445,6 +633,17 @@ func (copyDup *BRC20ModuleIndexer) deepCopyModuleData(base *BRC20ModuleIndexer)
 		copyDup.InscriptionsValidCommitMapById[k] = v
 	}
 
+	// withdrawInfo
+	for k, v := range base.InscriptionsWithdrawRemoveMap {
+		copyDup.InscriptionsWithdrawRemoveMap[k] = v
+	}
+	for k, v := range base.InscriptionsWithdrawMap {
+		copyDup.InscriptionsWithdrawMap[k] = v
+	}
+	for k, v := range base.InscriptionsValidWithdrawMap {
+		copyDup.InscriptionsValidWithdrawMap[k] = v
+	}
+
 	// runtime state
 	copyDup.ThisTxId = base.ThisTxId
 	for _, v := range base.TxStaticTransferStatesForConditionalApprove {
@@ -456,7 +655,7 @@ func (copyDup *BRC20ModuleIndexer) deepCopyModuleData(base *BRC20ModuleIndexer)
 
 func (copyDup *BRC20ModuleIndexer) cherryPickModuleData(base *BRC20ModuleIndexer, module string, pickUsersPkScript, pickTokensTick, pickPoolsPair map[string]bool) {
 
-	info, ok := base.ModulesInfoMap[module]
+	info, ok := base.Module(module)
 	if ok {
 		copyDup.ModulesInfoMap[module] = info.CherryPick(pickUsersPkScript, pickTokensTick, pickPoolsPair)
 	}
@@ -468,9 +667,20 @@ func (copyDup *BRC20ModuleIndexer) cherryPickModuleData(base *BRC20ModuleIndexer
 	log.Printf("cherryPickModuleData finish. total: %d", len(base.ModulesInfoMap))
 }
 
+func (copyDup *BRC20ModuleIndexer) copyConfig(base *BRC20ModuleIndexer) {
+	copyDup.Rules = base.Rules
+	copyDup.NetParams = base.NetParams
+	copyDup.TicksEnabled = base.TicksEnabled
+	copyDup.Debug = base.Debug
+	copyDup.ResultsExternal = base.ResultsExternal
+	copyDup.Handlers = base.Handlers
+	copyDup.Speculative = true
+}
+
 func (base *BRC20ModuleIndexer) DeepCopy() (copyDup *BRC20ModuleIndexer) {
 	log.Printf("DeepCopy enter")
 	copyDup = &BRC20ModuleIndexer{}
+	copyDup.copyConfig(base)
 	copyDup.Init()
 
 	copyDup.deepCopyBRC20Data(base)
@@ -481,9 +691,10 @@ func (base *BRC20ModuleIndexer) DeepCopy() (copyDup *BRC20ModuleIndexer) {
 func (base *BRC20ModuleIndexer) CherryPick(module string, pickUsersPkScript, pickTokensTick, pickPoolsPair map[string]bool) (copyDup *BRC20ModuleIndexer) {
 	log.Printf("CherryPick enter")
 	copyDup = &BRC20ModuleIndexer{}
+	copyDup.copyConfig(base)
 	copyDup.Init()
 
-	moduleInfo, ok := base.ModulesInfoMap[module]
+	moduleInfo, ok := base.Module(module)
 	if ok {
 		lowerTick := strings.ToLower(moduleInfo.GasTick)
 		pickTokensTick[lowerTick] = true
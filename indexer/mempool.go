@@ -0,0 +1,129 @@
+package indexer
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// BeginMempoolOverlay Create a speculative copy of the confirmed state for unconfirmed
+// inscriptions. The confirmed state is never changed by the overlay.
+func (g *BRC20ModuleIndexer) BeginMempoolOverlay() *BRC20ModuleIndexer {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+	return g.beginMempoolOverlay()
+}
+
+// beginMempoolOverlay See BeginMempoolOverlay, the caller holds the lock.
+func (g *BRC20ModuleIndexer) beginMempoolOverlay() *BRC20ModuleIndexer {
+	overlay := g.DeepCopy()
+	// pending state is thrown away as a whole, no need to rollback
+	overlay.UndoDepth = 0
+	overlay.EnableStateRoot = false
+	overlay.Durty = false
+
+	g.MempoolOverlay = overlay
+	log.Printf("begin mempool overlay. height: %d", g.BestHeight)
+	return overlay
+}
+
+// ApplyPending Process an unconfirmed inscription event on the overlay, begun again if dropped by
+// a block before. Called by the process loop, which holds the lock.
+func (g *BRC20ModuleIndexer) ApplyPending(data *model.InscriptionBRC20Data) error {
+	if data.Height != constant.MEMPOOL_HEIGHT {
+		return errors.New("pending data height must be mempool height")
+	}
+	if g.MempoolOverlay == nil {
+		g.beginMempoolOverlay()
+	}
+
+	g.MempoolOverlay.ProcessUpdateLatestBRC20(data)
+	return nil
+}
+
+// DiscardOverlay Drop all pending state, e.g. when the next block arrives. The next pending event
+// begins a new overlay.
+func (g *BRC20ModuleIndexer) DiscardOverlay() {
+	if g.MempoolOverlay == nil {
+		return
+	}
+	g.MempoolOverlay = nil
+	log.Printf("discard mempool overlay. height: %d", g.BestHeight)
+}
+
+// GetPendingUserTokenBalance Balance of user with pending inscriptions applied, confirmed if no overlay.
+// Not locked, see Query.GetPendingUserTokenBalance.
+func (g *BRC20ModuleIndexer) GetPendingUserTokenBalance(ticker, userPkScript string) (tokenBalance *model.BRC20TokenBalance, ok bool) {
+	state := g
+	if g.MempoolOverlay != nil {
+		state = g.MempoolOverlay
+	}
+	return state.UserBalance(ticker, userPkScript)
+}
+
+// GetPendingTransferInfo Transfer inscribed in mempool, but not in confirmed state. Not locked, see
+// Query.GetPendingTransferInfo.
+func (g *BRC20ModuleIndexer) GetPendingTransferInfo(createIdxKey string) (transferInfo *model.InscriptionBRC20TickInfo, isInvalid bool) {
+	if g.MempoolOverlay == nil {
+		return nil, false
+	}
+	if info, _ := g.GetTransferInfoByKey(createIdxKey); info != nil {
+		return nil, false
+	}
+	return g.MempoolOverlay.GetTransferInfoByKey(createIdxKey)
+}
+
+// GetPendingModuleTokenBalance Balance of user in module with pending inscriptions applied, confirmed
+// if no overlay. Not locked, see Query.GetPendingModuleTokenBalance.
+func (g *BRC20ModuleIndexer) GetPendingModuleTokenBalance(moduleId, ticker, userPkScript string) (tokenBalance *model.BRC20ModuleTokenBalance, ok bool) {
+	state := g
+	if g.MempoolOverlay != nil {
+		state = g.MempoolOverlay
+	}
+	moduleInfo, ok := state.Module(moduleId)
+	if !ok {
+		return nil, false
+	}
+	userTokens, ok := moduleInfo.UsersTokenBalanceDataMap[userPkScript]
+	if !ok {
+		return nil, false
+	}
+	tokenBalance, ok = userTokens[strings.ToLower(ticker)]
+	return tokenBalance, ok
+}
+
+// GetPendingUserTokenBalance Copy of balance of user with pending inscriptions applied.
+func (q *Query) GetPendingUserTokenBalance(ticker, userPkScript string) (tokenBalance *model.BRC20TokenBalance, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		var balance *model.BRC20TokenBalance
+		if balance, ok = g.GetPendingUserTokenBalance(ticker, userPkScript); ok {
+			tokenBalance = balance.DeepCopy()
+		}
+	})
+	return tokenBalance, ok
+}
+
+// GetPendingTransferInfo Copy of transfer inscribed in mempool, but not in confirmed state.
+func (q *Query) GetPendingTransferInfo(createIdxKey string) (transferInfo *model.InscriptionBRC20TickInfo, isInvalid bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		var info *model.InscriptionBRC20TickInfo
+		if info, isInvalid = g.GetPendingTransferInfo(createIdxKey); info != nil {
+			transferInfo = info.DeepCopy()
+		}
+	})
+	return transferInfo, isInvalid
+}
+
+// GetPendingModuleTokenBalance Copy of balance of user in module with pending inscriptions applied.
+func (q *Query) GetPendingModuleTokenBalance(moduleId, ticker, userPkScript string) (tokenBalance *model.BRC20ModuleTokenBalance, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		var balance *model.BRC20ModuleTokenBalance
+		if balance, ok = g.GetPendingModuleTokenBalance(moduleId, ticker, userPkScript); ok {
+			tokenBalance = balance.DeepCopy()
+		}
+	})
+	return tokenBalance, ok
+}
@@ -0,0 +1,102 @@
+package indexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+func TestMempoolOverlay(t *testing.T) {
+	blocks := testBlocks()
+	g := newTestIndexer()
+	processTestBlocks(g, blocks[:3])
+	confirmed := stateDigest(g)
+
+	g.BeginMempoolOverlay()
+	transferA := blocks[2][0]
+	pendingMove := &model.InscriptionBRC20Data{
+		IsTransfer:   true,
+		TxId:         testTxId("pending-move"),
+		Satoshi:      546,
+		PkScript:     testUserB,
+		ContentBody:  transferA.ContentBody,
+		CreateIdxKey: transferA.CreateIdxKey,
+		Height:       constant.MEMPOOL_HEIGHT,
+		Sequence:     1,
+	}
+	if err := g.ApplyPending(pendingMove); err != nil {
+		t.Fatalf("apply pending failed: %s", err)
+	}
+
+	balance, ok := g.Query().GetPendingUserTokenBalance("ORDI", testUserB)
+	if !ok || balance.AvailableBalance.String() != "160" {
+		t.Fatalf("unexpected pending balance: %v", balance)
+	}
+	if info, _ := g.Query().GetPendingTransferInfo(transferA.CreateIdxKey); info != nil {
+		t.Fatal("confirmed transfer should not be pending")
+	}
+	if _, ok := g.Query().GetPendingModuleTokenBalance("none", "ordi", testUserB); ok {
+		t.Fatal("balance of unknown module should be missing")
+	}
+	if got := stateDigest(g); got != confirmed {
+		t.Fatalf("confirmed state changed by overlay\ngot:\n%s\nwant:\n%s", got, confirmed)
+	}
+
+	// the next block drops the overlay
+	processTestBlocks(g, blocks[3:4])
+	if g.MempoolOverlay != nil {
+		t.Fatal("overlay should be discarded on new block")
+	}
+	balance, _ = g.Query().GetPendingUserTokenBalance("ordi", testUserB)
+	if balance.AvailableBalance.String() != "160" {
+		t.Fatalf("unexpected confirmed balance: %s", balance.AvailableBalance)
+	}
+}
+
+func TestMempoolLoop(t *testing.T) {
+	blocks := testBlocks()
+	pending := func(idx uint64, pkScript, content string) *model.InscriptionBRC20Data {
+		key := &model.NFTCreateIdxKey{Height: constant.MEMPOOL_HEIGHT, IdxInBlock: idx}
+		return &model.InscriptionBRC20Data{
+			TxId:         testTxId(fmt.Sprintf("pending-%d", idx)),
+			Satoshi:      546,
+			PkScript:     pkScript,
+			ContentBody:  []byte(content),
+			CreateIdxKey: key.String(),
+			Height:       constant.MEMPOOL_HEIGHT,
+		}
+	}
+	mint := `{"p":"brc-20","op":"mint","tick":"ordi","amt":"10"}`
+
+	// pending events between and after blocks, the overlay is dropped by each block
+	var stream [][]*model.InscriptionBRC20Data
+	stream = append(stream, blocks[:3]...)
+	stream = append(stream, []*model.InscriptionBRC20Data{pending(0, testUserB, mint)})
+	stream = append(stream, blocks[3])
+	stream = append(stream, []*model.InscriptionBRC20Data{pending(1, testUserB, mint)})
+	stream = append(stream, blocks[4])
+	stream = append(stream, []*model.InscriptionBRC20Data{pending(2, testUserA, mint)})
+	g := newTestIndexer()
+	processTestBlocks(g, stream)
+
+	confirmed := newTestIndexer()
+	processTestBlocks(confirmed, blocks[:5])
+	if got, want := stateDigest(g), stateDigest(confirmed); got != want {
+		t.Fatalf("confirmed state changed by pending events\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if g.MempoolOverlay == nil {
+		t.Fatal("overlay should be begun by pending event after block")
+	}
+	balance, ok := g.Query().GetPendingUserTokenBalance("ordi", testUserA)
+	if !ok || balance.AvailableBalance.String() != "100" {
+		t.Fatalf("unexpected pending balance of A: %v", balance)
+	}
+	// pending mints of B before blocks are dropped with their overlays
+	balance, ok = g.Query().GetPendingUserTokenBalance("ordi", testUserB)
+	if !ok || balance.AvailableBalance.String() != "60" {
+		t.Fatalf("unexpected pending balance of B: %v", balance)
+	}
+}
@@ -18,9 +18,9 @@ func (g *BRC20ModuleIndexer) GetApproveInfoByKey(createIdxKey string) (
 	approveInfo *model.InscriptionBRC20SwapInfo, isInvalid bool) {
 	var ok bool
 	// approve
-	approveInfo, ok = g.InscriptionsValidApproveMap[createIdxKey]
+	approveInfo, ok = cacheEntry(g, STORAGE_PREFIX_APPROVE, g.InscriptionsValidApproveMap, createIdxKey, decodeGob[*model.InscriptionBRC20SwapInfo])
 	if !ok {
-		approveInfo, ok = g.InscriptionsInvalidApproveMap[createIdxKey]
+		approveInfo, ok = cacheEntry(g, STORAGE_PREFIX_INVALID_APPROVE, g.InscriptionsInvalidApproveMap, createIdxKey, decodeGob[*model.InscriptionBRC20SwapInfo])
 		if !ok {
 			approveInfo = nil
 		}
@@ -37,13 +37,15 @@ func (g *BRC20ModuleIndexer) ProcessApprove(data *model.InscriptionBRC20Data, ap
 		return errors.New("approve, invalid ticker")
 	}
 
-	moduleInfo, ok := g.ModulesInfoMap[approveInfo.Module]
+	moduleInfo, ok := g.moduleOf(approveInfo.Module)
 	if !ok {
 		log.Printf("ProcessBRC20Approve send approve, but ticker invalid. txid: %s",
 			hex.EncodeToString(utils.ReverseBytes([]byte(data.TxId))),
 		)
 		return errors.New("approve, module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
 	// from
 	// get user's tokens to update
@@ -64,6 +66,7 @@ func (g *BRC20ModuleIndexer) ProcessApprove(data *model.InscriptionBRC20Data, ap
 		)
 		return errors.New("approve, send from ticker missing")
 	}
+	g.undoModuleTokenBalance(fromTokenBalance)
 
 	// Cross-check whether the approve-inscription exists.
 	if _, ok := fromTokenBalance.ValidApproveMap[data.CreateIdxKey]; !ok {
@@ -96,7 +99,7 @@ func (g *BRC20ModuleIndexer) ProcessApprove(data *model.InscriptionBRC20Data, ap
 	}
 
 	// to
-	tokenBalance := moduleInfo.GetUserTokenBalance(approveInfo.Tick, receiverPkScript)
+	tokenBalance := g.getModuleUserTokenBalance(moduleInfo, approveInfo.Tick, receiverPkScript)
 
 	// set from
 	fromTokenBalance.UpdateHeight = g.BestHeight
@@ -134,10 +137,12 @@ func (g *BRC20ModuleIndexer) ProcessInscribeApprove(data *model.InscriptionBRC20
 		return errors.New("module id invalid")
 	}
 
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok { // invalid module
 		return errors.New("module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
 	if len(body.Tick) != 4 {
 		return errors.New("tick invalid")
@@ -179,11 +184,13 @@ func (g *BRC20ModuleIndexer) ProcessInscribeApprove(data *model.InscriptionBRC20
 	moduleInfo.History = append(moduleInfo.History, history)
 
 	// Check if the module balance is sufficient to approve
-	moduleTokenBalance := moduleInfo.GetUserTokenBalance(approveInfo.Tick, data.PkScript)
+	moduleTokenBalance := g.getModuleUserTokenBalance(moduleInfo, approveInfo.Tick, data.PkScript)
 	// available > amt
 	if moduleTokenBalance.AvailableBalance.Cmp(balanceApprove) < 0 { // invalid
 		history.Valid = false
+		undoMapEntry(g, g.InscriptionsInvalidApproveMap, data.CreateIdxKey)
 		g.InscriptionsInvalidApproveMap[data.CreateIdxKey] = approveInfo
+		g.markStorageDirty(STORAGE_PREFIX_INVALID_APPROVE, data.CreateIdxKey)
 	} else {
 		history.Valid = true
 		// The available balance here needs to be directly deducted and transferred to ApproveableBalance.
@@ -199,7 +206,9 @@ func (g *BRC20ModuleIndexer) ProcessInscribeApprove(data *model.InscriptionBRC20
 
 		moduleTokenBalance.UpdateHeight = g.BestHeight
 		// Update global approve lookup table
+		undoMapEntry(g, g.InscriptionsValidApproveMap, data.CreateIdxKey)
 		g.InscriptionsValidApproveMap[data.CreateIdxKey] = approveInfo
+		g.markStorageDirty(STORAGE_PREFIX_APPROVE, data.CreateIdxKey)
 
 		// g.InscriptionsValidBRC20DataMap[data.CreateIdxKey] = approveInfo.Data  // fixme
 	}
@@ -15,9 +15,9 @@ func (g *BRC20ModuleIndexer) GetCommitInfoByKey(createIdxKey string) (
 	commitData *model.InscriptionBRC20Data, isInvalid bool) {
 	var ok bool
 	// commit
-	commitData, ok = g.InscriptionsValidCommitMap[createIdxKey]
+	commitData, ok = cacheEntry(g, STORAGE_PREFIX_COMMIT, g.InscriptionsValidCommitMap, createIdxKey, decodeGob[*model.InscriptionBRC20Data])
 	if !ok {
-		commitData, ok = g.InscriptionsInvalidCommitMap[createIdxKey]
+		commitData, ok = cacheEntry(g, STORAGE_PREFIX_INVALID_COMMIT, g.InscriptionsInvalidCommitMap, createIdxKey, decodeGob[*model.InscriptionBRC20Data])
 		if !ok {
 			commitData = nil
 		}
@@ -32,7 +32,9 @@ func (g *BRC20ModuleIndexer) ProcessCommit(dataFrom, dataTo *model.InscriptionBR
 	log.Printf("parse move commit. inscription id: %s", inscriptionId)
 
 	// Delete the already sent commit
+	undoMapEntry(g, g.InscriptionsValidCommitMapById, inscriptionId)
 	delete(g.InscriptionsValidCommitMapById, inscriptionId)
+	g.markStorageDirty(STORAGE_PREFIX_COMMIT_BY_ID, inscriptionId)
 
 	var body *model.InscriptionBRC20ModuleSwapCommitContent
 	if err := json.Unmarshal(dataFrom.ContentBody, &body); err != nil {
@@ -49,12 +51,15 @@ func (g *BRC20ModuleIndexer) ProcessCommit(dataFrom, dataTo *model.InscriptionBR
 	}
 
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok {
 		return errors.New("commit, module not exist")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
 	// preset invalid
+	undoMapEntry(g, moduleInfo.CommitInvalidMap, inscriptionId)
 	moduleInfo.CommitInvalidMap[inscriptionId] = struct{}{}
 
 	// Check the inscription sending address, it must be the sequencer address.
@@ -86,6 +91,8 @@ func (g *BRC20ModuleIndexer) ProcessCommit(dataFrom, dataTo *model.InscriptionBR
 	}
 
 	// set commit id
+	undoMapEntry(g, moduleInfo.CommitIdMap, inscriptionId)
+	undoMapEntry(g, moduleInfo.CommitIdChainMap, body.Parent)
 	moduleInfo.CommitIdMap[inscriptionId] = struct{}{}
 	moduleInfo.CommitIdChainMap[body.Parent] = struct{}{}
 
@@ -94,6 +101,8 @@ func (g *BRC20ModuleIndexer) ProcessCommit(dataFrom, dataTo *model.InscriptionBR
 
 	history := model.NewBRC20ModuleHistory(true, constant.BRC20_HISTORY_SWAP_TYPE_N_COMMIT, dataFrom, dataTo, nil, true)
 	moduleInfo.History = append(moduleInfo.History, history)
+
+	g.notify(func(o Observer) { o.OnCommitApplied(dataTo.Height, moduleInfo.ID, inscriptionId) })
 	return nil
 }
 
@@ -112,7 +121,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitCheck(data *model.InscriptionBRC20Data
 	}
 
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok {
 		return -1, errors.New("commit, module not exist")
 	}
@@ -130,11 +139,14 @@ func (g *BRC20ModuleIndexer) ProcessCommitCheck(data *model.InscriptionBRC20Data
 	}
 
 	// set commit id
+	undoMapEntry(g, moduleInfo.CommitIdMap, inscriptionId)
+	undoMapEntry(g, moduleInfo.CommitIdChainMap, body.Parent)
 	moduleInfo.CommitIdMap[inscriptionId] = struct{}{}
 	moduleInfo.CommitIdChainMap[body.Parent] = struct{}{}
 
 	// Delete the already sent commit
 	delete(g.InscriptionsValidCommitMapById, inscriptionId)
+	g.markStorageDirty(STORAGE_PREFIX_COMMIT_BY_ID, inscriptionId)
 
 	return 0, nil
 }
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
@@ -13,7 +12,7 @@ import (
 
 func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model.BRC20ModuleSwapInfo, f *model.SwapFunctionData) (err error) {
 	token0, token1 := f.Params[0], f.Params[1]
-	if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight < g.Rules.SwapWithdrawHeight {
 		token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 		if err != nil {
 			return errors.New("func: addLiq poolPair invalid")
@@ -25,6 +24,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model
 	if !ok {
 		return errors.New("addLiq: pool invalid")
 	}
+	g.undoModulePool(pool)
 
 	usersLpBalanceInPool, ok := moduleInfo.LPTokenUsersBalanceMap[poolPair]
 	if !ok {
@@ -35,7 +35,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model
 	log.Printf("pool addliq params: %v", f.Params)
 
 	offset := 0
-	if g.BestHeight >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight >= g.Rules.SwapWithdrawHeight {
 		offset = 1
 	}
 	token0AmtStr := f.Params[1+offset]
@@ -88,19 +88,13 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model
 				// pool lp update
 				pool.LpBalance = pool.LpBalance.Add(lpFee)
 
-				// lpFee lp balance update
+				// lpFee lp balance update, and lpFee-lp-balance
 				lpFeelpbalance := usersLpBalanceInPool[moduleInfo.LpFeePkScript]
 				lpFeelpbalance = lpFeelpbalance.Add(lpFee)
-				usersLpBalanceInPool[moduleInfo.LpFeePkScript] = lpFeelpbalance
+				g.setModuleLPBalance(moduleInfo, poolPair, moduleInfo.LpFeePkScript, lpFeelpbalance)
 				// set update flag
+				undoMapEntry(g, moduleInfo.LPTokenUsersBalanceUpdatedMap, poolPair+moduleInfo.LpFeePkScript)
 				moduleInfo.LPTokenUsersBalanceUpdatedMap[poolPair+moduleInfo.LpFeePkScript] = struct{}{}
-				// lpFee-lp-balance
-				lpFeelpsBalance, ok := moduleInfo.UsersLPTokenBalanceMap[moduleInfo.LpFeePkScript]
-				if !ok {
-					lpFeelpsBalance = make(map[string]*decimal.Decimal, 0)
-					moduleInfo.UsersLPTokenBalanceMap[moduleInfo.LpFeePkScript] = lpFeelpsBalance
-				}
-				lpFeelpsBalance[poolPair] = lpFeelpbalance
 			}
 		}
 
@@ -129,17 +123,17 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model
 	}
 
 	// User Balance Check
-	token0Balance := moduleInfo.GetUserTokenBalance(token0, f.PkScript)
-	token1Balance := moduleInfo.GetUserTokenBalance(token1, f.PkScript)
+	token0Balance := g.getModuleUserTokenBalance(moduleInfo, token0, f.PkScript)
+	token1Balance := g.getModuleUserTokenBalance(moduleInfo, token1, f.PkScript)
 	// fixme: Must use the confirmed amount
 	if token0Balance.SwapAccountBalance.Cmp(token0Amt) < 0 {
 		log.Printf("token0[%s] user[%s], balance %s", token0, f.Address, token0Balance)
-		return errors.New("addLiq: token0 balance insufficient")
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, "addLiq: token0 balance insufficient")
 	}
 	// fixme: Must use the confirmed amount
 	if token1Balance.SwapAccountBalance.Cmp(token1Amt) < 0 {
 		log.Printf("token1[%s] user[%s], balance %s", token1, f.Address, token1Balance)
-		return errors.New("addLiq: token1 balance insufficient")
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, "addLiq: token1 balance insufficient")
 	}
 
 	// User Real-time Balance Update
@@ -152,30 +146,16 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model
 	// fixme: User safety balance update
 
 	// lp balance update
-	// lp-user-balance
+	// lp-user-balance, and user-lp-balance
 	lpbalance := usersLpBalanceInPool[f.PkScript]
 	lpbalance = lpbalance.Add(lpForUser)
-	usersLpBalanceInPool[f.PkScript] = lpbalance
-	// user-lp-balance
-	lpsBalance, ok := moduleInfo.UsersLPTokenBalanceMap[f.PkScript]
-	if !ok {
-		lpsBalance = make(map[string]*decimal.Decimal, 0)
-		moduleInfo.UsersLPTokenBalanceMap[f.PkScript] = lpsBalance
-	}
-	lpsBalance[poolPair] = lpbalance
+	g.setModuleLPBalance(moduleInfo, poolPair, f.PkScript, lpbalance)
 
 	// zero address lp balance update
 	if first {
 		zerolpbalance := usersLpBalanceInPool[constant.ZERO_ADDRESS_PKSCRIPT]
 		zerolpbalance = zerolpbalance.Add(decimal.NewDecimal(1000, 18))
-		usersLpBalanceInPool[constant.ZERO_ADDRESS_PKSCRIPT] = zerolpbalance
-		// zerouser-lp-balance
-		zerolpsBalance, ok := moduleInfo.UsersLPTokenBalanceMap[constant.ZERO_ADDRESS_PKSCRIPT]
-		if !ok {
-			zerolpsBalance = make(map[string]*decimal.Decimal, 0)
-			moduleInfo.UsersLPTokenBalanceMap[constant.ZERO_ADDRESS_PKSCRIPT] = zerolpsBalance
-		}
-		zerolpsBalance[poolPair] = zerolpbalance
+		g.setModuleLPBalance(moduleInfo, poolPair, constant.ZERO_ADDRESS_PKSCRIPT, zerolpbalance)
 	}
 
 	// Changes in pool balance
@@ -189,5 +169,6 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionAddLiquidity(moduleInfo *model
 	pool.LastRootK = pool.TickBalance[token0Idx].Mul(pool.TickBalance[token1Idx]).Sqrt()
 
 	// log.Printf("[%s] pool after addliq [%s] %s: %s, %s: %s, lp: %s", moduleInfo.ID, poolPair, pool.Tick[0], pool.TickBalance[0], pool.Tick[1], pool.TickBalance[1], pool.LpBalance)
+	g.notify(func(o Observer) { o.OnPoolReservesChanged(g.BestHeight, moduleInfo.ID, poolPair, pool) })
 	return nil
 }
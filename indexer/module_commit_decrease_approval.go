@@ -1,9 +1,9 @@
 package indexer
 
 import (
-	"errors"
 	"log"
 
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 )
 
@@ -13,12 +13,12 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionDecreaseApproval(moduleInfo *m
 	tokenAmtStr := f.Params[1]
 	tokenAmt, _ := g.CheckTickVerify(token, tokenAmtStr)
 
-	tokenBalance := moduleInfo.GetUserTokenBalance(token, f.PkScript)
+	tokenBalance := g.getModuleUserTokenBalance(moduleInfo, token, f.PkScript)
 
 	// fixme: Must use the confirmed amount
 	if tokenBalance.SwapAccountBalance.Cmp(tokenAmt) < 0 {
 		log.Printf("token[%s] user[%s], balance %s", token, f.Address, tokenBalance)
-		return errors.New("decreaseApproval: token balance insufficient")
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, "decreaseApproval: token balance insufficient")
 	}
 
 	// User Real-time Balance Update
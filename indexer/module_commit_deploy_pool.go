@@ -15,6 +15,9 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionDeployPool(moduleInfo *model.B
 		return errors.New("deploy: twice")
 	}
 
+	undoMapEntry(g, moduleInfo.LPTokenUsersBalanceMap, poolPair)
+	undoMapEntry(g, moduleInfo.SwapPoolTotalBalanceDataMap, poolPair)
+
 	// lp token balance of address in module [pool][address]balance
 	moduleInfo.LPTokenUsersBalanceMap[poolPair] = make(map[string]*decimal.Decimal, 0)
 
@@ -30,5 +33,8 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionDeployPool(moduleInfo *model.B
 		TickBalance: [2]*decimal.Decimal{token0Amt, token1Amt},
 	}
 	log.Printf("[%s] pool deploy pool [%s]", moduleInfo.ID, poolPair)
+
+	pool := moduleInfo.SwapPoolTotalBalanceDataMap[poolPair]
+	g.notify(func(o Observer) { o.OnPoolReservesChanged(g.BestHeight, moduleInfo.ID, poolPair, pool) })
 	return nil
 }
@@ -2,10 +2,9 @@ package indexer
 
 import (
 	"encoding/hex"
-	"errors"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
@@ -13,19 +12,19 @@ import (
 
 func (g *BRC20ModuleIndexer) ProcessCommitFunctionGasFee(moduleInfo *model.BRC20ModuleSwapInfo, userPkScript string, gasAmt *decimal.Decimal) error {
 
-	tokenBalance := moduleInfo.GetUserTokenBalance(moduleInfo.GasTick, userPkScript)
+	tokenBalance := g.getModuleUserTokenBalance(moduleInfo, moduleInfo.GasTick, userPkScript)
 	// fixme: Must use the confirmed amount
 	if tokenBalance.SwapAccountBalance.Cmp(gasAmt) < 0 {
-		address, err := utils.GetAddressFromScript([]byte(userPkScript), conf.GlobalNetParams)
+		address, err := utils.GetAddressFromScript([]byte(userPkScript), g.NetParams)
 		if err != nil {
 			address = hex.EncodeToString([]byte(userPkScript))
 		}
 
 		log.Printf("gas[%s] user[%s], balance %s", moduleInfo.GasTick, address, tokenBalance)
-		return errors.New("gas fee: token balance insufficient")
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, "gas fee: token balance insufficient")
 	}
 
-	gasToBalance := moduleInfo.GetUserTokenBalance(moduleInfo.GasTick, moduleInfo.GasToPkScript)
+	gasToBalance := g.getModuleUserTokenBalance(moduleInfo, moduleInfo.GasTick, moduleInfo.GasToPkScript)
 
 	// User Real-time gas Balance Update
 	tokenBalance.SwapAccountBalance = tokenBalance.SwapAccountBalance.Sub(gasAmt)
@@ -30,12 +30,15 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommit(data *model.InscriptionBRC20D
 	}
 
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok {
 		return errors.New("module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
 	// preset invalid
+	undoMapEntry(g, moduleInfo.CommitInvalidMap, inscriptionId)
 	moduleInfo.CommitInvalidMap[inscriptionId] = struct{}{}
 
 	// check sequencer match
@@ -48,8 +51,12 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommit(data *model.InscriptionBRC20D
 		log.Printf("commit invalid inscribe. function[%d], %s, txid: %s", idx, err, hex.EncodeToString([]byte(data.TxId)))
 		return err
 	}
+	undoMapEntry(g, g.InscriptionsValidCommitMap, data.CreateIdxKey)
+	undoMapEntry(g, g.InscriptionsValidCommitMapById, inscriptionId)
 	g.InscriptionsValidCommitMap[data.CreateIdxKey] = data
 	g.InscriptionsValidCommitMapById[inscriptionId] = data
+	g.markStorageDirty(STORAGE_PREFIX_COMMIT, data.CreateIdxKey)
+	g.markStorageDirty(STORAGE_PREFIX_COMMIT_BY_ID, inscriptionId)
 
 	// valid
 	delete(moduleInfo.CommitInvalidMap, inscriptionId)
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
@@ -13,7 +13,7 @@ import (
 
 func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *model.BRC20ModuleSwapInfo, f *model.SwapFunctionData) (err error) {
 	token0, token1 := f.Params[0], f.Params[1]
-	if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight < g.Rules.SwapWithdrawHeight {
 		token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 		if err != nil {
 			return errors.New("func: removeLiq poolPair invalid")
@@ -24,6 +24,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *mo
 	if !ok {
 		return errors.New("removeLiq: pool invalid")
 	}
+	g.undoModulePool(pool)
 	usersLpBalanceInPool, ok := moduleInfo.LPTokenUsersBalanceMap[poolPair]
 	if !ok {
 		return errors.New("removeLiq: lps balance map missing pair")
@@ -37,7 +38,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *mo
 	log.Printf("pool removeliq params: %v", f.Params)
 
 	offset := 0
-	if g.BestHeight >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight >= g.Rules.SwapWithdrawHeight {
 		offset = 1
 	}
 
@@ -74,19 +75,13 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *mo
 			// pool lp update
 			pool.LpBalance = pool.LpBalance.Add(lpFee)
 
-			// lpFee update
+			// lpFee update, and lpFee-lp-balance
 			lpFeelpbalance := usersLpBalanceInPool[moduleInfo.LpFeePkScript]
 			lpFeelpbalance = lpFeelpbalance.Add(lpFee)
-			usersLpBalanceInPool[moduleInfo.LpFeePkScript] = lpFeelpbalance
+			g.setModuleLPBalance(moduleInfo, poolPair, moduleInfo.LpFeePkScript, lpFeelpbalance)
 			// set update flag
+			undoMapEntry(g, moduleInfo.LPTokenUsersBalanceUpdatedMap, poolPair+moduleInfo.LpFeePkScript)
 			moduleInfo.LPTokenUsersBalanceUpdatedMap[poolPair+moduleInfo.LpFeePkScript] = struct{}{}
-			// lpFee-lp-balance
-			lpFeelpsBalance, ok := moduleInfo.UsersLPTokenBalanceMap[moduleInfo.LpFeePkScript]
-			if !ok {
-				lpFeelpsBalance = make(map[string]*decimal.Decimal, 0)
-				moduleInfo.UsersLPTokenBalanceMap[moduleInfo.LpFeePkScript] = lpFeelpsBalance
-			}
-			lpFeelpsBalance[poolPair] = lpFeelpbalance
 		}
 	}
 
@@ -104,13 +99,13 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *mo
 
 	// Changes in pool balance
 	if pool.LpBalance.Cmp(tokenLpAmt) < 0 {
-		return errors.New(fmt.Sprintf("removeLiq: tokenLp balance insufficient, %s < %s", pool.LpBalance, tokenLpAmt))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("removeLiq: tokenLp balance insufficient, %s < %s", pool.LpBalance, tokenLpAmt))
 	}
 	if pool.TickBalance[token0Idx].Cmp(amt0) < 0 {
-		return errors.New(fmt.Sprintf("removeLiq: pool %s balance insufficient", pool.Tick[token1Idx]))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("removeLiq: pool %s balance insufficient", pool.Tick[token1Idx]))
 	}
 	if pool.TickBalance[token1Idx].Cmp(amt1) < 0 {
-		return errors.New(fmt.Sprintf("removeLiq: pool %s balance insufficient", pool.Tick[token1Idx]))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("removeLiq: pool %s balance insufficient", pool.Tick[token1Idx]))
 	}
 
 	// Check whether the user's LP balance is consistent (consider storing only one copy)
@@ -121,18 +116,20 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *mo
 	}
 	// Check whether the balance of user LP is sufficient.
 	if userbalance.Cmp(tokenLpAmt) < 0 {
-		return errors.New(fmt.Sprintf("removeLiq: user's tokenLp balance insufficient, %s < %s", userbalance, tokenLpAmt))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("removeLiq: user's tokenLp balance insufficient, %s < %s", userbalance, tokenLpAmt))
 	}
 	if lpBalance.Cmp(tokenLpAmt) < 0 {
-		return errors.New(fmt.Sprintf("removeLiq: user's tokenLp balance insufficient, %s < %s", lpBalance, tokenLpAmt))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("removeLiq: user's tokenLp balance insufficient, %s < %s", lpBalance, tokenLpAmt))
 	}
 
 	// update lp balance
+	undoMapEntry(g, usersLpBalanceInPool, f.PkScript)
+	undoMapEntry(g, lpsBalance, poolPair)
 	usersLpBalanceInPool[f.PkScript] = userbalance.Sub(tokenLpAmt)
 	lpsBalance[poolPair] = lpBalance.Sub(tokenLpAmt)
 
-	token0Balance := moduleInfo.GetUserTokenBalance(token0, f.PkScript)
-	token1Balance := moduleInfo.GetUserTokenBalance(token1, f.PkScript)
+	token0Balance := g.getModuleUserTokenBalance(moduleInfo, token0, f.PkScript)
+	token1Balance := g.getModuleUserTokenBalance(moduleInfo, token1, f.PkScript)
 
 	// Obtains user token balance
 	token0Balance.SwapAccountBalance = token0Balance.SwapAccountBalance.Add(amt0)
@@ -153,5 +150,6 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionRemoveLiquidity(moduleInfo *mo
 	pool.LastRootK = pool.TickBalance[token0Idx].Mul(pool.TickBalance[token1Idx]).Sqrt()
 
 	// log.Printf("[%s] pool after removeliq [%s] %s: %s, %s: %s, lp: %s", moduleInfo.ID, poolPair, pool.Tick[0], pool.TickBalance[0], pool.Tick[1], pool.TickBalance[1], pool.LpBalance)
+	g.notify(func(o Observer) { o.OnPoolReservesChanged(g.BestHeight, moduleInfo.ID, poolPair, pool) })
 	return nil
 }
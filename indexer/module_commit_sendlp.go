@@ -5,15 +5,14 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
-	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
 )
 
 func (g *BRC20ModuleIndexer) ProcessCommitFunctionSendLp(moduleInfo *model.BRC20ModuleSwapInfo, f *model.SwapFunctionData) error {
 	addressTo := f.Params[0]
-	pkScriptTo, _ := utils.GetPkScriptByAddress(addressTo, conf.GlobalNetParams)
+	pkScriptTo, _ := utils.GetPkScriptByAddress(addressTo, g.NetParams)
 
 	token0, token1 := f.Params[1], f.Params[2]
 	poolPair := GetLowerInnerPairNameByToken(token0, token1)
@@ -41,32 +40,30 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionSendLp(moduleInfo *model.BRC20
 	tokenLpAmt, _ := CheckAmountVerify(tokenAmtStr, 18)
 	// Check if the user's lp balance is sufficient.
 	if userbalanceFrom.Cmp(tokenLpAmt) < 0 {
-		return errors.New(fmt.Sprintf("sendlp: user's tokenLp balance insufficient, %s < %s", userbalanceFrom, tokenLpAmt))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("sendlp: user's tokenLp balance insufficient, %s < %s", userbalanceFrom, tokenLpAmt))
 	}
 	if lpBalanceFrom.Cmp(tokenLpAmt) < 0 {
-		return errors.New(fmt.Sprintf("sendlp: user's tokenLp balance insufficient, %s < %s", lpBalanceFrom, tokenLpAmt))
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("sendlp: user's tokenLp balance insufficient, %s < %s", lpBalanceFrom, tokenLpAmt))
 	}
 
 	// update from lp balance
+	undoMapEntry(g, usersLpBalanceInPool, f.PkScript)
+	undoMapEntry(g, lpsBalanceFrom, poolPair)
 	usersLpBalanceInPool[f.PkScript] = userbalanceFrom.Sub(tokenLpAmt)
 	lpsBalanceFrom[poolPair] = lpBalanceFrom.Sub(tokenLpAmt)
 
 	// update to lp balance
 	lpBalanceTo := usersLpBalanceInPool[string(pkScriptTo)]
 	lpBalanceTo = lpBalanceTo.Add(tokenLpAmt)
-	usersLpBalanceInPool[string(pkScriptTo)] = lpBalanceTo
 
 	// set update flag
+	undoMapEntry(g, moduleInfo.LPTokenUsersBalanceUpdatedMap, poolPair+f.PkScript)
+	undoMapEntry(g, moduleInfo.LPTokenUsersBalanceUpdatedMap, poolPair+string(pkScriptTo))
 	moduleInfo.LPTokenUsersBalanceUpdatedMap[poolPair+f.PkScript] = struct{}{}
 	moduleInfo.LPTokenUsersBalanceUpdatedMap[poolPair+string(pkScriptTo)] = struct{}{}
 
 	// touser-lp-balance
-	lpsBalanceTo, ok := moduleInfo.UsersLPTokenBalanceMap[string(pkScriptTo)]
-	if !ok {
-		lpsBalanceTo = make(map[string]*decimal.Decimal, 0)
-		moduleInfo.UsersLPTokenBalanceMap[string(pkScriptTo)] = lpsBalanceTo
-	}
-	lpsBalanceTo[poolPair] = lpBalanceTo
+	g.setModuleLPBalance(moduleInfo, poolPair, string(pkScriptTo), lpBalanceTo)
 
 	log.Printf("pool sendlp [%s] lp: %s -> %s", poolPair, lpBalanceFrom, lpBalanceTo)
 
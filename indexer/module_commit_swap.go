@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
@@ -22,7 +22,7 @@ import (
 //	amountIn = (reserveIn * amountOut * 1000)/((reserveOut - amountOut) * 997) + 1
 func (g *BRC20ModuleIndexer) ProcessCommitFunctionSwap(moduleInfo *model.BRC20ModuleSwapInfo, f *model.SwapFunctionData) (err error) {
 	token0, token1 := f.Params[0], f.Params[1]
-	if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight < g.Rules.SwapWithdrawHeight {
 		token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 		if err != nil {
 			return errors.New("func: swap poolPair invalid")
@@ -33,6 +33,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionSwap(moduleInfo *model.BRC20Mo
 	if !ok {
 		return errors.New("swap: pool invalid")
 	}
+	g.undoModulePool(pool)
 
 	if token0 != pool.Tick[0] && token0 != pool.Tick[1] {
 		return errors.New("func: swap token invalid")
@@ -45,7 +46,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionSwap(moduleInfo *model.BRC20Mo
 	log.Printf("pool swap params: %v", f.Params)
 
 	offset := 0
-	if g.BestHeight >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight >= g.Rules.SwapWithdrawHeight {
 		offset = 1
 	}
 
@@ -136,17 +137,17 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionSwap(moduleInfo *model.BRC20Mo
 
 	// Check the balance range, prepare to update.
 	if pool.TickBalance[tokenOutIdx].Cmp(amountOut) < 0 {
-		return errors.New("swap: pool tokenOut balance insufficient")
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, "swap: pool tokenOut balance insufficient")
 	}
 
-	tokenInBalance := moduleInfo.GetUserTokenBalance(tokenIn, f.PkScript)
-	tokenOutBalance := moduleInfo.GetUserTokenBalance(tokenOut, f.PkScript)
+	tokenInBalance := g.getModuleUserTokenBalance(moduleInfo, tokenIn, f.PkScript)
+	tokenOutBalance := g.getModuleUserTokenBalance(moduleInfo, tokenOut, f.PkScript)
 
 	tokenInBalance.UpdateHeight = g.BestHeight
 	tokenOutBalance.UpdateHeight = g.BestHeight
 
 	if tokenInBalance.SwapAccountBalance.Cmp(tokenInAmt) < 0 {
-		return errors.New(fmt.Sprintf("swap[%s]: user tokenIn balance insufficient: %s < %s",
+		return newQuietRejectError(constant.BRC20_REJECT_INSUFFICIENT_BALANCE, fmt.Sprintf("swap[%s]: user tokenIn balance insufficient: %s < %s",
 			f.ID,
 			tokenInBalance.SwapAccountBalance, tokenInAmt))
 	}
@@ -162,5 +163,6 @@ func (g *BRC20ModuleIndexer) ProcessCommitFunctionSwap(moduleInfo *model.BRC20Mo
 	pool.UpdateHeight = g.BestHeight
 
 	// log.Printf("[%s] pool after swap [%s] %s: %s, %s: %s, lp: %s", moduleInfo.ID, poolPair, pool.Tick[0], pool.TickBalance[0], pool.Tick[1], pool.TickBalance[1], pool.LpBalance)
+	g.notify(func(o Observer) { o.OnPoolReservesChanged(g.BestHeight, moduleInfo.ID, poolPair, pool) })
 	return nil
 }
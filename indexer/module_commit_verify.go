@@ -9,35 +9,41 @@ import (
 	"os"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
 )
 
+// GResultsExternal Results loaded by InitResultDataFromFile, for callers to pass as Options.ResultsExternal.
 var GResultsExternal []*model.SwapFunctionResultCheckState
 
 func InitResultDataFromFile(fname string) (err error) {
+	GResultsExternal, err = LoadResultDataFromFile(fname)
+	return err
+}
+
+// LoadResultDataFromFile Load expected commit results, for Options.ResultsExternal.
+func LoadResultDataFromFile(fname string) (results []*model.SwapFunctionResultCheckState, err error) {
 	// Open our jsonFile
 	jsonFile, err := os.Open(fname)
 	// if we os.Open returns an error then handle it
 	if err != nil {
 		fmt.Println(err)
-		return err
+		return nil, err
 	}
 	// defer the closing of our jsonFile so that we can parse it later on
 	defer jsonFile.Close()
 
 	byteValue, err := ioutil.ReadAll(jsonFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = json.Unmarshal([]byte(byteValue), &GResultsExternal)
+	err = json.Unmarshal([]byte(byteValue), &results)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return results, nil
 }
 
 func (g *BRC20ModuleIndexer) BRC20ModulePrepareSwapCommitContent(
@@ -60,7 +66,9 @@ func (g *BRC20ModuleIndexer) BRC20ModulePrepareSwapCommitContent(
 			InscriptionId: nextCommitObj.Parent,
 			ContentBody:   []byte(commitStr),
 		}
+		undoMapEntry(g, g.InscriptionsValidCommitMapById, nextCommitObj.Parent)
 		g.InscriptionsValidCommitMapById[nextCommitObj.Parent] = data
+		g.markStorageDirty(STORAGE_PREFIX_COMMIT_BY_ID, nextCommitObj.Parent)
 	}
 }
 
@@ -81,7 +89,7 @@ func (g *BRC20ModuleIndexer) BRC20ModuleVerifySwapCommitContent(
 
 	// Verifying commit that was not moved in the middle
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[commitObj.Module]
+	moduleInfo, ok := g.moduleOf(commitObj.Module)
 	if !ok {
 		return -1, true, errors.New("commit, module not exist")
 	}
@@ -136,7 +144,7 @@ func (g *BRC20ModuleIndexer) BRC20ResultsPreVerify(moduleInfo *model.BRC20Module
 		userPkScript := constant.ZERO_ADDRESS_PKSCRIPT
 		// format check
 		if user.Address != "0" {
-			if pk, err := utils.GetPkScriptByAddress(user.Address, conf.GlobalNetParams); err != nil {
+			if pk, err := utils.GetPkScriptByAddress(user.Address, g.NetParams); err != nil {
 				return errors.New(fmt.Sprintf("result users[%d] addr(%s) invalid", idxUser, user.Address))
 			} else {
 				userPkScript = string(pk)
@@ -149,7 +157,7 @@ func (g *BRC20ModuleIndexer) BRC20ResultsPreVerify(moduleInfo *model.BRC20Module
 			}
 
 			// balance check
-			tokenBalance := moduleInfo.GetUserTokenBalance(user.Tick, userPkScript)
+			tokenBalance := g.getModuleUserTokenBalance(moduleInfo, user.Tick, userPkScript)
 			if tokenBalance.SwapAccountBalance.Cmp(tokenAmt) != 0 {
 				return errors.New(fmt.Sprintf("result users[%d] %s amount not match (%s != %s)",
 					idxUser, user.Tick,
@@ -251,7 +259,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 	}
 
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok {
 		return -1, errors.New("module invalid")
 	}
@@ -272,14 +280,14 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 	}
 
 	paramOffset := 0
-	if g.BestHeight >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight >= g.Rules.SwapWithdrawHeight {
 		paramOffset = 1
 	}
 
 	// for previous id
 	functionsByAddressMap := make(map[string][]string)
 	for idx, f := range body.Data {
-		if pkScript, err := utils.GetPkScriptByAddress(f.Address, conf.GlobalNetParams); err != nil {
+		if pkScript, err := utils.GetPkScriptByAddress(f.Address, g.NetParams); err != nil {
 			return idx, errors.New("addr invalid")
 		} else {
 			f.PkScript = string(pkScript)
@@ -322,7 +330,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 			}
 
 			token0, token1 := f.Params[0], f.Params[1]
-			if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight < g.Rules.SwapWithdrawHeight {
 				token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 				if err != nil {
 					return idx, errors.New("func: addLiq poolPair invalid")
@@ -356,7 +364,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 			}
 
 			token0, token1 := f.Params[0], f.Params[1]
-			if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight < g.Rules.SwapWithdrawHeight {
 				token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 				if err != nil {
 					return idx, errors.New("func: removeLiq poolPair invalid")
@@ -389,7 +397,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 			}
 
 			token0, token1 := f.Params[0], f.Params[1]
-			if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight < g.Rules.SwapWithdrawHeight {
 				token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 				if err != nil {
 					return idx, errors.New("func: swap poolPair invalid")
@@ -460,7 +468,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 			}
 
 			addressTo := f.Params[0]
-			if _, err := utils.GetPkScriptByAddress(addressTo, conf.GlobalNetParams); err != nil {
+			if _, err := utils.GetPkScriptByAddress(addressTo, g.NetParams); err != nil {
 				return idx, errors.New("send addr invalid")
 			}
 
@@ -476,7 +484,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeCommitPreVerify(body *model.Inscript
 			}
 
 			addressTo := f.Params[0]
-			if _, err := utils.GetPkScriptByAddress(addressTo, conf.GlobalNetParams); err != nil {
+			if _, err := utils.GetPkScriptByAddress(addressTo, g.NetParams); err != nil {
 				return idx, errors.New("send addr invalid")
 			}
 
@@ -503,7 +511,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitVerify(commitId string, body *model.In
 	eachFuntionSize []uint64, results []*model.SwapFunctionResultCheckState) (index int, critical bool, err error) {
 
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok {
 		return -1, true, errors.New("commit, module not exist")
 	}
@@ -531,7 +539,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitVerify(commitId string, body *model.In
 		return -1, true, errors.New("commit, function size not match data")
 	}
 	for idx, f := range body.Data {
-		if pkScript, err := utils.GetPkScriptByAddress(f.Address, conf.GlobalNetParams); err != nil {
+		if pkScript, err := utils.GetPkScriptByAddress(f.Address, g.NetParams); err != nil {
 			return idx, true, errors.New("commit, addr invalid")
 		} else {
 			f.PkScript = string(pkScript)
@@ -540,7 +548,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitVerify(commitId string, body *model.In
 		// gas fee
 		if gasPriceAmt.Sign() > 0 {
 			size := eachFuntionSize[idx]
-			if g.BestHeight >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight >= g.Rules.SwapWithdrawHeight {
 				size = 1
 			}
 			gasAmt := gasPriceAmt.Mul(decimal.NewDecimal(size, 3))
@@ -604,10 +612,10 @@ func (g *BRC20ModuleIndexer) ProcessCommitVerify(commitId string, body *model.In
 		}
 
 		// verify test result
-		if GResultsExternal == nil {
+		if g.ResultsExternal == nil {
 			continue
 		}
-		for _, result := range GResultsExternal {
+		for _, result := range g.ResultsExternal {
 			if result.CommitId != commitId {
 				continue
 			}
@@ -628,7 +636,7 @@ func (g *BRC20ModuleIndexer) ProcessCommitVerify(commitId string, body *model.In
 
 func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20ModuleSwapCommitContent, pickUsersPkScript, pickTokensTick, pickPoolsPair map[string]bool) (index int, err error) {
 	// check module exist
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok {
 		return -1, errors.New("module invalid")
 	}
@@ -641,12 +649,12 @@ func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20Mo
 	pickTokensTick[moduleInfo.GasTick] = true
 
 	paramOffset := 0
-	if g.BestHeight >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if g.BestHeight >= g.Rules.SwapWithdrawHeight {
 		paramOffset = 1
 	}
 
 	for idx, f := range body.Data {
-		if pkScript, err := utils.GetPkScriptByAddress(f.Address, conf.GlobalNetParams); err != nil {
+		if pkScript, err := utils.GetPkScriptByAddress(f.Address, g.NetParams); err != nil {
 			return idx, errors.New("addr invalid")
 		} else {
 			pickUsersPkScript[string(pkScript)] = true
@@ -676,7 +684,7 @@ func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20Mo
 			}
 
 			token0, token1 := f.Params[0], f.Params[1]
-			if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight < g.Rules.SwapWithdrawHeight {
 				token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 				if err != nil {
 					return idx, errors.New("func: addLiq poolPair invalid")
@@ -698,7 +706,7 @@ func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20Mo
 			}
 
 			token0, token1 := f.Params[0], f.Params[1]
-			if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight < g.Rules.SwapWithdrawHeight {
 				token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 				if err != nil {
 					return idx, errors.New("func: removeLiq poolPair invalid")
@@ -720,7 +728,7 @@ func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20Mo
 			}
 
 			token0, token1 := f.Params[0], f.Params[1]
-			if g.BestHeight < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+			if g.BestHeight < g.Rules.SwapWithdrawHeight {
 				token0, token1, err = utils.DecodeTokensFromSwapPair(f.Params[0])
 				if err != nil {
 					return idx, errors.New("func: swap poolPair invalid")
@@ -751,7 +759,7 @@ func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20Mo
 			}
 
 			addressTo := f.Params[0]
-			if pk, err := utils.GetPkScriptByAddress(addressTo, conf.GlobalNetParams); err != nil {
+			if pk, err := utils.GetPkScriptByAddress(addressTo, g.NetParams); err != nil {
 				return idx, errors.New("send addr invalid")
 			} else {
 				pickUsersPkScript[string(pk)] = true
@@ -767,7 +775,7 @@ func (g *BRC20ModuleIndexer) InitCherryPickFilter(body *model.InscriptionBRC20Mo
 			}
 
 			addressTo := f.Params[0]
-			if pk, err := utils.GetPkScriptByAddress(addressTo, conf.GlobalNetParams); err != nil {
+			if pk, err := utils.GetPkScriptByAddress(addressTo, g.NetParams); err != nil {
 				return idx, errors.New("send addr invalid")
 			} else {
 				pickUsersPkScript[string(pk)] = true
@@ -8,7 +8,6 @@ import (
 	"log"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
@@ -19,9 +18,9 @@ func (g *BRC20ModuleIndexer) GetConditionalApproveInfoByKey(createIdxKey string)
 	approveInfo *model.InscriptionBRC20SwapConditionalApproveInfo, isInvalid bool) {
 	var ok bool
 	// approve
-	approveInfo, ok = g.InscriptionsValidConditionalApproveMap[createIdxKey]
+	approveInfo, ok = cacheEntry(g, STORAGE_PREFIX_COND_APPROVE, g.InscriptionsValidConditionalApproveMap, createIdxKey, decodeGob[*model.InscriptionBRC20SwapConditionalApproveInfo])
 	if !ok {
-		approveInfo, ok = g.InscriptionsInvalidConditionalApproveMap[createIdxKey]
+		approveInfo, ok = cacheEntry(g, STORAGE_PREFIX_INVALID_COND_APPROVE, g.InscriptionsInvalidConditionalApproveMap, createIdxKey, decodeGob[*model.InscriptionBRC20SwapConditionalApproveInfo])
 		if !ok {
 			approveInfo = nil
 		}
@@ -41,13 +40,17 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApprove(data *model.InscriptionBR
 		return errors.New("approve, invalid ticker")
 	}
 
-	moduleInfo, ok := g.ModulesInfoMap[approveInfo.Module]
+	moduleInfo, ok := g.moduleOf(approveInfo.Module)
 	if !ok {
 		log.Printf("ProcessBRC20ConditionalApprove send approve, but module invalid. txid: %s",
 			hex.EncodeToString(utils.ReverseBytes([]byte(data.TxId))),
 		)
 		return errors.New("approve, module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
+	g.touchConditionalApproveInfo(approveInfo)
+	g.undoConditionalApproveInfo(approveInfo)
 
 	// global invalid history
 	if isInvalid {
@@ -122,12 +125,12 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 		inscriptionId := event.FromData.GetInscriptionId()
 
 		// from address
-		addressFrom, err := utils.GetAddressFromScript([]byte(event.From), conf.GlobalNetParams)
+		addressFrom, err := utils.GetAddressFromScript([]byte(event.From), g.NetParams)
 		if err != nil {
 			addressFrom = hex.EncodeToString([]byte(event.From))
 		}
 		// to address
-		addressTo, err := utils.GetAddressFromScript([]byte(event.To), conf.GlobalNetParams)
+		addressTo, err := utils.GetAddressFromScript([]byte(event.To), g.NetParams)
 		if err != nil {
 			addressTo = hex.EncodeToString([]byte(event.From))
 		}
@@ -144,10 +147,12 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 			return errors.New("approve event, invalid ticker")
 		}
 
-		moduleInfo, ok := g.ModulesInfoMap[event.Module]
+		moduleInfo, ok := g.moduleOf(event.Module)
 		if !ok {
 			return errors.New("approve event, module invalid")
 		}
+		g.touchModule(moduleInfo)
+		g.undoModule(moduleInfo)
 
 		// global history
 		data := &model.BRC20SwapHistoryCondApproveData{
@@ -179,6 +184,7 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 			)
 			return errors.New("approve, send from ticker missing")
 		}
+		g.undoModuleTokenBalance(fromTokenBalance)
 
 		// Cross-check whether the approve inscription exists.
 		if _, ok := fromTokenBalance.ValidConditionalApproveMap[event.ToData.CreateIdxKey]; !ok {
@@ -190,7 +196,7 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 		}
 
 		// to
-		tokenBalance := moduleInfo.GetUserTokenBalance(event.Tick, event.To)
+		tokenBalance := g.getModuleUserTokenBalance(moduleInfo, event.Tick, event.To)
 
 		// set from
 		fromTokenBalance.CondApproveableBalance = fromTokenBalance.CondApproveableBalance.Sub(event.Amount)
@@ -215,7 +221,7 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 		tokenBalance.History = append(tokenBalance.History, toHistory)
 
 		// record state
-		stateBalance := moduleInfo.GetTickConditionalApproveStateBalance(event.Tick)
+		stateBalance := g.getModuleConditionalApproveStateBalance(moduleInfo, event.Tick)
 		if event.From == event.To {
 			stateBalance.BalanceCancelApprove = stateBalance.BalanceCancelApprove.Add(event.Amount)
 		} else {
@@ -224,6 +230,8 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 	}
 
 	for _, event := range events {
+		g.touchConditionalApproveInfo(event.ApproveInfo)
+		g.undoConditionalApproveInfo(event.ApproveInfo)
 		event.ApproveInfo.UpdateHeight = g.BestHeight
 		event.ApproveInfo.Balance = event.Balance
 	}
@@ -231,7 +239,7 @@ func (g *BRC20ModuleIndexer) ProcessConditionalApproveEvents(events []*model.Con
 }
 
 func (g *BRC20ModuleIndexer) ProcessInscribeConditionalApprove(data *model.InscriptionBRC20Data) error {
-	if data.Height >= conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if data.Height >= g.Rules.SwapWithdrawHeight {
 		return errors.New("invalid operation")
 	}
 
@@ -248,10 +256,12 @@ func (g *BRC20ModuleIndexer) ProcessInscribeConditionalApprove(data *model.Inscr
 		return errors.New("module id invalid")
 	}
 
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok { // invalid module
 		return errors.New("module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
 	if len(body.Tick) != 4 {
 		return errors.New("tick invalid")
@@ -296,10 +306,12 @@ func (g *BRC20ModuleIndexer) ProcessInscribeConditionalApprove(data *model.Inscr
 	history := model.NewBRC20ModuleHistory(false, constant.BRC20_HISTORY_SWAP_TYPE_N_INSCRIBE_CONDITIONAL_APPROVE, data, data, historyData, true)
 	moduleInfo.History = append(moduleInfo.History, history)
 
-	moduleTokenBalance := moduleInfo.GetUserTokenBalance(condApproveInfo.Tick, data.PkScript)
+	moduleTokenBalance := g.getModuleUserTokenBalance(moduleInfo, condApproveInfo.Tick, data.PkScript)
 	if moduleTokenBalance.AvailableBalance.Cmp(balanceCondApprove) < 0 { // invalid
 		history.Valid = false
+		undoMapEntry(g, g.InscriptionsInvalidConditionalApproveMap, data.CreateIdxKey)
 		g.InscriptionsInvalidConditionalApproveMap[data.CreateIdxKey] = condApproveInfo
+		g.markStorageDirty(STORAGE_PREFIX_INVALID_COND_APPROVE, data.CreateIdxKey)
 	} else {
 		history.Valid = true
 		// The available balance here will be directly deducted and transferred to ApproveableBalance.
@@ -317,11 +329,13 @@ func (g *BRC20ModuleIndexer) ProcessInscribeConditionalApprove(data *model.Inscr
 		moduleTokenBalance.UpdateHeight = g.BestHeight
 
 		// Update global approve lookup table
+		undoMapEntry(g, g.InscriptionsValidConditionalApproveMap, data.CreateIdxKey)
 		g.InscriptionsValidConditionalApproveMap[data.CreateIdxKey] = condApproveInfo
+		g.markStorageDirty(STORAGE_PREFIX_COND_APPROVE, data.CreateIdxKey)
 		// g.InscriptionsValidBRC20DataMap[data.CreateIdxKey] = condApproveInfo.Data  // fixme
 
 		// record state
-		stateBalance := moduleInfo.GetTickConditionalApproveStateBalance(condApproveInfo.Tick)
+		stateBalance := g.getModuleConditionalApproveStateBalance(moduleInfo, condApproveInfo.Tick)
 		stateBalance.BalanceNewApprove = stateBalance.BalanceNewApprove.Add(balanceCondApprove)
 	}
 
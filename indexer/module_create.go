@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
@@ -23,14 +22,18 @@ func (g *BRC20ModuleIndexer) ProcessCreateModule(data *model.InscriptionBRC20Dat
 		return err
 	}
 
-	if conf.MODULE_SWAP_SOURCE_INSCRIPTION_ID != body.Source {
+	// networks without a module source in rules have no swap module
+	if g.Rules.ModuleSwapSourceInscriptionId == "" {
+		return errors.New("module source not set in rules")
+	}
+	if g.Rules.ModuleSwapSourceInscriptionId != body.Source {
 		return errors.New(fmt.Sprintf("source not match: %s", body.Source))
 	}
 
 	inscriptionId := data.GetInscriptionId()
 	log.Printf("create module: %s", inscriptionId)
 
-	if _, ok := g.ModulesInfoMap[inscriptionId]; ok {
+	if _, ok := g.moduleOf(inscriptionId); ok {
 		return errors.New("dup module deploy") // impossible
 	}
 
@@ -57,7 +60,7 @@ func (g *BRC20ModuleIndexer) ProcessCreateModule(data *model.InscriptionBRC20Dat
 	// sequencer default
 	sequencerPkScript := data.PkScript
 	if sequencer, ok := body.Init["sequencer"]; ok {
-		if pk, err := utils.GetPkScriptByAddress(sequencer, conf.GlobalNetParams); err != nil {
+		if pk, err := utils.GetPkScriptByAddress(sequencer, g.NetParams); err != nil {
 			return errors.New("sequencer invalid")
 		} else {
 			sequencerPkScript = string(pk)
@@ -69,7 +72,7 @@ func (g *BRC20ModuleIndexer) ProcessCreateModule(data *model.InscriptionBRC20Dat
 	// gasTo default
 	gasToPkScript := data.PkScript
 	if gasTo, ok := body.Init["gas_to"]; ok {
-		if pk, err := utils.GetPkScriptByAddress(gasTo, conf.GlobalNetParams); err != nil {
+		if pk, err := utils.GetPkScriptByAddress(gasTo, g.NetParams); err != nil {
 			return errors.New("gasTo invalid")
 		} else {
 			gasToPkScript = string(pk)
@@ -81,7 +84,7 @@ func (g *BRC20ModuleIndexer) ProcessCreateModule(data *model.InscriptionBRC20Dat
 	// lpFeeTo default
 	lpFeeToPkScript := data.PkScript
 	if lpFeeTo, ok := body.Init["fee_to"]; ok {
-		if pk, err := utils.GetPkScriptByAddress(lpFeeTo, conf.GlobalNetParams); err != nil {
+		if pk, err := utils.GetPkScriptByAddress(lpFeeTo, g.NetParams); err != nil {
 			return errors.New("lpFeeTo invalid")
 		} else {
 			lpFeeToPkScript = string(pk)
@@ -139,7 +142,9 @@ func (g *BRC20ModuleIndexer) ProcessCreateModule(data *model.InscriptionBRC20Dat
 	history := model.NewBRC20ModuleHistory(false, constant.BRC20_HISTORY_MODULE_TYPE_N_INSCRIBE_MODULE, data, data, nil, true)
 	m.History = append(m.History, history)
 
+	undoMapEntry(g, g.ModulesInfoMap, inscriptionId)
 	g.ModulesInfoMap[inscriptionId] = m
+	g.markStorageDirty(STORAGE_PREFIX_MODULE, inscriptionId)
 
 	return nil
 }
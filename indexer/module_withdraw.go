@@ -8,7 +8,6 @@ import (
 	"log"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
@@ -38,13 +37,17 @@ func (g *BRC20ModuleIndexer) ProcessWithdraw(data *model.InscriptionBRC20Data, w
 		return errors.New("transfer, invalid ticker")
 	}
 
-	moduleInfo, ok := g.ModulesInfoMap[withdrawInfo.Module]
+	moduleInfo, ok := g.moduleOf(withdrawInfo.Module)
 	if !ok {
 		log.Printf("ProcessBRC20Withdraw send withdraw, but ticker invalid. txid: %s",
 			hex.EncodeToString(utils.ReverseBytes([]byte(data.TxId))),
 		)
 		return errors.New("withdraw, module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
+	g.touchTokenInfo(tokenInfo)
+	g.undoTokenInfo(tokenInfo)
 
 	var isInvalid bool
 
@@ -80,6 +83,7 @@ func (g *BRC20ModuleIndexer) ProcessWithdraw(data *model.InscriptionBRC20Data, w
 		)
 		return errors.New("withdraw, send from ticker missing")
 	}
+	g.undoModuleTokenBalance(fromTokenBalance)
 
 	// Cross-check whether the withdraw-inscription exists.
 	if _, ok := fromTokenBalance.ReadyToWithdrawMap[data.CreateIdxKey]; !ok {
@@ -159,6 +163,11 @@ func (g *BRC20ModuleIndexer) ProcessWithdraw(data *model.InscriptionBRC20Data, w
 	// toHistory := model.NewBRC20ModuleHistory(true, constant.BRC20_HISTORY_MODULE_TYPE_N_WITHDRAW_TO, withdrawInfo.Data, data, nil, true)
 	// tokenBalance.History = append(tokenBalance.History, toHistory)
 
+	g.notify(func(o Observer) {
+		o.OnModuleWithdraw(data.Height, moduleInfo.ID, withdrawInfo.Tick, string(withdrawInfo.Data.PkScript), balanceWithdraw)
+		o.OnBalanceChanged(data.Height, receiverPkScript, tokenBalance)
+	})
+
 	////////////////////////////////////////////////////////////////
 	// withdraw to a module, is NOT deposit
 	return nil
@@ -178,12 +187,14 @@ func (g *BRC20ModuleIndexer) ProcessInscribeWithdraw(data *model.InscriptionBRC2
 		return errors.New("module id invalid")
 	}
 
-	moduleInfo, ok := g.ModulesInfoMap[body.Module]
+	moduleInfo, ok := g.moduleOf(body.Module)
 	if !ok { // invalid module
 		return errors.New("module invalid")
 	}
+	g.touchModule(moduleInfo)
+	g.undoModule(moduleInfo)
 
-	if data.Height < conf.ENABLE_SWAP_WITHDRAW_HEIGHT {
+	if data.Height < g.Rules.SwapWithdrawHeight {
 		return errors.New("module withdraw disable")
 	}
 
@@ -226,7 +237,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeWithdraw(data *model.InscriptionBRC2
 	moduleInfo.History = append(moduleInfo.History, history)
 
 	// Check if the module balance is sufficient to withdraw
-	moduleTokenBalance := moduleInfo.GetUserTokenBalance(withdrawInfo.Tick, data.PkScript)
+	moduleTokenBalance := g.getModuleUserTokenBalance(moduleInfo, withdrawInfo.Tick, data.PkScript)
 	{
 
 		moduleTokenBalance.ReadyToWithdrawAmount = moduleTokenBalance.ReadyToWithdrawAmount.Add(balanceWithdraw)
@@ -240,6 +251,7 @@ func (g *BRC20ModuleIndexer) ProcessInscribeWithdraw(data *model.InscriptionBRC2
 
 		moduleTokenBalance.UpdateHeight = data.Height
 		// Update global withdraw lookup table
+		undoMapEntry(g, g.InscriptionsWithdrawMap, data.CreateIdxKey)
 		g.InscriptionsWithdrawMap[data.CreateIdxKey] = withdrawInfo
 		// g.InscriptionsValidBRC20DataMap[data.CreateIdxKey] = withdrawInfo.Data  // fixme
 	}
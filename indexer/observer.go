@@ -0,0 +1,95 @@
+package indexer
+
+import (
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// Observer Receive state changes of the indexer. Callbacks are invoked synchronously
+// after the change is applied, objects passed in are live state and must not be modified.
+// Speculative copies (mempool overlay, commit verify) do not notify observers.
+// Callbacks run under the write lock of the block, calling Query in them deadlocks.
+type Observer interface {
+	OnTokenDeployed(height uint32, tokenInfo *model.BRC20TokenInfo)
+	OnBalanceChanged(height uint32, userPkScript string, tokenBalance *model.BRC20TokenBalance)
+
+	OnTransferCreated(height uint32, transferInfo *model.InscriptionBRC20TickInfo)
+	OnTransferSpent(height uint32, transferInfo *model.InscriptionBRC20TickInfo, receiverPkScript string)
+
+	OnModuleDeposit(height uint32, moduleId, ticker, userPkScript string, amt *decimal.Decimal)
+	OnModuleWithdraw(height uint32, moduleId, ticker, userPkScript string, amt *decimal.Decimal)
+	OnPoolReservesChanged(height uint32, moduleId, poolPair string, pool *model.BRC20ModulePoolTotalBalance)
+	OnCommitApplied(height uint32, moduleId, commitId string)
+
+	// state after height is dropped by reorg
+	OnRollback(height uint32)
+}
+
+// BlockObserver Optional for Observer, notified after all events of a block are applied.
+type BlockObserver interface {
+	OnBlockProcessed(height uint32)
+}
+
+// BaseObserver No-op observer, embed it to implement only the needed callbacks.
+type BaseObserver struct{}
+
+func (BaseObserver) OnTokenDeployed(height uint32, tokenInfo *model.BRC20TokenInfo) {}
+func (BaseObserver) OnBalanceChanged(height uint32, userPkScript string, tokenBalance *model.BRC20TokenBalance) {
+}
+func (BaseObserver) OnTransferCreated(height uint32, transferInfo *model.InscriptionBRC20TickInfo) {}
+func (BaseObserver) OnTransferSpent(height uint32, transferInfo *model.InscriptionBRC20TickInfo, receiverPkScript string) {
+}
+func (BaseObserver) OnModuleDeposit(height uint32, moduleId, ticker, userPkScript string, amt *decimal.Decimal) {
+}
+func (BaseObserver) OnModuleWithdraw(height uint32, moduleId, ticker, userPkScript string, amt *decimal.Decimal) {
+}
+func (BaseObserver) OnPoolReservesChanged(height uint32, moduleId, poolPair string, pool *model.BRC20ModulePoolTotalBalance) {
+}
+func (BaseObserver) OnCommitApplied(height uint32, moduleId, commitId string) {}
+func (BaseObserver) OnRollback(height uint32)                                 {}
+
+// AddObserver Register an observer, called in order of registration.
+func (g *BRC20ModuleIndexer) AddObserver(o Observer) {
+	g.observers = append(g.observers, o)
+}
+
+// RemoveObserver Unregister an observer.
+func (g *BRC20ModuleIndexer) RemoveObserver(o Observer) {
+	for idx, v := range g.observers {
+		if v == o {
+			g.observers = append(g.observers[:idx], g.observers[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (g *BRC20ModuleIndexer) notify(f func(o Observer)) {
+	for _, o := range g.observers {
+		f(o)
+	}
+}
+
+// finishBlock Block of height is done, before readers see it. Observers are not notified if
+// the block failed to save.
+func (g *BRC20ModuleIndexer) finishBlock(height uint32) error {
+	g.updateStateRoot()
+	g.updateBalanceCheckpoints(height)
+	g.updateHolderCounts(height)
+	if err := g.flushStorage(); err != nil {
+		return err
+	}
+	g.notifyBlockProcessed(height)
+	return nil
+}
+
+func (g *BRC20ModuleIndexer) notifyBlockProcessed(height uint32) {
+	if height == constant.MEMPOOL_HEIGHT {
+		return
+	}
+	for _, o := range g.observers {
+		if bo, ok := o.(BlockObserver); ok {
+			bo.OnBlockProcessed(height)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package indexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+type testObserver struct {
+	BaseObserver
+	events []string
+}
+
+func (o *testObserver) OnTokenDeployed(height uint32, tokenInfo *model.BRC20TokenInfo) {
+	o.events = append(o.events, fmt.Sprintf("%d deploy %s", height, tokenInfo.Ticker))
+}
+
+func (o *testObserver) OnBalanceChanged(height uint32, userPkScript string, tokenBalance *model.BRC20TokenBalance) {
+	o.events = append(o.events, fmt.Sprintf("%d balance %x %s %s %s",
+		height, userPkScript, tokenBalance.Ticker, tokenBalance.AvailableBalance, tokenBalance.TransferableBalance))
+}
+
+func (o *testObserver) OnTransferCreated(height uint32, transferInfo *model.InscriptionBRC20TickInfo) {
+	o.events = append(o.events, fmt.Sprintf("%d transfer created %s", height, transferInfo.Amount))
+}
+
+func (o *testObserver) OnTransferSpent(height uint32, transferInfo *model.InscriptionBRC20TickInfo, receiverPkScript string) {
+	o.events = append(o.events, fmt.Sprintf("%d transfer spent %s %x", height, transferInfo.Amount, receiverPkScript))
+}
+
+func (o *testObserver) OnRollback(height uint32) {
+	o.events = append(o.events, fmt.Sprintf("%d rollback", height))
+}
+
+func TestObserver(t *testing.T) {
+	g := newTestIndexer()
+	o := &testObserver{}
+	g.AddObserver(o)
+	processTestBlocks(g, testBlocks()[:4])
+	if err := g.RollbackToHeight(102); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+
+	expected := []string{
+		"100 deploy ordi",
+		fmt.Sprintf("101 balance %x ordi 100 0", testUserA),
+		fmt.Sprintf("101 balance %x ordi 100 0", testUserB),
+		"102 transfer created 60",
+		fmt.Sprintf("102 balance %x ordi 40 60", testUserA),
+		fmt.Sprintf("102 balance %x ordi 90 60", testUserA),
+		fmt.Sprintf("103 transfer spent 60 %x", testUserB),
+		fmt.Sprintf("103 balance %x ordi 90 0", testUserA),
+		fmt.Sprintf("103 balance %x ordi 160 0", testUserB),
+		"102 rollback",
+	}
+	if len(o.events) != len(expected) {
+		t.Fatalf("unexpected events: %q", o.events)
+	}
+	for idx, event := range expected {
+		if o.events[idx] != event {
+			t.Errorf("event %d: expected %q, got %q", idx, event, o.events[idx])
+		}
+	}
+
+	// overlay has no observer
+	g.BeginMempoolOverlay()
+	if len(g.MempoolOverlay.observers) != 0 {
+		t.Error("overlay should not notify observers")
+	}
+	g.RemoveObserver(o)
+	if len(g.observers) != 0 {
+		t.Error("observer not removed")
+	}
+}
+
+type testBlockObserver struct {
+	BaseObserver
+	heights []uint32
+}
+
+func (o *testBlockObserver) OnBlockProcessed(height uint32) {
+	o.heights = append(o.heights, height)
+}
+
+func TestBlockObserver(t *testing.T) {
+	g := newTestIndexer()
+	o := &testBlockObserver{}
+	g.AddObserver(o)
+	processTestBlocks(g, testBlocks()[:4])
+	if fmt.Sprint(o.heights) != "[100 101 102 103]" {
+		t.Fatalf("unexpected blocks: %v", o.heights)
+	}
+}
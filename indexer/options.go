@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/historylog"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
+)
+
+// Options Config of an indexer instance. Zero Rules and NetParams are of the network of the other,
+// or mainnet. Empty TicksEnabled enables all ticks, nil ResultsExternal verifies no commit results.
+type Options struct {
+	Rules           *conf.ChainRules
+	NetParams       *chaincfg.Params
+	TicksEnabled    string
+	Debug           bool // log failed mints
+	ResultsExternal []*model.SwapFunctionResultCheckState
+	Handlers        *HandlerRegistry
+	Storage         storage.KV      // state and history are kept in it instead of memory
+	HistoryLog      *historylog.Log // history is kept in it instead of memory or storage
+
+	StorageCacheSize int // entries of each kind kept in memory with storage, 0 default
+
+	DisableHistory           bool
+	EnableStateRoot          bool
+	EnableBalanceCheckpoints bool // module balances by height, see ModuleBalanceAt
+	EnableOutcomes           bool // outcomes by inscription, see GetInscriptionOutcomes
+	UndoDepth                int  // 0 default depth, -1 disable
+}
+
+// New Indexer with config of its own, so instances of other networks or rules can run side by side.
+func New(opts Options) *BRC20ModuleIndexer {
+	g := &BRC20ModuleIndexer{
+		Rules:           opts.Rules,
+		NetParams:       opts.NetParams,
+		TicksEnabled:    opts.TicksEnabled,
+		Debug:           opts.Debug,
+		ResultsExternal: opts.ResultsExternal,
+		Handlers:        opts.Handlers,
+		Storage:         opts.Storage,
+		HistoryLog:      opts.HistoryLog,
+
+		StorageCacheSize: opts.StorageCacheSize,
+	}
+	g.Init()
+
+	g.EnableHistory = !opts.DisableHistory
+	g.EnableStateRoot = opts.EnableStateRoot
+	g.EnableBalanceCheckpoints = opts.EnableBalanceCheckpoints
+	g.EnableOutcomes = opts.EnableOutcomes
+	switch {
+	case opts.UndoDepth > 0:
+		g.UndoDepth = opts.UndoDepth
+	case opts.UndoDepth == 0:
+		g.UndoDepth = constant.DEFAULT_UNDO_DEPTH
+	}
+	return g
+}
+
+// OptionsForNetwork Options with rules and params of the network.
+func OptionsForNetwork(network string) (opts Options, err error) {
+	if opts.Rules, err = conf.ChainRulesByNetwork(network); err != nil {
+		return opts, err
+	}
+	if opts.NetParams, err = conf.NetParamsByNetwork(network); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,111 @@
+package indexer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+)
+
+func TestIndependentInstances(t *testing.T) {
+	mainnet := New(Options{})
+	shadow := New(Options{NetParams: &chaincfg.TestNet3Params, TicksEnabled: "sats"})
+	processTestBlocks(mainnet, testBlocks())
+	processTestBlocks(shadow, testBlocks())
+
+	if _, ok := mainnet.InscriptionsTickerInfoMap["ordi"]; !ok {
+		t.Fatal("ordi not deployed on mainnet instance")
+	}
+	if _, ok := shadow.InscriptionsTickerInfoMap["ordi"]; ok {
+		t.Fatal("ordi deployed though not enabled on shadow instance")
+	}
+	if _, ok := shadow.InscriptionsTickerInfoMap["sats"]; !ok {
+		t.Fatal("sats not deployed on shadow instance")
+	}
+
+	// copies keep config of the instance
+	copyDup := shadow.DeepCopy()
+	if copyDup.NetParams != &chaincfg.TestNet3Params || copyDup.TicksEnabled != "sats" || copyDup.Rules != shadow.Rules {
+		t.Fatal("config not copied")
+	}
+	if mainnet.NetParams != &chaincfg.MainNetParams || mainnet.TicksEnabled != "" {
+		t.Fatal("config of mainnet instance changed")
+	}
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	g := New(Options{NetParams: &chaincfg.RegressionNetParams})
+	if g.TicksEnabled != "" || g.Rules.Network != "regtest" {
+		t.Fatalf("defaults not of network: %q %s", g.TicksEnabled, g.Rules.Network)
+	}
+	rules, _ := conf.ChainRulesByNetwork("signet")
+	g = New(Options{Rules: rules})
+	if g.NetParams != &chaincfg.SigNetParams {
+		t.Fatalf("params not of rules network: %s", g.NetParams.Name)
+	}
+	g = New(Options{})
+	if g.NetParams != &chaincfg.MainNetParams || g.Rules.Network != "mainnet" {
+		t.Fatal("defaults not of mainnet")
+	}
+}
+
+func TestOptionsForNetwork(t *testing.T) {
+	prefixes := map[string]string{
+		"mainnet":  "bc1q",
+		"testnet":  "tb1q",
+		"testnet4": "tb1q",
+		"signet":   "tb1q",
+		"regtest":  "bcrt1q",
+	}
+	pkScript := append([]byte{0x00, 0x14}, bytes.Repeat([]byte{0x42}, 20)...)
+	for _, network := range conf.Networks {
+		opts, err := OptionsForNetwork(network)
+		if err != nil {
+			t.Fatalf("options of %s failed: %s", network, err)
+		}
+		if opts.Rules.Network != network {
+			t.Fatalf("rules of %s is %s", network, opts.Rules.Network)
+		}
+		address, err := utils.GetAddressFromScript(pkScript, opts.NetParams)
+		if err != nil || !strings.HasPrefix(address, prefixes[network]) {
+			t.Fatalf("address of %s: %s %v", network, address, err)
+		}
+		pk, err := utils.GetPkScriptByAddress(address, opts.NetParams)
+		if err != nil || !bytes.Equal(pk, pkScript) {
+			t.Fatalf("pkScript of %s address %s: %x %v", network, address, pk, err)
+		}
+	}
+	if _, err := OptionsForNetwork("mars"); err == nil {
+		t.Fatal("options of unknown network")
+	}
+
+	// module addresses on regtest
+	opts, _ := OptionsForNetwork("regtest")
+	opts.Rules.ModuleSwapSourceInscriptionId = "source"
+	g := New(opts)
+	sequencer, _ := utils.GetAddressFromScript(pkScript, opts.NetParams)
+	blocks := testBlocks()[:1]
+	blocks = append(blocks, []*model.InscriptionBRC20Data{{
+		TxId:     testTxId("module"),
+		Satoshi:  546,
+		PkScript: testUserA,
+		ContentBody: []byte(fmt.Sprintf(`{"p":"brc20-module","op":"deploy","name":"swap","source":"source",`+
+			`"init":{"gas_tick":"ordi","sequencer":"%s","gas_to":"%s","fee_to":"%s"}}`, sequencer, sequencer, sequencer)),
+		CreateIdxKey: (&model.NFTCreateIdxKey{Height: 101}).String(),
+		Height:       101,
+	}})
+	processTestBlocks(g, blocks)
+
+	moduleInfo, ok := g.ModulesInfoMap[blocks[1][0].GetInscriptionId()]
+	if !ok {
+		t.Fatal("module not created on regtest")
+	}
+	if moduleInfo.SequencerPkScript != string(pkScript) || moduleInfo.GasToPkScript != string(pkScript) {
+		t.Fatal("module addresses not decoded")
+	}
+}
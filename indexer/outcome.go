@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// RejectError Inscription rejected by protocol rules, with a machine-readable reason code.
+type RejectError struct {
+	Reason string
+	Msg    string
+
+	// quiet reject is not logged by the process loop, for common cases
+	Quiet bool
+}
+
+func (e *RejectError) Error() string {
+	return e.Msg
+}
+
+func newRejectError(reason, msg string) *RejectError {
+	return &RejectError{Reason: reason, Msg: msg}
+}
+
+func newQuietRejectError(reason, msg string) *RejectError {
+	return &RejectError{Reason: reason, Msg: msg, Quiet: true}
+}
+
+// isQuietReject Whether the error need no log.
+func isQuietReject(err error) bool {
+	var rejectErr *RejectError
+	return errors.As(err, &rejectErr) && rejectErr.Quiet
+}
+
+// moveOutcomeError Moving an inscription which is invalid on inscribe is rejected too.
+func moveOutcomeError(err error, isInvalid bool) error {
+	if err == nil && isInvalid {
+		return newQuietRejectError(constant.BRC20_REJECT_INSCRIPTION_INVALID, "move, inscription invalid")
+	}
+	return err
+}
+
+// recordOutcome Count the outcome of an inscription event by ticker, and save it by inscription id
+// if EnableOutcomes is set, as outcomes of all inscriptions grow without bound.
+func (g *BRC20ModuleIndexer) recordOutcome(data *model.InscriptionBRC20Data, inscriptionId, proto, op, tick string, err error) {
+	outcome := &model.BRC20InscriptionOutcome{
+		Height:     data.Height,
+		IsTransfer: data.IsTransfer,
+		Proto:      proto,
+		Operation:  op,
+		Tick:       tick,
+		Valid:      err == nil,
+		Reason:     constant.BRC20_OUTCOME_VALID,
+	}
+	if err != nil {
+		outcome.Message = err.Error()
+		var rejectErr *RejectError
+		if errors.As(err, &rejectErr) {
+			outcome.Reason = rejectErr.Reason
+		} else {
+			outcome.Reason = constant.BRC20_REJECT_MODULE
+		}
+	}
+
+	if g.EnableOutcomes {
+		outcomes := g.outcomesOf(inscriptionId)
+		undoMapEntry(g, g.InscriptionOutcomesMap, inscriptionId)
+		g.InscriptionOutcomesMap[inscriptionId] = append(outcomes, outcome)
+		g.markStorageDirty(STORAGE_PREFIX_OUTCOME, inscriptionId)
+	}
+
+	uniqueLowerTicker := strings.ToLower(tick)
+	tickCount, ok := g.TickOutcomeCountMap[uniqueLowerTicker]
+	if !ok {
+		tickCount = make(map[string]uint32, 0)
+		undoMapEntry(g, g.TickOutcomeCountMap, uniqueLowerTicker)
+		g.TickOutcomeCountMap[uniqueLowerTicker] = tickCount
+	}
+	undoMapEntry(g, tickCount, outcome.Reason)
+	tickCount[outcome.Reason] += 1
+	g.markStorageDirty(STORAGE_PREFIX_OUTCOME_COUNT, uniqueLowerTicker)
+}
+
+// GetInscriptionOutcomes Outcomes of inscribe and moves of the inscription, in order. None if
+// EnableOutcomes is not set.
+func (g *BRC20ModuleIndexer) GetInscriptionOutcomes(inscriptionId string) []*model.BRC20InscriptionOutcome {
+	outcomes, _ := peekEntry(g, STORAGE_PREFIX_OUTCOME, g.InscriptionOutcomesMap, inscriptionId, decodeGob[[]*model.BRC20InscriptionOutcome])
+	return outcomes
+}
+
+// GetTickOutcomeCount Count of inscription events of ticker by reason code.
+func (g *BRC20ModuleIndexer) GetTickOutcomeCount(ticker string) map[string]uint32 {
+	return g.TickOutcomeCountMap[strings.ToLower(ticker)]
+}
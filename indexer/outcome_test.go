@@ -0,0 +1,86 @@
+package indexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+func TestInscriptionOutcome(t *testing.T) {
+	contents := []struct {
+		content string
+		reason  string
+	}{
+		{`{"p":"brc-20","op":"deploy","tick":"ordi","max":"1000","lim":"100"}`, constant.BRC20_OUTCOME_VALID},
+		{`{"p":"brc-20","op":"deploy","tick":"ORDI","max":"1000","lim":"100"}`, constant.BRC20_REJECT_DUP_DEPLOY},
+		{`{"p":"brc-20","op":"deploy","tick":"toolong","max":"1000"}`, constant.BRC20_REJECT_TICK_LENGTH},
+		{`{"p":"brc-20","op":"mint","tick":"ordi","amt":"101"}`, constant.BRC20_REJECT_OVER_LIMIT},
+		{`{"p":"brc-20","op":"mint","tick":"none","amt":"100"}`, constant.BRC20_REJECT_TICK_NOT_EXIST},
+		{`{"p":"brc-20","op":"mint","tick":"ordi","amt":"100"}`, constant.BRC20_OUTCOME_VALID},
+		{`{"p":"brc-20","op":"transfer","tick":"ordi","amt":"200"}`, constant.BRC20_REJECT_INSUFFICIENT_BALANCE},
+		{`{"p":"brc-20","op":"burn","tick":"ordi","amt":"200000"}`, constant.BRC20_REJECT_OP_INVALID},
+	}
+
+	g := newTestIndexer()
+	g.EnableOutcomes = true
+	brc20Datas := make(chan interface{}, len(contents))
+	for idx, c := range contents {
+		key := &model.NFTCreateIdxKey{Height: 100, IdxInBlock: uint64(idx)}
+		brc20Datas <- &model.InscriptionBRC20Data{
+			TxId:         testTxId(fmt.Sprintf("tx-%d", idx)),
+			Satoshi:      546,
+			PkScript:     testUserA,
+			ContentBody:  []byte(c.content),
+			CreateIdxKey: key.String(),
+			Height:       100,
+			TxIdx:        uint32(idx),
+		}
+	}
+	close(brc20Datas)
+	g.ProcessUpdateLatestBRC20Loop(brc20Datas, nil)
+
+	for idx, c := range contents {
+		data := &model.InscriptionBRC20Data{TxId: testTxId(fmt.Sprintf("tx-%d", idx))}
+		outcomes := g.GetInscriptionOutcomes(data.GetInscriptionId())
+		if len(outcomes) != 1 {
+			t.Fatalf("inscription %d: expected 1 outcome, got %d", idx, len(outcomes))
+		}
+		if outcomes[0].Reason != c.reason {
+			t.Errorf("inscription %d: expected reason %s, got %s (%s)", idx, c.reason, outcomes[0].Reason, outcomes[0].Message)
+		}
+	}
+
+	counts := g.GetTickOutcomeCount("ORDI")
+	if counts[constant.BRC20_OUTCOME_VALID] != 2 || counts[constant.BRC20_REJECT_DUP_DEPLOY] != 1 {
+		t.Errorf("unexpected outcome count: %v", counts)
+	}
+}
+
+func TestInscriptionOutcomeDisabled(t *testing.T) {
+	g := newTestIndexer()
+	brc20Datas := make(chan interface{}, 1)
+	brc20Datas <- &model.InscriptionBRC20Data{
+		TxId:         testTxId("none"),
+		Satoshi:      546,
+		PkScript:     testUserA,
+		ContentBody:  []byte(`{"p":"brc-20","op":"mint","tick":"none","amt":"100"}`),
+		CreateIdxKey: (&model.NFTCreateIdxKey{Height: 100}).String(),
+		Height:       100,
+	}
+	close(brc20Datas)
+	g.ProcessUpdateLatestBRC20Loop(brc20Datas, nil)
+
+	// rejected without change of state
+	if g.Durty {
+		t.Error("state marked durty by a reject")
+	}
+	data := &model.InscriptionBRC20Data{TxId: testTxId("none")}
+	if outcomes := g.GetInscriptionOutcomes(data.GetInscriptionId()); len(outcomes) != 0 {
+		t.Errorf("outcomes kept when disabled: %v", outcomes)
+	}
+	if counts := g.GetTickOutcomeCount("none"); counts[constant.BRC20_REJECT_TICK_NOT_EXIST] != 1 {
+		t.Errorf("unexpected outcome count: %v", counts)
+	}
+}
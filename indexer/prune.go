@@ -0,0 +1,457 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// archive file, same container as snapshot
+const (
+	HISTORY_ARCHIVE_MAGIC   = "BRC20ARC"
+	HISTORY_ARCHIVE_VERSION = 1
+)
+
+// HistoryArchive History dropped by PruneHistory, for AttachHistoryArchive.
+type HistoryArchive struct {
+	CutoffHeight uint32 // history before the height
+	From         uint64 // index of the first history
+	To           uint64 // index after the last history
+
+	History              [][]byte
+	FirstHistoryByHeight map[uint32]uint64
+	AllHistory           []uint64
+
+	// index lists pruned, same order as historyListsOfToken/historyListsOfBalance
+	UserHistory    map[string][]uint64              // [address]
+	TokenHistory   map[string][][]uint64            // [ticker]
+	BalanceHistory map[string]map[string][][]uint64 // [address][ticker]
+
+	ModuleHistory        map[string][]*model.BRC20ModuleHistory                       // [module]
+	ModuleBalanceHistory map[string]map[string]map[string][]*model.BRC20ModuleHistory // [module][address][ticker]
+	PoolHistory          map[string]map[string][]*model.BRC20ModuleHistory            // [module][pool]
+}
+
+func historyListsOfToken(info *model.BRC20TokenInfo) []*[]uint64 {
+	return []*[]uint64{&info.History, &info.HistoryMint, &info.HistoryInscribeTransfer, &info.HistoryTransfer, &info.HistoryWithdraw}
+}
+
+func historyListsOfBalance(balance *model.BRC20TokenBalance) []*[]uint64 {
+	return []*[]uint64{&balance.History, &balance.HistoryMint, &balance.HistoryInscribeTransfer, &balance.HistorySend, &balance.HistoryReceive}
+}
+
+// prunedHistoryList Index before start of list of increasing index.
+func prunedHistoryList(list []uint64, start uint64) []uint64 {
+	n := sort.Search(len(list), func(i int) bool {
+		return list[i] >= start
+	})
+	if n == 0 {
+		return nil
+	}
+	return list[:n:n]
+}
+
+func prunedHistoryLists(lists []*[]uint64, start uint64) (pruned [][]uint64, ok bool) {
+	pruned = make([][]uint64, len(lists))
+	for i, list := range lists {
+		if pruned[i] = prunedHistoryList(*list, start); pruned[i] != nil {
+			ok = true
+		}
+	}
+	return pruned, ok
+}
+
+// prunedModuleHistory History before height of module history in order of height.
+func prunedModuleHistory(list []*model.BRC20ModuleHistory, height uint32) []*model.BRC20ModuleHistory {
+	n := sort.Search(len(list), func(i int) bool {
+		return list[i].Height >= height
+	})
+	if n == 0 {
+		return nil
+	}
+	return list[:n:n]
+}
+
+// dropFront Remove n items at front, reallocated to release memory.
+func dropFront[V any](list *[]V, n int) {
+	*list = append([]V{}, (*list)[n:]...)
+}
+
+// PruneHistory Drop history before cutoffHeight, with the index lists of users and tokens, and
+// history of modules. If archive is set, history dropped is saved into the file first, see
+// AttachHistoryArchive. Blocks before pruning can not be rolled back.
+func (g *BRC20ModuleIndexer) PruneHistory(cutoffHeight uint32, archive string) error {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	start, err := g.firstHistoryAt(cutoffHeight)
+	if err != nil {
+		return err
+	}
+	if start < g.HistoryStart {
+		start = g.HistoryStart
+	}
+
+	ar := &HistoryArchive{
+		CutoffHeight: cutoffHeight,
+		From:         g.HistoryStart,
+		To:           start,
+
+		FirstHistoryByHeight: make(map[uint32]uint64, 0),
+		UserHistory:          make(map[string][]uint64, 0),
+		TokenHistory:         make(map[string][][]uint64, 0),
+		BalanceHistory:       make(map[string]map[string][][]uint64, 0),
+		ModuleHistory:        make(map[string][]*model.BRC20ModuleHistory, 0),
+		ModuleBalanceHistory: make(map[string]map[string]map[string][]*model.BRC20ModuleHistory, 0),
+		PoolHistory:          make(map[string]map[string][]*model.BRC20ModuleHistory, 0),
+	}
+	if archive != "" {
+		for idx := ar.From; idx < ar.To; idx++ {
+			data, ok := g.GetHistoryData(idx)
+			if !ok {
+				return fmt.Errorf("history %d missing", idx)
+			}
+			ar.History = append(ar.History, data)
+		}
+	}
+
+	if err := g.archiveHistoryState(ar); err != nil {
+		return fmt.Errorf("load state from storage: %w", err)
+	}
+	if archive != "" {
+		registerSnapshotTypes()
+		header := &SnapshotHeader{
+			Version: HISTORY_ARCHIVE_VERSION,
+			Height:  cutoffHeight,
+			Network: g.Rules.Network,
+		}
+		if err := writeContainer(archive, HISTORY_ARCHIVE_MAGIC, header, ar); err != nil {
+			return fmt.Errorf("save history archive: %w", err)
+		}
+	}
+	g.pruneHistoryState(ar)
+
+	// history data
+	n := ar.To - ar.From
+	if n > uint64(len(g.historyArchived)) {
+		n = uint64(len(g.historyArchived))
+	}
+	g.historyArchived = g.historyArchived[n:]
+	if store := g.primaryHistory(); store != nil && g.history == store && ar.From < g.historyOffset {
+		to := ar.To
+		if to > g.historyOffset {
+			to = g.historyOffset
+		}
+		if err := store.pruneHistory(ar.From, to); err != nil {
+			log.Printf("prune history of storage failed: %s", err)
+		}
+	}
+	if ar.To > g.historyOffset {
+		n := ar.To - g.historyOffset
+		if n > uint64(len(g.HistoryData)) {
+			n = uint64(len(g.HistoryData))
+		}
+		g.HistoryData = append([][]byte{}, g.HistoryData[n:]...)
+		g.historyOffset += n
+	}
+	g.HistoryStart = ar.To
+	g.pruneBalanceCheckpoints(cutoffHeight)
+
+	g.dropUndoJournals()
+	if err := g.flushStorage(); err != nil {
+		return err
+	}
+	g.Durty = true
+	log.Printf("prune history before height %d ok, history from %d", cutoffHeight, g.HistoryStart)
+	return nil
+}
+
+// archiveHistoryState Set index lists and module history before the range of archive into it.
+func (g *BRC20ModuleIndexer) archiveHistoryState(ar *HistoryArchive) error {
+	firstHistory, err := g.allFirstHistory()
+	if err != nil {
+		return err
+	}
+	for height, idx := range firstHistory {
+		if height < ar.CutoffHeight {
+			ar.FirstHistoryByHeight[height] = idx
+		}
+	}
+	beforeTo := func(idx uint64) bool { return idx < ar.To }
+	if ar.AllHistory, err = prunedStoredList(g.allHistoryList(), beforeTo); err != nil {
+		return err
+	}
+
+	err = g.RangeUserHistory(func(pkScript string, userHistory *model.BRC20UserHistory) bool {
+		if list := prunedHistoryList(userHistory.History, ar.To); list != nil {
+			ar.UserHistory[pkScript] = list
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for ticker, info := range g.InscriptionsTickerInfoMap {
+		lists := g.tokenHistoryLists(ticker, info)
+		pruned := make([][]uint64, len(lists))
+		ok := false
+		for i, list := range lists {
+			if pruned[i], err = prunedStoredList(list, beforeTo); err != nil {
+				return err
+			}
+			ok = ok || pruned[i] != nil
+		}
+		if ok {
+			ar.TokenHistory[ticker] = pruned
+		}
+	}
+	err = g.RangeUserBalances(func(pkScript string, userTokens map[string]*model.BRC20TokenBalance) bool {
+		for ticker, balance := range userTokens {
+			lists, ok := prunedHistoryLists(historyListsOfBalance(balance), ar.To)
+			if !ok {
+				continue
+			}
+			if _, exist := ar.BalanceHistory[pkScript]; !exist {
+				ar.BalanceHistory[pkScript] = make(map[string][][]uint64, 0)
+			}
+			ar.BalanceHistory[pkScript][ticker] = lists
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	var historyErr error
+	err = g.RangeModules(func(moduleId string, moduleInfo *model.BRC20ModuleSwapInfo) bool {
+		list, err := prunedStoredList(g.moduleHistoryList(moduleInfo), func(h *model.BRC20ModuleHistory) bool {
+			return h.Height < ar.CutoffHeight
+		})
+		if err != nil {
+			historyErr = err
+			return false
+		}
+		if list != nil {
+			ar.ModuleHistory[moduleId] = list
+		}
+		for pkScript, userTokens := range moduleInfo.UsersTokenBalanceDataMap {
+			for ticker, balance := range userTokens {
+				list := prunedModuleHistory(balance.History, ar.CutoffHeight)
+				if list == nil {
+					continue
+				}
+				if _, exist := ar.ModuleBalanceHistory[moduleId]; !exist {
+					ar.ModuleBalanceHistory[moduleId] = make(map[string]map[string][]*model.BRC20ModuleHistory, 0)
+				}
+				if _, exist := ar.ModuleBalanceHistory[moduleId][pkScript]; !exist {
+					ar.ModuleBalanceHistory[moduleId][pkScript] = make(map[string][]*model.BRC20ModuleHistory, 0)
+				}
+				ar.ModuleBalanceHistory[moduleId][pkScript][ticker] = list
+			}
+		}
+		for pair, pool := range moduleInfo.SwapPoolTotalBalanceDataMap {
+			if list := prunedModuleHistory(pool.History, ar.CutoffHeight); list != nil {
+				if _, exist := ar.PoolHistory[moduleId]; !exist {
+					ar.PoolHistory[moduleId] = make(map[string][]*model.BRC20ModuleHistory, 0)
+				}
+				ar.PoolHistory[moduleId][pair] = list
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return historyErr
+}
+
+// pruneHistoryState Drop index lists and module history set into archive.
+func (g *BRC20ModuleIndexer) pruneHistoryState(ar *HistoryArchive) {
+	for height := range ar.FirstHistoryByHeight {
+		delete(g.FirstHistoryByHeight, height)
+		g.deleteStorage(firstHistoryKey(height), false)
+	}
+	dropStoredFront(g, STORAGE_PREFIX_ALL_HISTORY, &g.allHistoryStored, &g.AllHistory, len(ar.AllHistory))
+
+	for pkScript, list := range ar.UserHistory {
+		userHistory, _ := g.userHistoryOf(pkScript)
+		dropFront(&userHistory.History, len(list))
+		g.markStorageDirty(STORAGE_PREFIX_USER_HIST, pkScript)
+	}
+	for ticker, archived := range ar.TokenHistory {
+		info := g.InscriptionsTickerInfoMap[ticker]
+		for i, list := range historyListsOfToken(info) {
+			dropStoredFront(g, tokenListPrefix(ticker, i), &info.HistoryStored[i], list, len(archived[i]))
+		}
+		g.markStorageDirty(STORAGE_PREFIX_TICK, ticker)
+	}
+	for pkScript, archivedTokens := range ar.BalanceHistory {
+		userTokens, _ := g.userTokensOf(pkScript)
+		g.markStorageDirty(STORAGE_PREFIX_BALANCE, pkScript)
+		for ticker, archived := range archivedTokens {
+			balance := userTokens[ticker]
+			for i, list := range historyListsOfBalance(balance) {
+				dropFront(list, len(archived[i]))
+			}
+			balance.HistoryPruned += uint64(len(archived[0]))
+		}
+	}
+
+	for moduleId, list := range ar.ModuleHistory {
+		moduleInfo, _ := g.moduleOf(moduleId)
+		dropStoredFront(g, listPrefix(STORAGE_PREFIX_MODULE_HISTORY, moduleId), &moduleInfo.HistoryStored, &moduleInfo.History, len(list))
+		g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleId)
+	}
+	for moduleId, users := range ar.ModuleBalanceHistory {
+		moduleInfo, _ := g.moduleOf(moduleId)
+		g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleId)
+		for pkScript, userTokens := range users {
+			for ticker, list := range userTokens {
+				dropFront(&moduleInfo.UsersTokenBalanceDataMap[pkScript][ticker].History, len(list))
+			}
+		}
+	}
+	for moduleId, pools := range ar.PoolHistory {
+		moduleInfo, _ := g.moduleOf(moduleId)
+		g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleId)
+		for pair, list := range pools {
+			dropFront(&moduleInfo.SwapPoolTotalBalanceDataMap[pair].History, len(list))
+		}
+	}
+}
+
+// ReadHistoryArchive Archive saved by PruneHistory, verified by checksum and network.
+func (g *BRC20ModuleIndexer) ReadHistoryArchive(fname string) (*HistoryArchive, error) {
+	registerSnapshotTypes()
+	header, content, err := readContainer(fname, HISTORY_ARCHIVE_MAGIC, g.Rules.Network)
+	if err != nil {
+		return nil, err
+	}
+	if header.Version != HISTORY_ARCHIVE_VERSION {
+		return nil, fmt.Errorf("history archive version %d, expected %d", header.Version, HISTORY_ARCHIVE_VERSION)
+	}
+	ar := &HistoryArchive{}
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(ar); err != nil {
+		return nil, fmt.Errorf("%w: decode: %s", ErrSnapshotCorrupt, err)
+	}
+	if ar.To < ar.From || uint64(len(ar.History)) != ar.To-ar.From {
+		return nil, fmt.Errorf("%w: history count mismatch", ErrSnapshotCorrupt)
+	}
+	return ar, nil
+}
+
+// AttachHistoryArchive Restore history pruned into the archive. Archives are attached in the
+// reverse order of pruning, the last one first. History attached is kept in memory, lists of
+// history attached are written into storage again.
+func (g *BRC20ModuleIndexer) AttachHistoryArchive(fname string) error {
+	ar, err := g.ReadHistoryArchive(fname)
+	if err != nil {
+		return err
+	}
+
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	if ar.To != g.HistoryStart {
+		return fmt.Errorf("archive of history from %d to %d, but history from %d", ar.From, ar.To, g.HistoryStart)
+	}
+
+	for height, idx := range ar.FirstHistoryByHeight {
+		g.FirstHistoryByHeight[height] = idx
+	}
+	if err := attachStoredFront(g, g.allHistoryList(), ar.AllHistory, &g.allHistoryStored, &g.AllHistory); err != nil {
+		return err
+	}
+
+	for pkScript, list := range ar.UserHistory {
+		userHistory, ok := g.userHistoryOf(pkScript)
+		if !ok {
+			userHistory = &model.BRC20UserHistory{}
+			g.UserAllHistory[pkScript] = userHistory
+		}
+		userHistory.History = append(list, userHistory.History...)
+		g.markStorageDirty(STORAGE_PREFIX_USER_HIST, pkScript)
+	}
+	for ticker, archived := range ar.TokenHistory {
+		if info, ok := g.InscriptionsTickerInfoMap[ticker]; ok {
+			tails := historyListsOfToken(info)
+			for i, list := range g.tokenHistoryLists(ticker, info) {
+				if i < len(archived) {
+					if err := attachStoredFront(g, list, archived[i], &info.HistoryStored[i], tails[i]); err != nil {
+						return err
+					}
+				}
+			}
+			g.markStorageDirty(STORAGE_PREFIX_TICK, ticker)
+		}
+	}
+	for pkScript, archivedTokens := range ar.BalanceHistory {
+		userTokens, _ := g.userTokensOf(pkScript)
+		for ticker, archived := range archivedTokens {
+			// holder removed after pruning
+			if balance, ok := userTokens[ticker]; ok {
+				attachHistoryLists(historyListsOfBalance(balance), archived)
+				balance.HistoryPruned -= uint64(len(archived[0]))
+				g.markStorageDirty(STORAGE_PREFIX_BALANCE, pkScript)
+			}
+		}
+	}
+
+	for moduleId, list := range ar.ModuleHistory {
+		if moduleInfo, ok := g.moduleOf(moduleId); ok {
+			if err := attachStoredFront(g, g.moduleHistoryList(moduleInfo), list, &moduleInfo.HistoryStored, &moduleInfo.History); err != nil {
+				return err
+			}
+			g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleId)
+		}
+	}
+	for moduleId, users := range ar.ModuleBalanceHistory {
+		moduleInfo, ok := g.moduleOf(moduleId)
+		if !ok {
+			continue
+		}
+		g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleId)
+		for pkScript, userTokens := range users {
+			for ticker, list := range userTokens {
+				if balance, ok := moduleInfo.UsersTokenBalanceDataMap[pkScript][ticker]; ok {
+					balance.History = append(list, balance.History...)
+				}
+			}
+		}
+	}
+	for moduleId, pools := range ar.PoolHistory {
+		moduleInfo, ok := g.moduleOf(moduleId)
+		if !ok {
+			continue
+		}
+		g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleId)
+		for pair, list := range pools {
+			if pool, ok := moduleInfo.SwapPoolTotalBalanceDataMap[pair]; ok {
+				pool.History = append(list, pool.History...)
+			}
+		}
+	}
+
+	g.historyArchived = append(ar.History, g.historyArchived...)
+	g.HistoryStart = ar.From
+
+	g.dropUndoJournals()
+	if err := g.flushStorage(); err != nil {
+		return err
+	}
+	g.Durty = true
+	log.Printf("attach history archive ok, history from %d", g.HistoryStart)
+	return nil
+}
+
+func attachHistoryLists(lists []*[]uint64, archived [][]uint64) {
+	for i, list := range lists {
+		if i < len(archived) {
+			*list = append(archived[i], *list...)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneHistory(t *testing.T) {
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	fullHistory := full.HistoryData
+
+	g := newTestIndexer()
+	processTestBlocks(g, testBlocks())
+	archive := filepath.Join(t.TempDir(), "history-103.archive")
+	if err := g.PruneHistory(103, archive); err != nil {
+		t.Fatalf("prune failed: %s", err)
+	}
+
+	start := full.FirstHistoryByHeight[103]
+	if g.HistoryStart != start || len(g.HistoryData) != int(g.HistoryCount-start) {
+		t.Fatalf("history from %d, %d in memory, expected from %d", g.HistoryStart, len(g.HistoryData), start)
+	}
+	if _, ok := g.GetHistoryData(start - 1); ok {
+		t.Fatal("history pruned found")
+	}
+	if data, ok := g.GetHistoryData(start); !ok || string(data) != string(fullHistory[start]) {
+		t.Fatal("history kept not found")
+	}
+	for h := range g.FirstHistoryByHeight {
+		if h < 103 {
+			t.Fatalf("first history of height %d not pruned", h)
+		}
+	}
+	for pk, userHistory := range g.UserAllHistory {
+		for _, idx := range userHistory.History {
+			if idx < start {
+				t.Fatalf("history %d of user %x not pruned", idx, pk)
+			}
+		}
+	}
+	for _, idx := range g.InscriptionsTickerInfoMap["ordi"].History {
+		if idx < start {
+			t.Fatalf("history %d of token not pruned", idx)
+		}
+	}
+	if _, ok := g.UserTokensBalanceData[testUserA]["ordi"]; !ok {
+		t.Fatal("balance pruned")
+	}
+	if err := g.RollbackToHeight(104); err == nil {
+		t.Fatal("rollback before pruning")
+	}
+
+	// snapshot of pruned state
+	fname := filepath.Join(t.TempDir(), "snapshot")
+	if err := g.SaveSnapshot(fname); err != nil {
+		t.Fatalf("save snapshot failed: %s", err)
+	}
+	loaded := newTestIndexer()
+	if err := loaded.LoadSnapshot(fname); err != nil {
+		t.Fatalf("load snapshot failed: %s", err)
+	}
+	if got, want := storedDigest(loaded), storedDigest(g); got != want {
+		t.Fatalf("pruned state of snapshot differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// attach archive again
+	if err := loaded.AttachHistoryArchive(archive); err != nil {
+		t.Fatalf("attach archive failed: %s", err)
+	}
+	if err := loaded.AttachHistoryArchive(archive); err == nil {
+		t.Fatal("archive attached twice")
+	}
+	if got, want := digestWithoutHistoryData(t, loaded, full), storedDigest(full); got != want {
+		t.Fatalf("state with archive differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package indexer
+
+import (
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// Query Read-only access to confirmed state, safe for many goroutines while blocks are processed.
+// Results are copies taken at a block boundary.
+type Query struct {
+	g *BRC20ModuleIndexer
+}
+
+func (g *BRC20ModuleIndexer) Query() *Query {
+	return &Query{g: g}
+}
+
+// View Run fn with the state locked for reading, for several reads of the same block.
+// fn must not keep or modify objects of the state.
+func (q *Query) View(fn func(g *BRC20ModuleIndexer)) {
+	q.g.rw.RLock()
+	defer q.g.rw.RUnlock()
+	fn(q.g)
+}
+
+// BestHeight Height of the last block applied.
+func (q *Query) BestHeight() (height uint32) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		height = g.BestHeight
+	})
+	return height
+}
+
+// GetBalance Balance of user in token.
+func (q *Query) GetBalance(ticker, userPkScript string) (tokenBalance *model.BRC20TokenBalance, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		var balance *model.BRC20TokenBalance
+		if balance, ok = g.UserBalance(ticker, userPkScript); ok {
+			tokenBalance = balance.DeepCopy()
+		}
+	})
+	return tokenBalance, ok
+}
+
+// GetBalances All token balances of user, by lower ticker.
+func (q *Query) GetBalances(userPkScript string) (tokenBalances map[string]*model.BRC20TokenBalance) {
+	tokenBalances = make(map[string]*model.BRC20TokenBalance, 0)
+	q.View(func(g *BRC20ModuleIndexer) {
+		for ticker, balance := range g.UserBalances(userPkScript) {
+			tokenBalances[ticker] = balance.DeepCopy()
+		}
+	})
+	return tokenBalances
+}
+
+// GetTokenInfo Deploy info and history of token.
+func (q *Query) GetTokenInfo(ticker string) (tokenInfo *model.BRC20TokenInfo, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		var info *model.BRC20TokenInfo
+		if info, ok = g.InscriptionsTickerInfoMap[strings.ToLower(ticker)]; ok {
+			tokenInfo = info.DeepCopy()
+		}
+	})
+	return tokenInfo, ok
+}
+
+// GetUserHistory Index of history of user.
+func (q *Query) GetUserHistory(userPkScript string) (history []uint64) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		if userHistory, ok := g.UserHistory(userPkScript); ok {
+			history = make([]uint64, len(userHistory.History))
+			copy(history, userHistory.History)
+		}
+	})
+	return history
+}
+
+// GetHistory Raw history data of index, see model.BRC20History.Unmarshal.
+func (q *Query) GetHistory(idx uint64) (data []byte, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		data, ok = g.GetHistoryData(idx)
+	})
+	return data, ok
+}
+
+// GetModuleUserBalance Balance of user in module.
+func (q *Query) GetModuleUserBalance(moduleId, ticker, userPkScript string) (tokenBalance *model.BRC20ModuleTokenBalance, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		moduleInfo, exist := g.Module(moduleId)
+		if !exist {
+			return
+		}
+		var balance *model.BRC20ModuleTokenBalance
+		if userTokens, exist := moduleInfo.UsersTokenBalanceDataMap[userPkScript]; exist {
+			balance, ok = userTokens[strings.ToLower(ticker)]
+		}
+		if ok {
+			tokenBalance = balance.DeepCopy()
+		}
+	})
+	return tokenBalance, ok
+}
+
+// GetPool Total balance of pool in module.
+func (q *Query) GetPool(moduleId, poolPair string) (pool *model.BRC20ModulePoolTotalBalance, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		moduleInfo, exist := g.Module(moduleId)
+		if !exist {
+			return
+		}
+		var poolBalance *model.BRC20ModulePoolTotalBalance
+		if poolBalance, ok = moduleInfo.SwapPoolTotalBalanceDataMap[poolPair]; ok {
+			pool = poolBalance.DeepCopy()
+			pool.TickBalance[0] = decimal.NewDecimalCopy(poolBalance.TickBalance[0])
+			pool.TickBalance[1] = decimal.NewDecimalCopy(poolBalance.TickBalance[1])
+		}
+	})
+	return pool, ok
+}
+
+// GetUserLpBalance Lp balance of user in pool of module.
+func (q *Query) GetUserLpBalance(moduleId, poolPair, userPkScript string) (balance *decimal.Decimal, ok bool) {
+	q.View(func(g *BRC20ModuleIndexer) {
+		moduleInfo, exist := g.Module(moduleId)
+		if !exist {
+			return
+		}
+		var lp *decimal.Decimal
+		if userLps, exist := moduleInfo.UsersLPTokenBalanceMap[userPkScript]; exist {
+			lp, ok = userLps[poolPair]
+		}
+		if ok {
+			balance = decimal.NewDecimalCopy(lp)
+		}
+	})
+	return balance, ok
+}
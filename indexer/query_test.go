@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/loader"
+)
+
+// balanceDigest Non-zero balances and best height.
+func balanceDigest(g *BRC20ModuleIndexer) string {
+	lines := []string{fmt.Sprintf("best %d", g.BestHeight)}
+	for pkScript, userTokens := range g.UserTokensBalanceData {
+		for ticker, balance := range userTokens {
+			if balance.OverallBalance().Sign() == 0 {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%x %s %s %s", pkScript, ticker,
+				balance.AvailableBalance, balance.TransferableBalance))
+		}
+	}
+	sort.Strings(lines[1:])
+	return strings.Join(lines, "\n")
+}
+
+func TestQueryDuringProcess(t *testing.T) {
+	// balances after each block
+	expected := map[uint32]string{0: balanceDigest(newTestIndexer())}
+	for n := 1; n <= len(testBlocks()); n++ {
+		replay := newTestIndexer()
+		processTestBlocks(replay, testBlocks()[:n])
+		expected[replay.BestHeight] = balanceDigest(replay)
+	}
+
+	g := newTestIndexer()
+	q := g.Query()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				q.View(func(g *BRC20ModuleIndexer) {
+					if got, want := balanceDigest(g), expected[g.BestHeight]; got != want {
+						t.Errorf("half-applied block seen\ngot:\n%s\nwant:\n%s", got, want)
+					}
+				})
+				q.GetBalance("ordi", testUserA)
+				q.GetTokenInfo("ordi")
+			}
+		}()
+	}
+
+	processTestBlocks(g, testBlocks()[:1])
+	for i := 0; i < 100; i++ {
+		if err := g.ProcessSource(context.Background(), loader.NewDataSource(testDatas()[1:])); err != nil {
+			t.Fatalf("process source failed: %s", err)
+		}
+		if i < 99 {
+			if err := g.RollbackToHeight(100); err != nil {
+				t.Fatalf("rollback failed: %s", err)
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	balance, ok := q.GetBalance("ORDI", testUserA)
+	if !ok || balance.AvailableBalance.String() != "190" {
+		t.Fatalf("unexpected balance: %v", balance)
+	}
+	balance.AvailableBalance = nil
+	if again, _ := q.GetBalance("ordi", testUserA); again.AvailableBalance == nil {
+		t.Fatal("query returned live state")
+	}
+	if info, ok := q.GetTokenInfo("ordi"); !ok || info.Deploy.TotalMinted.String() != "250" {
+		t.Fatalf("unexpected token info: %v", info)
+	}
+}
@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+)
+
+func TestChainRules(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(fname, []byte(`{"network":"regtest","self_mint_height":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	regtest, err := conf.LoadChainRules(fname)
+	if err != nil {
+		t.Fatalf("load rules failed: %s", err)
+	}
+	if regtest.Network != "regtest" || regtest.SelfMintHeight != 1 || regtest.SwapWithdrawHeight != 0 {
+		t.Fatalf("unexpected rules: %+v", regtest)
+	}
+	if _, err := conf.ChainRulesByNetwork("mars"); err == nil {
+		t.Fatal("rules of unknown network")
+	}
+
+	// self mint is active at block 1 on regtest only
+	for _, c := range []struct {
+		rules  *conf.ChainRules
+		reason string
+	}{
+		{&conf.MainnetRules, constant.BRC20_REJECT_SELF_MINT_DISABLED},
+		{regtest, constant.BRC20_OUTCOME_VALID},
+	} {
+		g := &BRC20ModuleIndexer{Rules: c.rules, EnableOutcomes: true}
+		g.Init()
+		data := &model.InscriptionBRC20Data{
+			TxId:         testTxId("self-mint"),
+			Satoshi:      546,
+			PkScript:     testUserA,
+			ContentBody:  []byte(`{"p":"brc-20","op":"deploy","tick":"abcde","max":"1000","self_mint":"true"}`),
+			CreateIdxKey: (&model.NFTCreateIdxKey{Height: 1}).String(),
+			Height:       1,
+		}
+		processTestBlocks(g, [][]*model.InscriptionBRC20Data{{data}})
+
+		outcomes := g.GetInscriptionOutcomes(data.GetInscriptionId())
+		if len(outcomes) != 1 || outcomes[0].Reason != c.reason {
+			t.Fatalf("%s: unexpected outcomes %v", c.rules.Network, outcomes)
+		}
+	}
+}
+
+func TestModuleSourceUnset(t *testing.T) {
+	opts, _ := OptionsForNetwork("regtest")
+	g := New(opts)
+	// gas tick deployed
+	processTestBlocks(g, testBlocks()[:1])
+	sequencer, _ := utils.GetAddressFromScript(append([]byte{0x00, 0x14}, bytes.Repeat([]byte{0x42}, 20)...), opts.NetParams)
+	data := &model.InscriptionBRC20Data{
+		TxId:     testTxId("module"),
+		Satoshi:  546,
+		PkScript: testUserA,
+		ContentBody: []byte(fmt.Sprintf(`{"p":"brc20-module","op":"deploy","name":"swap","source":"",`+
+			`"init":{"gas_tick":"ordi","sequencer":"%s","gas_to":"%s","fee_to":"%s"}}`, sequencer, sequencer, sequencer)),
+		CreateIdxKey: (&model.NFTCreateIdxKey{Height: 101}).String(),
+		Height:       101,
+	}
+	// empty source of inscription matches no source in rules
+	if err := g.ProcessCreateModule(data); err == nil {
+		t.Fatal("module created without source in rules")
+	}
+	if _, ok := g.Module(data.GetInscriptionId()); ok {
+		t.Fatal("module kept without source in rules")
+	}
+}
@@ -0,0 +1,350 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// snapshot file: header of SNAPSHOT_HEADER_SIZE bytes, then content of gob snapshotContent.
+// header: magic[8] version[4] height[4] size[8] sha256[32] network[32], integers big endian.
+const (
+	SNAPSHOT_MAGIC       = "BRC20SNP"
+	SNAPSHOT_VERSION     = 2
+	SNAPSHOT_HEADER_SIZE = 8 + 4 + 4 + 8 + 32 + 32
+)
+
+var ErrSnapshotCorrupt = errors.New("snapshot corrupt")
+
+// SnapshotHeader Description of snapshot content.
+type SnapshotHeader struct {
+	Version uint32
+	Height  uint32
+	Size    uint64
+	Hash    [32]byte
+	Network string
+}
+
+// snapshotContent State and history in one snapshot.
+type snapshotContent struct {
+	Store   *BRC20ModuleIndexerStore
+	History [][]byte
+}
+
+// SnapshotMigration Convert content of a version to the next version.
+type SnapshotMigration func(content []byte) ([]byte, error)
+
+var snapshotMigrations = map[uint32]SnapshotMigration{}
+
+// RegisterSnapshotMigration Set the step from version to version+1.
+func RegisterSnapshotMigration(version uint32, migration SnapshotMigration) {
+	snapshotMigrations[version] = migration
+}
+
+func init() {
+	RegisterSnapshotMigration(1, migrateSnapshotWideHistory)
+}
+
+// migrateSnapshotWideHistory Version 2 has history indexes of uint64, gob decodes the uint32
+// of version 1 into them.
+func migrateSnapshotWideHistory(content []byte) ([]byte, error) {
+	registerSnapshotTypes()
+	var snapshot snapshotContent
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&snapshot); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *SnapshotHeader) marshal(magic string) []byte {
+	buf := make([]byte, SNAPSHOT_HEADER_SIZE)
+	copy(buf, magic)
+	binary.BigEndian.PutUint32(buf[8:], h.Version)
+	binary.BigEndian.PutUint32(buf[12:], h.Height)
+	binary.BigEndian.PutUint64(buf[16:], h.Size)
+	copy(buf[24:56], h.Hash[:])
+	copy(buf[56:], h.Network)
+	return buf
+}
+
+func (h *SnapshotHeader) unmarshal(magic string, buf []byte) error {
+	if len(buf) != SNAPSHOT_HEADER_SIZE || string(buf[:8]) != magic {
+		return fmt.Errorf("%w: invalid header", ErrSnapshotCorrupt)
+	}
+	h.Version = binary.BigEndian.Uint32(buf[8:])
+	h.Height = binary.BigEndian.Uint32(buf[12:])
+	h.Size = binary.BigEndian.Uint64(buf[16:])
+	copy(h.Hash[:], buf[24:56])
+	h.Network = string(bytes.TrimRight(buf[56:], "\x00"))
+	return nil
+}
+
+func registerSnapshotTypes() {
+	gob.Register(model.BRC20SwapHistoryApproveData{})
+	gob.Register(model.BRC20SwapHistoryCondApproveData{})
+	gob.Register(model.BRC20SwapHistoryWithdrawData{})
+}
+
+// writeContainer Write header of magic and gob of content into file, the file is replaced only
+// if completely written. Size and hash of header are set by content.
+func writeContainer(fname, magic string, header *SnapshotHeader, content any) error {
+	if len(header.Network) > 32 {
+		return fmt.Errorf("network name too long: %s", header.Network)
+	}
+
+	tmpName := fname + ".tmp"
+	file, err := os.OpenFile(tmpName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName)
+	defer file.Close()
+
+	if _, err := file.Write(header.marshal(magic)); err != nil {
+		return err
+	}
+
+	// content and its hash
+	hasher := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(file, hasher))
+	counter := &countWriter{w: w}
+	if err := gob.NewEncoder(counter).Encode(content); err != nil {
+		return fmt.Errorf("encode %s: %w", fname, err)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	header.Size = counter.n
+	copy(header.Hash[:], hasher.Sum(nil))
+
+	if _, err := file.WriteAt(header.marshal(magic), 0); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, fname)
+}
+
+// readContainer Header and content of file written by writeContainer, verified by size, hash
+// and network.
+func readContainer(fname, magic, network string) (header *SnapshotHeader, content []byte, err error) {
+	raw, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) < SNAPSHOT_HEADER_SIZE {
+		return nil, nil, fmt.Errorf("%w: invalid header", ErrSnapshotCorrupt)
+	}
+	header = &SnapshotHeader{}
+	if err := header.unmarshal(magic, raw[:SNAPSHOT_HEADER_SIZE]); err != nil {
+		return nil, nil, err
+	}
+
+	content = raw[SNAPSHOT_HEADER_SIZE:]
+	if uint64(len(content)) != header.Size {
+		return nil, nil, fmt.Errorf("%w: size %d, expected %d", ErrSnapshotCorrupt, len(content), header.Size)
+	}
+	if sha256.Sum256(content) != header.Hash {
+		return nil, nil, fmt.Errorf("%w: hash mismatch", ErrSnapshotCorrupt)
+	}
+	if header.Network != network {
+		return nil, nil, fmt.Errorf("%s of network %s, but indexer of %s", fname, header.Network, network)
+	}
+	return header, content, nil
+}
+
+// SaveSnapshot Save state and history into a snapshot file. The file is replaced only
+// if completely written.
+func (g *BRC20ModuleIndexer) SaveSnapshot(fname string) error {
+	log.Printf("saving brc20 snapshot ...")
+	registerSnapshotTypes()
+
+	if g.HistoryLog != nil {
+		if err := g.HistoryLog.Sync(); err != nil {
+			return err
+		}
+	}
+
+	content := &snapshotContent{Store: g.GetStore()}
+	for idx := g.HistoryStart; idx < g.HistoryCount; idx++ {
+		data, ok := g.GetHistoryData(idx)
+		if !ok {
+			return fmt.Errorf("history %d missing", idx)
+		}
+		content.History = append(content.History, data)
+	}
+
+	header := &SnapshotHeader{
+		Version: SNAPSHOT_VERSION,
+		Height:  g.BestHeight,
+		Network: g.Rules.Network,
+	}
+	if err := writeContainer(fname, SNAPSHOT_MAGIC, header, content); err != nil {
+		return err
+	}
+	log.Printf("save brc20 snapshot ok. height: %d, size: %d", header.Height, header.Size)
+	return nil
+}
+
+// ReadSnapshotHeader Header of snapshot file, version 0 for legacy gob without header.
+func ReadSnapshotHeader(fname string) (header *SnapshotHeader, err error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header = &SnapshotHeader{}
+	buf := make([]byte, SNAPSHOT_HEADER_SIZE)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	if n < len(SNAPSHOT_MAGIC) || string(buf[:len(SNAPSHOT_MAGIC)]) != SNAPSHOT_MAGIC {
+		return header, nil
+	}
+	if err := header.unmarshal(SNAPSHOT_MAGIC, buf[:n]); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// LoadSnapshot Load state and history from snapshot file, migrated to the current version.
+// Nothing is changed if the file is corrupt, of other network, or of unknown version.
+// With history log, history is kept in the log, only history not in it is appended.
+func (g *BRC20ModuleIndexer) LoadSnapshot(fname string) error {
+	log.Printf("loading brc20 snapshot ...")
+	registerSnapshotTypes()
+
+	header, err := ReadSnapshotHeader(fname)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	version := header.Version
+	if version == 0 {
+		log.Printf("legacy snapshot without header, no checksum to verify")
+		raw, err := os.ReadFile(fname)
+		if err != nil {
+			return err
+		}
+		if content, err = legacySnapshotContent(fname, raw); err != nil {
+			return err
+		}
+		version = 1
+	} else if _, content, err = readContainer(fname, SNAPSHOT_MAGIC, g.Rules.Network); err != nil {
+		return err
+	}
+
+	if version > SNAPSHOT_VERSION {
+		return fmt.Errorf("snapshot version %d newer than %d", version, SNAPSHOT_VERSION)
+	}
+	for ; version < SNAPSHOT_VERSION; version++ {
+		migration, ok := snapshotMigrations[version]
+		if !ok {
+			return fmt.Errorf("no migration from snapshot version %d", version)
+		}
+		if content, err = migration(content); err != nil {
+			return fmt.Errorf("migrate snapshot version %d: %w", version, err)
+		}
+		log.Printf("migrated snapshot to version %d", version+1)
+	}
+
+	var snapshot snapshotContent
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&snapshot); err != nil {
+		return fmt.Errorf("%w: decode: %s", ErrSnapshotCorrupt, err)
+	}
+	if snapshot.Store == nil || snapshot.Store.HistoryStart > snapshot.Store.HistoryCount ||
+		uint64(len(snapshot.History)) != snapshot.Store.HistoryCount-snapshot.Store.HistoryStart {
+		return fmt.Errorf("%w: history count mismatch", ErrSnapshotCorrupt)
+	}
+	if header.Version != 0 && snapshot.Store.BestHeight != header.Height {
+		return fmt.Errorf("%w: height mismatch", ErrSnapshotCorrupt)
+	}
+
+	if g.HistoryLog != nil {
+		store := snapshot.Store
+		err := g.attachHistoryLog(store.HistoryStart, store.HistoryCount, func(idx uint64) ([]byte, bool) {
+			return snapshot.History[idx-store.HistoryStart], true
+		})
+		if err != nil {
+			return fmt.Errorf("history log: %w", err)
+		}
+	}
+
+	g.rw.Lock()
+	defer g.rw.Unlock()
+	g.LoadStore(snapshot.Store)
+	if g.HistoryLog != nil {
+		g.HistoryData = make([][]byte, 0)
+		g.history = logHistory{g.HistoryLog}
+		g.historyOffset = g.HistoryCount
+	} else {
+		g.HistoryData = snapshot.History
+		g.history = nil
+		g.historyOffset = g.HistoryStart
+	}
+	log.Printf("load brc20 snapshot ok. height: %d", g.BestHeight)
+	return nil
+}
+
+// legacySnapshotContent Content of version 1 from raw gob store written by Save, and
+// history written by SaveHistory into fname.history.
+func legacySnapshotContent(fname string, raw []byte) ([]byte, error) {
+	store := &BRC20ModuleIndexerStore{}
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&store); err != nil {
+		return nil, fmt.Errorf("%w: decode legacy: %s", ErrSnapshotCorrupt, err)
+	}
+
+	content := &snapshotContent{Store: store}
+	if store.HistoryCount > 0 {
+		file, err := os.Open(fname + ".history")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		dec := gob.NewDecoder(bufio.NewReader(file))
+		for idx := uint64(0); idx < store.HistoryCount; idx++ {
+			var h []byte
+			if err := dec.Decode(&h); err != nil {
+				return nil, fmt.Errorf("%w: legacy history %d: %s", ErrSnapshotCorrupt, idx, err)
+			}
+			content.History = append(content.History, h)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(content); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
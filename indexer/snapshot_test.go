@@ -0,0 +1,78 @@
+package indexer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	g := newTestIndexer()
+	processTestBlocks(g, testBlocks())
+	fname := filepath.Join(t.TempDir(), "snapshot")
+	if err := g.SaveSnapshot(fname); err != nil {
+		t.Fatalf("save snapshot failed: %s", err)
+	}
+
+	header, err := ReadSnapshotHeader(fname)
+	if err != nil {
+		t.Fatalf("read header failed: %s", err)
+	}
+	if header.Version != SNAPSHOT_VERSION || header.Height != 105 || header.Network != "mainnet" {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+
+	loaded := newTestIndexer()
+	if err := loaded.LoadSnapshot(fname); err != nil {
+		t.Fatalf("load snapshot failed: %s", err)
+	}
+	if got, want := storedDigest(loaded), storedDigest(g); got != want {
+		t.Fatalf("state of snapshot differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// legacy files of Save and SaveHistory
+	legacy := filepath.Join(t.TempDir(), "legacy")
+	g.Save(legacy)
+	g.SaveHistory(legacy + ".history")
+	loaded = newTestIndexer()
+	if err := loaded.LoadSnapshot(legacy); err != nil {
+		t.Fatalf("load legacy snapshot failed: %s", err)
+	}
+	if got, want := storedDigest(loaded), storedDigest(g); got != want {
+		t.Fatalf("state of legacy snapshot differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	raw, _ := os.ReadFile(fname)
+	broken := filepath.Join(t.TempDir(), "broken")
+
+	// flipped byte, truncated file
+	for _, content := range [][]byte{
+		append(append([]byte{}, raw[:len(raw)-10]...), raw[len(raw)-10]^1),
+		raw[:len(raw)/2],
+		raw[:20],
+	} {
+		os.WriteFile(broken, content, 0644)
+		loaded = newTestIndexer()
+		if err := loaded.LoadSnapshot(broken); !errors.Is(err, ErrSnapshotCorrupt) {
+			t.Fatalf("corrupt snapshot loaded: %v", err)
+		}
+		if loaded.BestHeight != 0 {
+			t.Fatal("state changed by corrupt snapshot")
+		}
+	}
+
+	// other network
+	regtest, _ := OptionsForNetwork("regtest")
+	if err := New(regtest).LoadSnapshot(fname); err == nil {
+		t.Fatal("snapshot of other network loaded")
+	}
+
+	// newer version, needs migration
+	newer := append([]byte{}, raw...)
+	newer[11] = SNAPSHOT_VERSION + 1
+	os.WriteFile(broken, newer, 0644)
+	if err := newTestIndexer().LoadSnapshot(broken); err == nil {
+		t.Fatal("snapshot of newer version loaded")
+	}
+}
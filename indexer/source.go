@@ -0,0 +1,180 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// Source Input of inscription events, in order of height.
+type Source interface {
+	// NextBlock Events of the next block, all with the same height.
+	// Return io.EOF if the input ended cleanly, other errors are passed to caller of ProcessSource.
+	NextBlock(ctx context.Context) ([]*model.InscriptionBRC20Data, error)
+}
+
+// ProcessSource Process blocks from source until end of input. Cancel is checked between
+// blocks, so the state is always at a block boundary when returned. Each block is applied
+// under write lock, readers of Query never see a half-applied block. If storage fails, the
+// error is returned at the event, and the state is to be loaded again from storage.
+func (g *BRC20ModuleIndexer) ProcessSource(ctx context.Context, src Source) (err error) {
+	g.Durty = false
+	defer func() {
+		g.rw.Lock()
+		if finishErr := g.finishProcess(); err == nil {
+			err = finishErr
+		}
+		g.rw.Unlock()
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		block, err := src.NextBlock(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		g.rw.Lock()
+		for _, data := range block {
+			if g.storageErr != nil {
+				break
+			}
+			g.processData(data)
+		}
+		if g.storageErr != nil {
+			g.rw.Unlock()
+			return g.storageErr
+		}
+		if len(block) > 0 && block[0].Height != constant.MEMPOOL_HEIGHT {
+			if err := g.finishBlock(block[0].Height); err != nil {
+				g.rw.Unlock()
+				return err
+			}
+			// whole block applied, resume at the next one. The last block of channel may be cut,
+			// it is resumed after its last event.
+			if cs, ok := src.(*chanSource); !ok || cs.next != nil {
+				g.LastCreateIdxKey = ""
+				g.LastSequence = 0
+			}
+		}
+		g.rw.Unlock()
+	}
+}
+
+// resumeSource Skip events already processed before the snapshot.
+type resumeSource struct {
+	src Source
+
+	height       uint32
+	lastKey      string
+	lastSequence uint16
+	resumed      bool
+}
+
+// ResumeSource Wrap the source to skip events at or below the height of loaded state. If the
+// state stopped in the middle of a block, events after the last processed one are kept.
+func (g *BRC20ModuleIndexer) ResumeSource(src Source) Source {
+	return &resumeSource{
+		src:          src,
+		height:       g.BestHeight,
+		lastKey:      g.LastCreateIdxKey,
+		lastSequence: g.LastSequence,
+		resumed:      g.BestHeight == 0,
+	}
+}
+
+func (s *resumeSource) NextBlock(ctx context.Context) ([]*model.InscriptionBRC20Data, error) {
+	for {
+		block, err := s.src.NextBlock(ctx)
+		if err != nil || s.resumed || len(block) == 0 {
+			return block, err
+		}
+
+		height := block[0].Height
+		if height < s.height {
+			continue
+		}
+		if height > s.height {
+			if s.lastKey != "" {
+				return nil, fmt.Errorf("resume, last event of height %d not found", s.height)
+			}
+			s.resumed = true
+			log.Printf("resume at height %d", height)
+			return block, nil
+		}
+
+		// the block of snapshot height, complete if no last event
+		s.resumed = true
+		if s.lastKey == "" {
+			continue
+		}
+		for idx, data := range block {
+			if data.CreateIdxKey == s.lastKey && data.Sequence == s.lastSequence {
+				if idx+1 == len(block) {
+					break
+				}
+				log.Printf("resume in block %d, skip %d events", height, idx+1)
+				return block[idx+1:], nil
+			}
+			if idx+1 == len(block) {
+				return nil, fmt.Errorf("resume, last event of height %d not found", s.height)
+			}
+		}
+	}
+}
+
+// chanSource Events from channel of ProcessUpdateLatestBRC20Loop, grouped by height. A block is
+// known to end only when an event of the next height arrives or the channel is closed.
+type chanSource struct {
+	in   chan interface{}
+	dump chan interface{}
+
+	next    interface{} // first event of the next block
+	applied []interface{}
+}
+
+func (s *chanSource) NextBlock(ctx context.Context) ([]*model.InscriptionBRC20Data, error) {
+	// block before is applied when the next one is asked
+	s.flushDump()
+
+	var block []*model.InscriptionBRC20Data
+	for {
+		dataIn := s.next
+		s.next = nil
+		if dataIn == nil {
+			var ok bool
+			if dataIn, ok = <-s.in; !ok {
+				if len(block) == 0 {
+					return nil, io.EOF
+				}
+				return block, nil
+			}
+		}
+
+		data := dataIn.(*model.InscriptionBRC20Data)
+		if len(block) > 0 && data.Height != block[0].Height {
+			s.next = dataIn
+			return block, nil
+		}
+		block = append(block, data)
+		s.applied = append(s.applied, dataIn)
+	}
+}
+
+func (s *chanSource) flushDump() {
+	if s.dump != nil {
+		for _, dataIn := range s.applied {
+			s.dump <- dataIn
+		}
+	}
+	s.applied = nil
+}
@@ -0,0 +1,182 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/loader"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// failSource Fails after some blocks.
+type failSource struct {
+	blocks [][]*model.InscriptionBRC20Data
+	err    error
+}
+
+func (s *failSource) NextBlock(ctx context.Context) ([]*model.InscriptionBRC20Data, error) {
+	if len(s.blocks) == 0 {
+		return nil, s.err
+	}
+	block := s.blocks[0]
+	s.blocks = s.blocks[1:]
+	return block, nil
+}
+
+func TestProcessSource(t *testing.T) {
+	datas := testDatas()
+	g := newTestIndexer()
+	if err := g.ProcessSource(context.Background(), loader.NewDataSource(datas)); err != nil {
+		t.Fatalf("process source failed: %s", err)
+	}
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	if got, want := stateDigest(g), stateDigest(full); got != want {
+		t.Fatalf("state of source differs from loop\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// error of source is passed through, state is at block boundary
+	errLoad := errors.New("invalid data format")
+	g = newTestIndexer()
+	err := g.ProcessSource(context.Background(), &failSource{blocks: testBlocks()[:3], err: errLoad})
+	if !errors.Is(err, errLoad) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replay := newTestIndexer()
+	processTestBlocks(replay, testBlocks()[:3])
+	if got, want := stateDigest(g), stateDigest(replay); got != want {
+		t.Fatalf("state after source error differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newTestIndexer().ProcessSource(ctx, loader.NewDataSource(datas)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error on cancel: %v", err)
+	}
+}
+
+func TestResumeSource(t *testing.T) {
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	datas := testDatas()
+
+	// stop after each event, then resume from snapshot
+	for n := 1; n < len(datas); n++ {
+		g := newTestIndexer()
+		processTestBlocks(g, [][]*model.InscriptionBRC20Data{testDatas()[:n]})
+		fname := filepath.Join(t.TempDir(), "snapshot")
+		g.Save(fname)
+		g.SaveHistory(fname + ".history")
+
+		resumed := newTestIndexer()
+		resumed.Load(fname)
+		resumed.LoadHistory(fname + ".history")
+		if err := resumed.ProcessSource(context.Background(), resumed.ResumeSource(loader.NewDataSource(testDatas()))); err != nil {
+			t.Fatalf("resume after %d events failed: %s", n, err)
+		}
+		if got, want := storedDigest(resumed), storedDigest(full); got != want {
+			t.Fatalf("state resumed after %d events differs\ngot:\n%s\nwant:\n%s", n, got, want)
+		}
+	}
+}
+
+func testDatas() (datas []*model.InscriptionBRC20Data) {
+	for _, block := range testBlocks() {
+		datas = append(datas, block...)
+	}
+	return datas
+}
+
+// storedDigest Digest of state without the remove maps, which are not in snapshot.
+func storedDigest(g *BRC20ModuleIndexer) string {
+	var lines []string
+	for _, line := range strings.Split(stateDigest(g), "\n") {
+		if !strings.HasPrefix(line, "remove ") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestResumeSourceAfterBlock(t *testing.T) {
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+
+	// snapshot after blocks 100..102, input starts at 103
+	g := newTestIndexer()
+	if err := g.ProcessSource(context.Background(), loader.NewDataSource(testDatas()[:5])); err != nil {
+		t.Fatalf("process source failed: %s", err)
+	}
+	fname := filepath.Join(t.TempDir(), "snapshot")
+	g.Save(fname)
+	g.SaveHistory(fname + ".history")
+
+	resumed := newTestIndexer()
+	resumed.Load(fname)
+	resumed.LoadHistory(fname + ".history")
+	var rest []*model.InscriptionBRC20Data
+	for _, block := range testBlocks()[3:] {
+		rest = append(rest, block...)
+	}
+	if err := resumed.ProcessSource(context.Background(), resumed.ResumeSource(loader.NewDataSource(rest))); err != nil {
+		t.Fatalf("resume at next block failed: %s", err)
+	}
+	if got, want := storedDigest(resumed), storedDigest(full); got != want {
+		t.Fatalf("state resumed at next block differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// digestObserver State digests seen when each block is done.
+type digestObserver struct {
+	BaseObserver
+	g       *BRC20ModuleIndexer
+	digests map[string]bool
+}
+
+func (o *digestObserver) OnBlockProcessed(height uint32) {
+	o.digests[stateDigest(o.g)] = true
+}
+
+func TestLoopWholeBlocks(t *testing.T) {
+	g := newTestIndexer()
+	o := &digestObserver{g: g, digests: map[string]bool{stateDigest(g): true}}
+	g.AddObserver(o)
+
+	brc20Datas := make(chan interface{})
+	brc20DatasDump := make(chan interface{}, 64)
+	done := make(chan struct{})
+	go func() {
+		g.ProcessUpdateLatestBRC20Loop(brc20Datas, brc20DatasDump)
+		close(done)
+	}()
+
+	// readers only see state at block boundary
+	q := g.Query()
+	for _, data := range testDatas() {
+		brc20Datas <- data
+		q.View(func(g *BRC20ModuleIndexer) {
+			if !o.digests[stateDigest(g)] {
+				t.Errorf("half-applied block seen at height %d", g.BestHeight)
+			}
+		})
+	}
+	close(brc20Datas)
+	<-done
+
+	// all events dumped in order
+	close(brc20DatasDump)
+	datas := testDatas()
+	n := 0
+	for dataIn := range brc20DatasDump {
+		if dataIn.(*model.InscriptionBRC20Data).CreateIdxKey != datas[n].CreateIdxKey {
+			t.Fatalf("dump event %d out of order", n)
+		}
+		n++
+	}
+	if n != len(datas) {
+		t.Fatalf("dumped %d events, want %d", n, len(datas))
+	}
+}
@@ -0,0 +1,306 @@
+package indexer
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/stateroot"
+)
+
+type stateBalanceKey struct {
+	pkScript string
+	ticker   string
+}
+
+// stateRootTracker Leaves of the current state, and the state changed since last root.
+type stateRootTracker struct {
+	tree *stateroot.Tree
+
+	dirtyTicks    map[string]struct{}
+	dirtyBalances map[stateBalanceKey]struct{}
+	dirtyModules  map[string]struct{}
+
+	moduleLeaves map[string]map[string]struct{} // [module]leafKeys
+	pending      bool                           // state processed since last root
+}
+
+func newStateRootTracker() *stateRootTracker {
+	return &stateRootTracker{
+		tree:          stateroot.NewTree(),
+		dirtyTicks:    make(map[string]struct{}, 0),
+		dirtyBalances: make(map[stateBalanceKey]struct{}, 0),
+		dirtyModules:  make(map[string]struct{}, 0),
+		moduleLeaves:  make(map[string]map[string]struct{}, 0),
+	}
+}
+
+func (g *BRC20ModuleIndexer) markStateTickDirty(ticker string) {
+	if g.stateRoot == nil || g.stateRoot.tree == nil {
+		return
+	}
+	g.stateRoot.dirtyTicks[strings.ToLower(ticker)] = struct{}{}
+}
+
+func (g *BRC20ModuleIndexer) markStateBalanceDirty(pkScript, ticker string) {
+	if g.stateRoot == nil || g.stateRoot.tree == nil {
+		return
+	}
+	g.stateRoot.dirtyBalances[stateBalanceKey{pkScript, strings.ToLower(ticker)}] = struct{}{}
+}
+
+func (g *BRC20ModuleIndexer) markStateModuleDirty(moduleId string) {
+	if g.stateRoot == nil || g.stateRoot.tree == nil {
+		return
+	}
+	g.stateRoot.dirtyModules[moduleId] = struct{}{}
+}
+
+// markStatePending A new state event is being processed, root is saved when its block is done.
+func (g *BRC20ModuleIndexer) markStatePending() {
+	if !g.EnableStateRoot {
+		return
+	}
+	if g.stateRoot == nil {
+		// build from full state on next root
+		g.stateRoot = &stateRootTracker{}
+	}
+	g.stateRoot.pending = true
+}
+
+// updateStateRoot Apply state changed to the tree, and save root of current height.
+func (g *BRC20ModuleIndexer) updateStateRoot() {
+	if !g.EnableStateRoot || g.stateRoot == nil || !g.stateRoot.pending {
+		return
+	}
+	if g.BestHeight == constant.MEMPOOL_HEIGHT {
+		return
+	}
+
+	if g.stateRoot.tree == nil {
+		g.buildStateTree()
+	} else {
+		for ticker := range g.stateRoot.dirtyTicks {
+			g.updateStateTickLeaf(ticker)
+		}
+		for key := range g.stateRoot.dirtyBalances {
+			g.updateStateBalanceLeaf(key)
+		}
+		for moduleId := range g.stateRoot.dirtyModules {
+			g.updateStateModuleLeaves(moduleId)
+		}
+	}
+	g.stateRoot.dirtyTicks = make(map[string]struct{}, 0)
+	g.stateRoot.dirtyBalances = make(map[stateBalanceKey]struct{}, 0)
+	g.stateRoot.dirtyModules = make(map[string]struct{}, 0)
+	g.stateRoot.pending = false
+
+	root := g.stateRoot.tree.Root()
+	if _, ok := g.StateRootsByHeight[g.BestHeight]; !ok {
+		idx := sort.Search(len(g.stateRootHeights), func(i int) bool { return g.stateRootHeights[i] > g.BestHeight })
+		g.stateRootHeights = append(g.stateRootHeights, 0)
+		copy(g.stateRootHeights[idx+1:], g.stateRootHeights[idx:])
+		g.stateRootHeights[idx] = g.BestHeight
+	}
+	g.StateRootsByHeight[g.BestHeight] = root
+	g.markStorageDirty(STORAGE_PREFIX_STATE_ROOT, stateRootKey(g.BestHeight))
+}
+
+// buildStateTree Hash all leaves of the state.
+func (g *BRC20ModuleIndexer) buildStateTree() {
+	log.Printf("build state tree. height: %d", g.BestHeight)
+	g.stateRoot = newStateRootTracker()
+	for ticker := range g.InscriptionsTickerInfoMap {
+		g.updateStateTickLeaf(ticker)
+	}
+	err := g.RangeUserBalances(func(pkScript string, userTokens map[string]*model.BRC20TokenBalance) bool {
+		for ticker := range userTokens {
+			g.updateStateBalanceLeaf(stateBalanceKey{pkScript, ticker})
+		}
+		return true
+	})
+	if err != nil {
+		log.Panicf("load balances from storage failed: %s", err)
+	}
+	for _, moduleId := range g.ModuleIds() {
+		g.updateStateModuleLeaves(moduleId)
+	}
+	log.Printf("build state tree finish. leaves: %d", g.stateRoot.tree.Len())
+}
+
+func (g *BRC20ModuleIndexer) updateStateTickLeaf(ticker string) {
+	tokenInfo, ok := g.InscriptionsTickerInfoMap[ticker]
+	if !ok {
+		key, _ := stateroot.TickLeaf(ticker, "", "", 0, "")
+		g.stateRoot.tree.Delete(key)
+		return
+	}
+	tinfo := tokenInfo.Deploy
+	key, value := stateroot.TickLeaf(ticker, tinfo.Max.String(), tinfo.Limit.String(), tinfo.Decimal, tinfo.TotalMinted.String())
+	g.stateRoot.tree.Set(key, stateroot.LeafHash(key, value))
+}
+
+func (g *BRC20ModuleIndexer) updateStateBalanceLeaf(balanceKey stateBalanceKey) {
+	var tokenBalance *model.BRC20TokenBalance
+	if userTokens := g.UserBalances(balanceKey.pkScript); userTokens != nil {
+		tokenBalance = userTokens[balanceKey.ticker]
+	}
+	if tokenBalance == nil || (tokenBalance.AvailableBalance.Sign() == 0 && tokenBalance.TransferableBalance.Sign() == 0) {
+		key, _ := stateroot.BalanceLeaf(balanceKey.pkScript, balanceKey.ticker, "", "")
+		g.stateRoot.tree.Delete(key)
+		return
+	}
+	key, value := stateroot.BalanceLeaf(balanceKey.pkScript, balanceKey.ticker,
+		tokenBalance.AvailableBalance.String(), tokenBalance.TransferableBalance.String())
+	g.stateRoot.tree.Set(key, stateroot.LeafHash(key, value))
+}
+
+// updateStateModuleLeaves Rehash all leaves of the module, remove the leaves not exist anymore.
+func (g *BRC20ModuleIndexer) updateStateModuleLeaves(moduleId string) {
+	tree := g.stateRoot.tree
+	leaves := make(map[string]struct{}, 0)
+	set := func(key, value []byte) {
+		leaves[string(key)] = struct{}{}
+		tree.Set(key, stateroot.LeafHash(key, value))
+	}
+
+	if moduleInfo, ok := g.Module(moduleId); ok {
+		for pkScript, userTokens := range moduleInfo.UsersTokenBalanceDataMap {
+			for ticker, balance := range userTokens {
+				if balance.SwapAccountBalance.Sign() == 0 && balance.AvailableBalance.Sign() == 0 &&
+					balance.ApproveableBalance.Sign() == 0 && balance.CondApproveableBalance.Sign() == 0 &&
+					balance.ReadyToWithdrawAmount.Sign() == 0 {
+					continue
+				}
+				set(stateroot.ModuleBalanceLeaf(moduleId, pkScript, ticker,
+					balance.SwapAccountBalance.String(),
+					balance.AvailableBalance.String(),
+					balance.ApproveableBalance.String(),
+					balance.CondApproveableBalance.String(),
+					balance.ReadyToWithdrawAmount.String(),
+				))
+			}
+		}
+
+		for poolPair, pool := range moduleInfo.SwapPoolTotalBalanceDataMap {
+			set(stateroot.PoolLeaf(moduleId, poolPair, pool.Tick[0], pool.Tick[1],
+				pool.TickBalance[0].String(), pool.TickBalance[1].String(), pool.LpBalance.String()))
+		}
+
+		for poolPair, usersLp := range moduleInfo.LPTokenUsersBalanceMap {
+			for pkScript, lp := range usersLp {
+				if lp.Sign() == 0 {
+					continue
+				}
+				set(stateroot.LpLeaf(moduleId, poolPair, pkScript, lp.String()))
+			}
+		}
+	}
+
+	for key := range g.stateRoot.moduleLeaves[moduleId] {
+		if _, ok := leaves[key]; !ok {
+			tree.Delete([]byte(key))
+		}
+	}
+	g.stateRoot.moduleLeaves[moduleId] = leaves
+}
+
+// resetStateRoot Drop roots after height, and rebuild the tree on next root.
+func (g *BRC20ModuleIndexer) resetStateRoot(height uint32) {
+	idx := sort.Search(len(g.stateRootHeights), func(i int) bool { return g.stateRootHeights[i] > height })
+	for _, h := range g.stateRootHeights[idx:] {
+		delete(g.StateRootsByHeight, h)
+		g.markStorageDirty(STORAGE_PREFIX_STATE_ROOT, stateRootKey(h))
+	}
+	g.stateRootHeights = g.stateRootHeights[:idx]
+	g.stateRoot = nil
+}
+
+// loadStateRoots Restore roots saved in store.
+func (g *BRC20ModuleIndexer) loadStateRoots(roots map[uint32]stateroot.Hash) {
+	g.StateRootsByHeight = make(map[uint32]stateroot.Hash, len(roots))
+	g.stateRootHeights = make([]uint32, 0, len(roots))
+	for h, root := range roots {
+		g.StateRootsByHeight[h] = root
+		g.stateRootHeights = append(g.stateRootHeights, h)
+	}
+	sort.Slice(g.stateRootHeights, func(i, j int) bool { return g.stateRootHeights[i] < g.stateRootHeights[j] })
+	g.stateRoot = nil
+}
+
+// StateRootAtHeight Root of the state after block h. Blocks without state change share the root of last changed block.
+func (g *BRC20ModuleIndexer) StateRootAtHeight(h uint32) (root stateroot.Hash, ok bool) {
+	if h > g.BestHeight {
+		return root, false
+	}
+	idx := sort.Search(len(g.stateRootHeights), func(i int) bool { return g.stateRootHeights[i] > h })
+	if idx == 0 {
+		return root, false
+	}
+	return g.StateRootsByHeight[g.stateRootHeights[idx-1]], true
+}
+
+// stateTreeForProof Tree of current state, rebuilt if dropped. The caller holds the write lock.
+func (g *BRC20ModuleIndexer) stateTreeForProof() (*stateroot.Tree, error) {
+	if !g.EnableStateRoot {
+		return nil, errors.New("state root not enabled")
+	}
+	if g.stateRoot != nil && g.stateRoot.pending {
+		return nil, errors.New("state root of block not finished")
+	}
+	if g.stateRoot == nil || g.stateRoot.tree == nil {
+		g.buildStateTree()
+	}
+	return g.stateRoot.tree, nil
+}
+
+// GetBalanceProof Inclusion proof of user's brc20 balance in state root of best height. Not locked,
+// the tree may be built, see Query.GetBalanceProof.
+func (g *BRC20ModuleIndexer) GetBalanceProof(ticker, userPkScript string) (proof *stateroot.Proof, root stateroot.Hash, err error) {
+	tree, err := g.stateTreeForProof()
+	if err != nil {
+		return nil, root, err
+	}
+	key, _ := stateroot.BalanceLeaf(userPkScript, ticker, "", "")
+	proof, ok := tree.Prove(key)
+	if !ok {
+		return nil, root, errors.New("balance not exist")
+	}
+	proof.Height = g.BestHeight
+	return proof, tree.Root(), nil
+}
+
+// GetModuleBalanceProof Inclusion proof of user's balance in module in state root of best height.
+// Not locked, the tree may be built, see Query.GetModuleBalanceProof.
+func (g *BRC20ModuleIndexer) GetModuleBalanceProof(moduleId, ticker, userPkScript string) (proof *stateroot.Proof, root stateroot.Hash, err error) {
+	tree, err := g.stateTreeForProof()
+	if err != nil {
+		return nil, root, err
+	}
+	key, _ := stateroot.ModuleBalanceLeaf(moduleId, userPkScript, ticker, "", "", "", "", "")
+	proof, ok := tree.Prove(key)
+	if !ok {
+		return nil, root, errors.New("module balance not exist")
+	}
+	proof.Height = g.BestHeight
+	return proof, tree.Root(), nil
+}
+
+// GetBalanceProof See BRC20ModuleIndexer.GetBalanceProof. Locked for writing, as the tree dropped
+// by load or rollback is built on first use.
+func (q *Query) GetBalanceProof(ticker, userPkScript string) (proof *stateroot.Proof, root stateroot.Hash, err error) {
+	q.g.rw.Lock()
+	defer q.g.rw.Unlock()
+	return q.g.GetBalanceProof(ticker, userPkScript)
+}
+
+// GetModuleBalanceProof See BRC20ModuleIndexer.GetModuleBalanceProof. Locked for writing, as the
+// tree dropped by load or rollback is built on first use.
+func (q *Query) GetModuleBalanceProof(moduleId, ticker, userPkScript string) (proof *stateroot.Proof, root stateroot.Hash, err error) {
+	q.g.rw.Lock()
+	defer q.g.rw.Unlock()
+	return q.g.GetModuleBalanceProof(moduleId, ticker, userPkScript)
+}
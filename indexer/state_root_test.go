@@ -0,0 +1,120 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/stateroot"
+)
+
+func TestStateRootAtHeight(t *testing.T) {
+	g := newTestIndexer()
+	g.EnableStateRoot = true
+	processTestBlocks(g, testBlocks())
+
+	// incremental root is the same as root of a replay to that height
+	for n := 1; n <= len(testBlocks()); n++ {
+		height := uint32(99 + n)
+		root, ok := g.StateRootAtHeight(height)
+		if !ok {
+			t.Fatalf("missing state root at %d", height)
+		}
+
+		replay := newTestIndexer()
+		replay.EnableStateRoot = true
+		processTestBlocks(replay, testBlocks()[:n])
+		replay.buildStateTree()
+		if full := replay.stateRoot.tree.Root(); full != root {
+			t.Fatalf("state root at %d: incremental %s, full %s", height, root, full)
+		}
+	}
+
+	if _, ok := g.StateRootAtHeight(99); ok {
+		t.Fatal("no state root before first block")
+	}
+	if root, _ := g.StateRootAtHeight(1000); !root.IsZero() {
+		t.Fatal("no state root after best height")
+	}
+	root101, _ := g.StateRootAtHeight(101)
+	root102, _ := g.StateRootAtHeight(102)
+	if root101 == root102 {
+		t.Fatal("state root not changed by block")
+	}
+
+	// rollback, then process again
+	full, _ := g.StateRootAtHeight(105)
+	if err := g.RollbackToHeight(102); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	if root, ok := g.StateRootAtHeight(102); !ok || root != root102 {
+		t.Fatal("state root at rollback height changed")
+	}
+	processTestBlocks(g, testBlocks()[3:])
+	if root, _ := g.StateRootAtHeight(105); root != full {
+		t.Fatalf("state root after reprocess %s, expected %s", root, full)
+	}
+}
+
+// rootObserver State roots seen when each block is done.
+type rootObserver struct {
+	BaseObserver
+	g     *BRC20ModuleIndexer
+	roots map[uint32]bool
+}
+
+func (o *rootObserver) OnBlockProcessed(height uint32) {
+	_, o.roots[height] = o.g.StateRootsByHeight[height]
+}
+
+func TestStateRootOnBlockDone(t *testing.T) {
+	g := newTestIndexer()
+	g.EnableStateRoot = true
+	o := &rootObserver{g: g, roots: make(map[uint32]bool, 0)}
+	g.AddObserver(o)
+	processTestBlocks(g, testBlocks())
+
+	// root of a block is saved before the next block arrives
+	for n := 1; n <= len(testBlocks()); n++ {
+		if height := uint32(99 + n); !o.roots[height] {
+			t.Fatalf("state root at %d missing when block done", height)
+		}
+	}
+}
+
+func TestBalanceProof(t *testing.T) {
+	g := newTestIndexer()
+	g.EnableStateRoot = true
+	processTestBlocks(g, testBlocks())
+
+	root, _ := g.StateRootAtHeight(g.BestHeight)
+	for _, pkScript := range []string{testUserA, testUserB} {
+		for _, ticker := range []string{"ordi", "sats"} {
+			balance, ok := g.GetPendingUserTokenBalance(ticker, pkScript)
+			proof, proofRoot, err := g.GetBalanceProof(ticker, pkScript)
+			if !ok || balance.AvailableBalance.Sign() == 0 {
+				if err == nil {
+					t.Fatalf("proof of empty balance %x %s", pkScript, ticker)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("get proof failed: %s", err)
+			}
+			if proofRoot != root || proof.Height != g.BestHeight {
+				t.Fatal("proof not of best height")
+			}
+			if !stateroot.VerifyBalance(root, pkScript, ticker,
+				balance.AvailableBalance.String(), balance.TransferableBalance.String(), proof) {
+				t.Fatalf("proof of %x %s invalid", pkScript, ticker)
+			}
+		}
+	}
+
+	// tree dropped by rollback is built by the query
+	if err := g.RollbackToHeight(103); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	root, _ = g.StateRootAtHeight(103)
+	if _, proofRoot, err := g.Query().GetBalanceProof("ordi", testUserB); err != nil || proofRoot != root {
+		t.Fatalf("proof after rollback: %v", err)
+	}
+}
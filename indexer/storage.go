@@ -0,0 +1,616 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/unisat-wallet/libbrc20-indexer/historylog"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
+)
+
+// key prefixes of state in storage
+const (
+	STORAGE_KEY_META                = "meta"
+	STORAGE_KEY_VERSION             = "version"
+	STORAGE_PREFIX_HISTORY          = "history/"
+	STORAGE_PREFIX_TICK             = "tick/"
+	STORAGE_PREFIX_BALANCE          = "balance/"
+	STORAGE_PREFIX_HOLDER           = "holder/"
+	STORAGE_PREFIX_USER_HIST        = "userhistory/"
+	STORAGE_PREFIX_VALID            = "valid/"
+	STORAGE_PREFIX_TRANSFER         = "transfer/"
+	STORAGE_PREFIX_INVALID_TRANSFER = "invalidtransfer/"
+	STORAGE_PREFIX_OUTCOME          = "outcome/"
+	STORAGE_PREFIX_MODULE           = "module/"
+	STORAGE_PREFIX_BLOCK            = "block/" // version 2 and 3, for migration only
+	STORAGE_PREFIX_STATE_ROOT       = "stateroot/"
+	STORAGE_PREFIX_CHECKPOINT       = "checkpoint/"
+	STORAGE_PREFIX_HOLDER_COUNT     = "holdercount/"
+
+	STORAGE_PREFIX_ALL_HISTORY    = "allhistory/"
+	STORAGE_PREFIX_FIRST_HISTORY  = "firsthistory/"
+	STORAGE_PREFIX_TICK_HISTORY   = "tickhistory/"
+	STORAGE_PREFIX_MODULE_HISTORY = "modulehistory/"
+	STORAGE_PREFIX_OUTCOME_COUNT  = "outcomecount/"
+
+	STORAGE_PREFIX_USER_MODULE          = "usermodule/"
+	STORAGE_PREFIX_USER_LP_MODULE       = "userlpmodule/"
+	STORAGE_PREFIX_APPROVE              = "approve/"
+	STORAGE_PREFIX_INVALID_APPROVE      = "invalidapprove/"
+	STORAGE_PREFIX_COND_APPROVE         = "condapprove/"
+	STORAGE_PREFIX_INVALID_COND_APPROVE = "invalidcondapprove/"
+	STORAGE_PREFIX_COMMIT               = "commit/"
+	STORAGE_PREFIX_INVALID_COMMIT       = "invalidcommit/"
+	STORAGE_PREFIX_COMMIT_BY_ID         = "commitbyid/"
+)
+
+// STORAGE_VERSION Layout of keys in storage, no version key for 1 of all indexes in meta.
+// Version 2 keeps history indexes by block, and holders, roots, checkpoints and counts by key,
+// version 3 has history keys of 8 bytes. Version 4 keeps lists of history by position, and
+// approves, commits, modules of users and outcome counts by key.
+const STORAGE_VERSION = 4
+
+func historyKey(idx uint64) []byte {
+	key := make([]byte, len(STORAGE_PREFIX_HISTORY)+8)
+	copy(key, STORAGE_PREFIX_HISTORY)
+	binary.BigEndian.PutUint64(key[len(STORAGE_PREFIX_HISTORY):], idx)
+	return key
+}
+
+// historyStore Persistent history before historyOffset, shared by copies for reading.
+type historyStore interface {
+	getHistory(idx uint64) ([]byte, error)
+	truncateHistory(from, to uint64) error
+	pruneHistory(from, to uint64) error
+}
+
+// kvHistory History in storage, a key for each.
+type kvHistory struct {
+	kv storage.KV
+}
+
+func (s kvHistory) getHistory(idx uint64) ([]byte, error) {
+	return s.kv.Get(historyKey(idx))
+}
+
+func (s kvHistory) truncateHistory(from, to uint64) error {
+	batch := storage.NewBatch()
+	for idx := from; idx < to; idx++ {
+		batch.Delete(historyKey(idx))
+	}
+	return s.kv.Write(batch)
+}
+
+func (s kvHistory) pruneHistory(from, to uint64) error {
+	batch := storage.NewBatch()
+	for idx := from; idx < to; idx++ {
+		batch.Delete(historyKey(idx))
+	}
+	return s.kv.Write(batch)
+}
+
+// logHistory History in segment files of log.
+type logHistory struct {
+	log *historylog.Log
+}
+
+func (s logHistory) getHistory(idx uint64) ([]byte, error) {
+	return s.log.Get(idx)
+}
+
+func (s logHistory) putHistory(height uint32, idx uint64, data []byte) error {
+	// left by a run before
+	if s.log.Count() > idx {
+		if err := s.log.Truncate(idx); err != nil {
+			return err
+		}
+	}
+	got, err := s.log.Append(height, data)
+	if err != nil {
+		return err
+	}
+	if got != idx {
+		return fmt.Errorf("history log at %d, expected %d", got, idx)
+	}
+	return nil
+}
+
+func (s logHistory) truncateHistory(from, to uint64) error {
+	return s.log.Truncate(from)
+}
+
+// pruneHistory Only whole segments are removed.
+func (s logHistory) pruneHistory(from, to uint64) error {
+	return s.log.PruneBefore(to)
+}
+
+// primaryHistory Store of history of this instance, the log if set, or storage.
+func (g *BRC20ModuleIndexer) primaryHistory() historyStore {
+	if g.HistoryLog != nil {
+		return logHistory{g.HistoryLog}
+	}
+	if g.Storage != nil {
+		return kvHistory{g.Storage}
+	}
+	return nil
+}
+
+// appendHistory Append history to log if set, so history is not kept in memory. History for
+// storage is written with the block, see flushStorage.
+func (g *BRC20ModuleIndexer) appendHistory(height uint32, data []byte) {
+	if store, ok := g.history.(logHistory); ok && store.log == g.HistoryLog && len(g.HistoryData) == 0 && g.historyOffset == g.HistoryCount {
+		err := store.putHistory(height, g.HistoryCount, data)
+		if err == nil {
+			g.historyOffset++
+			return
+		}
+		// kept in memory, processing stops at the event
+		g.failStorage(fmt.Errorf("save history %d: %w", g.HistoryCount, err))
+	}
+	g.HistoryData = append(g.HistoryData, data)
+}
+
+// truncateHistory Drop history from count, by rollback.
+func (g *BRC20ModuleIndexer) truncateHistory(count uint64) {
+	if count < g.historyOffset {
+		if store := g.primaryHistory(); store != nil && g.history == store {
+			if err := store.truncateHistory(count, g.historyOffset); err != nil {
+				log.Panicf("truncate history to %d failed: %s", count, err)
+			}
+		}
+		g.historyOffset = count
+		g.HistoryData = g.HistoryData[:0]
+		return
+	}
+	if len(g.HistoryData) > int(count-g.historyOffset) {
+		g.HistoryData = g.HistoryData[:count-g.historyOffset]
+	}
+}
+
+// GetHistoryData Marshaled history of index, from archive attached, memory, log or storage.
+// History pruned is not found.
+func (g *BRC20ModuleIndexer) GetHistoryData(idx uint64) (data []byte, ok bool) {
+	if idx >= g.HistoryCount || idx < g.HistoryStart {
+		return nil, false
+	}
+	if idx-g.HistoryStart < uint64(len(g.historyArchived)) {
+		return g.historyArchived[idx-g.HistoryStart], true
+	}
+	if idx >= g.historyOffset {
+		return g.HistoryData[idx-g.historyOffset], true
+	}
+	if g.history == nil {
+		return nil, false
+	}
+	data, err := g.history.getHistory(idx)
+	if err != nil {
+		log.Printf("load history %d failed: %s", idx, err)
+		return nil, false
+	}
+	return data, true
+}
+
+// attachHistoryLog Keep history from start to count in log, history not in log yet is appended
+// from history. The log is rewritten if it is of other history.
+func (g *BRC20ModuleIndexer) attachHistoryLog(start, count uint64, history func(idx uint64) ([]byte, bool)) error {
+	logCount := g.HistoryLog.Count()
+	if logCount > count {
+		logCount = count
+	}
+	rewrite := logCount <= start || g.HistoryLog.First() > start
+	if !rewrite {
+		last, err := g.HistoryLog.Get(logCount - 1)
+		if err != nil {
+			return err
+		}
+		if data, ok := history(logCount - 1); !ok || !bytes.Equal(data, last) {
+			log.Printf("history log of other history, rewrite")
+			rewrite = true
+		}
+	}
+	if rewrite {
+		logCount = start
+		if err := g.HistoryLog.Reset(logCount); err != nil {
+			return err
+		}
+	} else if err := g.HistoryLog.Truncate(logCount); err != nil {
+		return err
+	}
+
+	for idx := logCount; idx < count; idx++ {
+		data, ok := history(idx)
+		if !ok {
+			return fmt.Errorf("history %d missing", idx)
+		}
+		h := &model.BRC20History{}
+		h.Unmarshal(data)
+		if _, err := g.HistoryLog.Append(h.Height, data); err != nil {
+			return err
+		}
+	}
+	return g.HistoryLog.Sync()
+}
+
+func loadEntries[V any](kv storage.KV, prefix string, m map[string]V) (err error) {
+	iterErr := kv.Iterate([]byte(prefix), func(key, value []byte) bool {
+		var v V
+		if err = gob.NewDecoder(bytes.NewReader(value)).Decode(&v); err != nil {
+			err = fmt.Errorf("decode %x: %w", key, err)
+			return false
+		}
+		m[string(key[len(prefix):])] = v
+		return true
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	return err
+}
+
+// migrateStorage Convert keys of storage saved by an older version, in one batch.
+// Version 1 has history indexes, invalid transfers, roots, checkpoints and counts in meta, and
+// no holders. Versions before 3 have history keys of 4 bytes, versions before 4 have lists of
+// history in blocks, tickers and modules, and approves and commits in meta.
+func migrateStorage(kv storage.KV) error {
+	version := uint32(1)
+	value, err := kv.Get([]byte(STORAGE_KEY_VERSION))
+	if err == nil && len(value) == 4 {
+		version = binary.BigEndian.Uint32(value)
+	} else if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	if version > STORAGE_VERSION {
+		return fmt.Errorf("storage version %d newer than %d", version, STORAGE_VERSION)
+	}
+	if version == STORAGE_VERSION {
+		return nil
+	}
+	if value, err = kv.Get([]byte(STORAGE_KEY_META)); err != nil {
+		return err
+	}
+	meta, err := decodeGob[*BRC20ModuleIndexerStore](value)
+	if err != nil {
+		return fmt.Errorf("decode meta: %w", err)
+	}
+
+	batch := storage.NewBatch()
+	if version < 2 {
+		if err := migrateStorageEntries(kv, batch, meta); err != nil {
+			return err
+		}
+	}
+	if version < 3 {
+		err = kv.Iterate([]byte(STORAGE_PREFIX_HISTORY), func(key, value []byte) bool {
+			if len(key) != len(STORAGE_PREFIX_HISTORY)+4 {
+				return true
+			}
+			idx := binary.BigEndian.Uint32(key[len(STORAGE_PREFIX_HISTORY):])
+			batch.Delete(append([]byte{}, key...))
+			batch.Put(historyKey(uint64(idx)), append([]byte{}, value...))
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if version < 4 {
+		if err := migrateStorageLists(kv, batch, meta); err != nil {
+			return err
+		}
+	}
+	if value, err = encodeGob(meta); err != nil {
+		return fmt.Errorf("encode meta: %w", err)
+	}
+	batch.Put([]byte(STORAGE_KEY_META), value)
+	batch.Put([]byte(STORAGE_KEY_VERSION), binary.BigEndian.AppendUint32(nil, STORAGE_VERSION))
+	if err := kv.Write(batch); err != nil {
+		return err
+	}
+	log.Printf("migrated storage from version %d to %d, keys: %d", version, STORAGE_VERSION, batch.Len())
+	return nil
+}
+
+// migrateStorageEntries Move state of version 1 out of meta into keys of its own, and index
+// holders of balances. Indexes of history are left in meta for migrateStorageLists.
+func migrateStorageEntries(kv storage.KV, batch *storage.Batch, meta *BRC20ModuleIndexerStore) error {
+	// counts of state saved without them start at its height
+	seedCounts := meta.HolderCountSeries == nil
+	counts := make(map[string]int, 0)
+	var decodeErr error
+	err := kv.Iterate([]byte(STORAGE_PREFIX_BALANCE), func(key, value []byte) bool {
+		pkScript := string(key[len(STORAGE_PREFIX_BALANCE):])
+		userTokens, err := decodeGob[map[string]*model.BRC20TokenBalance](value)
+		if err != nil {
+			decodeErr = fmt.Errorf("decode %x: %w", key, err)
+			return false
+		}
+		for uniqueLowerTicker, balance := range userTokens {
+			if balance.OverallBalance().Sign() > 0 {
+				batch.Put(holderKey(uniqueLowerTicker, pkScript), []byte{})
+			}
+			if _, ok := counts[uniqueLowerTicker]; !ok {
+				counts[uniqueLowerTicker] = 0
+			}
+			if isHolder(balance) {
+				counts[uniqueLowerTicker]++
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if decodeErr != nil {
+		return decodeErr
+	}
+	if seedCounts {
+		meta.HolderCountSeries = make(map[string][]HolderCount, len(counts))
+		for uniqueLowerTicker, n := range counts {
+			meta.HolderCountSeries[uniqueLowerTicker] = []HolderCount{{Height: meta.BestHeight, Holders: n}}
+		}
+	}
+
+	if err := putEntries(batch, STORAGE_PREFIX_INVALID_TRANSFER, meta.InscriptionsInvalidTransferMap); err != nil {
+		return err
+	}
+	for h, root := range meta.StateRootsByHeight {
+		batch.Put([]byte(STORAGE_PREFIX_STATE_ROOT+stateRootKey(h)), append([]byte{}, root[:]...))
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_CHECKPOINT, meta.ModuleBalanceCheckpoints); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_HOLDER_COUNT, meta.HolderCountSeries); err != nil {
+		return err
+	}
+
+	meta.InscriptionsInvalidTransferMap = nil
+	meta.StateRootsByHeight = nil
+	meta.ModuleBalanceCheckpoints = nil
+	meta.HolderCountSeries = nil
+	return nil
+}
+
+// storageBlock Indexes of history appended by a block, saved by height before version 4.
+type storageBlock struct {
+	AllHistory           []uint64
+	FirstHistoryByHeight map[uint32]uint64
+}
+
+// migrateStorageLists Move lists of history out of blocks, tickers and modules into keys by
+// position, and maps of meta into keys by entry.
+func migrateStorageLists(kv storage.KV, batch *storage.Batch, meta *BRC20ModuleIndexerStore) (err error) {
+	// indexes of history by block, in meta for version 1
+	allHistory := meta.AllHistory
+	firstHistory := meta.FirstHistoryByHeight
+	if firstHistory == nil {
+		firstHistory = make(map[uint32]uint64, 0)
+	}
+	iterErr := kv.Iterate([]byte(STORAGE_PREFIX_BLOCK), func(key, value []byte) bool {
+		var block storageBlock
+		if block, err = decodeGob[storageBlock](value); err != nil {
+			err = fmt.Errorf("decode %x: %w", key, err)
+			return false
+		}
+		allHistory = append(allHistory, block.AllHistory...)
+		for h, idx := range block.FirstHistoryByHeight {
+			firstHistory[h] = idx
+		}
+		return true
+	})
+	if iterErr != nil {
+		return iterErr
+	} else if err != nil {
+		return err
+	}
+	batch.DeletePrefix([]byte(STORAGE_PREFIX_BLOCK))
+	if meta.AllHistoryStored, err = putListTail(batch, STORAGE_PREFIX_ALL_HISTORY, model.HistoryListStored{}, allHistory, encodeIndex); err != nil {
+		return err
+	}
+	for h, idx := range firstHistory {
+		batch.Put(firstHistoryKey(h), binary.BigEndian.AppendUint64(nil, idx))
+	}
+
+	iterErr = kv.Iterate([]byte(STORAGE_PREFIX_TICK), func(key, value []byte) bool {
+		ticker := string(key[len(STORAGE_PREFIX_TICK):])
+		var info *model.BRC20TokenInfo
+		if info, err = decodeGob[*model.BRC20TokenInfo](value); err != nil {
+			err = fmt.Errorf("decode %x: %w", key, err)
+			return false
+		}
+		for i, list := range historyListsOfToken(info) {
+			if info.HistoryStored[i], err = putListTail(batch, tokenListPrefix(ticker, i), model.HistoryListStored{}, *list, encodeIndex); err != nil {
+				return false
+			}
+			*list = nil
+		}
+		err = putEntry(batch, STORAGE_PREFIX_TICK, ticker, info)
+		return err == nil
+	})
+	if iterErr != nil {
+		return iterErr
+	} else if err != nil {
+		return err
+	}
+	iterErr = kv.Iterate([]byte(STORAGE_PREFIX_MODULE), func(key, value []byte) bool {
+		moduleId := string(key[len(STORAGE_PREFIX_MODULE):])
+		var infoStore *model.BRC20ModuleSwapInfoStore
+		if infoStore, err = decodeGob[*model.BRC20ModuleSwapInfoStore](value); err != nil {
+			err = fmt.Errorf("decode %x: %w", key, err)
+			return false
+		}
+		prefix := listPrefix(STORAGE_PREFIX_MODULE_HISTORY, moduleId)
+		if infoStore.HistoryStored, err = putListTail(batch, prefix, model.HistoryListStored{}, infoStore.History, encodeModuleHistory); err != nil {
+			return false
+		}
+		infoStore.History = nil
+		err = putEntry(batch, STORAGE_PREFIX_MODULE, moduleId, infoStore)
+		return err == nil
+	})
+	if iterErr != nil {
+		return iterErr
+	} else if err != nil {
+		return err
+	}
+
+	if err := putEntries(batch, STORAGE_PREFIX_OUTCOME_COUNT, meta.TickOutcomeCountMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_USER_MODULE, meta.UsersModuleWithTokenMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_USER_LP_MODULE, meta.UsersModuleWithLpTokenMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_APPROVE, meta.InscriptionsValidApproveMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_INVALID_APPROVE, meta.InscriptionsInvalidApproveMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_COND_APPROVE, meta.InscriptionsValidConditionalApproveMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_INVALID_COND_APPROVE, meta.InscriptionsInvalidConditionalApproveMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_COMMIT, meta.InscriptionsValidCommitMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_INVALID_COMMIT, meta.InscriptionsInvalidCommitMap); err != nil {
+		return err
+	}
+	// commits by id were built from all valid commits when loaded
+	for _, data := range meta.InscriptionsValidCommitMap {
+		if err := putEntry(batch, STORAGE_PREFIX_COMMIT_BY_ID, data.GetInscriptionId(), data); err != nil {
+			return err
+		}
+	}
+
+	meta.AllHistory = nil
+	meta.FirstHistoryByHeight = nil
+	meta.TickOutcomeCountMap = nil
+	meta.UsersModuleWithTokenMap = nil
+	meta.UsersModuleWithLpTokenMap = nil
+	meta.InscriptionsValidApproveMap = nil
+	meta.InscriptionsInvalidApproveMap = nil
+	meta.InscriptionsValidConditionalApproveMap = nil
+	meta.InscriptionsInvalidConditionalApproveMap = nil
+	meta.InscriptionsValidCommitMap = nil
+	meta.InscriptionsInvalidCommitMap = nil
+	return nil
+}
+
+// SaveStorage Save state into storage, in one batch. State in the storage of indexer is
+// written since last block only, other storage is replaced by all state. History in the
+// history log is not saved, load with the same log.
+func (g *BRC20ModuleIndexer) SaveStorage(kv storage.KV) error {
+	log.Printf("saving brc20 into storage ...")
+	registerSnapshotTypes()
+
+	if g.HistoryLog != nil {
+		if err := g.HistoryLog.Sync(); err != nil {
+			return err
+		}
+	}
+	if kv == g.Storage {
+		if err := g.flushStorage(); err != nil {
+			return err
+		}
+		log.Printf("save brc20 into storage ok")
+		return nil
+	}
+
+	batch := storage.NewBatch()
+	if err := g.putStorageState(batch, kv); err != nil {
+		return err
+	}
+	if err := kv.Write(batch); err != nil {
+		return err
+	}
+	log.Printf("save brc20 into storage ok, keys: %d", batch.Len())
+	return nil
+}
+
+// LoadStorage Load state saved by SaveStorage, which becomes the storage of indexer. Only
+// tickers without their lists, and counts are loaded, the rest is read from storage when used.
+// History is read from the history log or storage when needed.
+func (g *BRC20ModuleIndexer) LoadStorage(kv storage.KV) error {
+	log.Printf("loading brc20 from storage ...")
+	registerSnapshotTypes()
+
+	if _, err := kv.Get([]byte(STORAGE_KEY_META)); errors.Is(err, storage.ErrNotFound) {
+		return errors.New("no state in storage")
+	} else if err != nil {
+		return err
+	}
+	if err := migrateStorage(kv); err != nil {
+		return fmt.Errorf("migrate storage: %w", err)
+	}
+	value, err := kv.Get([]byte(STORAGE_KEY_META))
+	if err != nil {
+		return err
+	}
+	store, err := decodeGob[*BRC20ModuleIndexerStore](value)
+	if err != nil {
+		return fmt.Errorf("decode meta: %w", err)
+	}
+	if err := loadStorageState(kv, store); err != nil {
+		return err
+	}
+	commitsById := make(map[string]*model.InscriptionBRC20Data, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_COMMIT_BY_ID, commitsById); err != nil {
+		return err
+	}
+
+	// read when used
+	store.UserTokensBalanceData = make(map[string]map[string]*model.BRC20TokenBalance, 0)
+	store.UserAllHistory = make(map[string]*model.BRC20UserHistory, 0)
+	store.InscriptionsValidBRC20DataMap = make(map[string]*model.InscriptionBRC20InfoResp, 0)
+	store.InscriptionsValidTransferMap = make(map[string]*model.InscriptionBRC20TickInfo, 0)
+	store.InscriptionsInvalidTransferMap = make(map[string]*model.InscriptionBRC20TickInfo, 0)
+	store.InscriptionOutcomesMap = make(map[string][]*model.BRC20InscriptionOutcome, 0)
+	store.ModulesInfoMap = make(map[string]*model.BRC20ModuleSwapInfoStore, 0)
+	store.InscriptionsValidApproveMap = make(map[string]*model.InscriptionBRC20SwapInfo, 0)
+	store.InscriptionsInvalidApproveMap = make(map[string]*model.InscriptionBRC20SwapInfo, 0)
+	store.InscriptionsValidConditionalApproveMap = make(map[string]*model.InscriptionBRC20SwapConditionalApproveInfo, 0)
+	store.InscriptionsInvalidConditionalApproveMap = make(map[string]*model.InscriptionBRC20SwapConditionalApproveInfo, 0)
+	store.InscriptionsValidCommitMap = make(map[string]*model.InscriptionBRC20Data, 0)
+	store.InscriptionsInvalidCommitMap = make(map[string]*model.InscriptionBRC20Data, 0)
+
+	var history historyStore = kvHistory{kv}
+	if g.HistoryLog != nil {
+		if count := g.HistoryLog.Count(); count < store.HistoryCount || g.HistoryLog.First() > store.HistoryStart {
+			return fmt.Errorf("history log from %d to %d, storage from %d to %d",
+				g.HistoryLog.First(), count, store.HistoryStart, store.HistoryCount)
+		}
+		if err := g.HistoryLog.Truncate(store.HistoryCount); err != nil {
+			return err
+		}
+		history = logHistory{g.HistoryLog}
+	}
+
+	g.rw.Lock()
+	defer g.rw.Unlock()
+	g.LoadStore(store)
+	for uniqueLowerTicker := range g.InscriptionsTickerInfoMap {
+		if _, ok := g.TokenUsersBalanceData[uniqueLowerTicker]; !ok {
+			g.TokenUsersBalanceData[uniqueLowerTicker] = make(map[string]*model.BRC20TokenBalance, 0)
+		}
+	}
+	g.InscriptionsValidCommitMapById = commitsById
+	g.Storage = kv
+	g.stateKV = kv
+	g.storageHistoryHeight = g.LastHistoryHeight
+
+	// history stays in log or storage
+	g.HistoryData = make([][]byte, 0)
+	g.history = history
+	g.historyOffset = g.HistoryCount
+	log.Printf("load brc20 from storage ok")
+	return nil
+}
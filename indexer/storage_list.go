@@ -0,0 +1,303 @@
+package indexer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
+)
+
+// With storage, AllHistory, the index lists of tickers and history of modules are kept by
+// position, and only the part appended since last write is in memory. FirstHistoryByHeight
+// keeps the heights not written yet. Positions from the count of a list are left by rollback
+// and written again when appended.
+
+// listPrefix Keys of list of owner, the owner is prefixed by its length.
+func listPrefix(prefix, owner string) string {
+	return prefix + string([]byte{byte(len(owner))}) + owner
+}
+
+func listKey(prefix string, pos uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte(prefix), pos)
+}
+
+// tokenListPrefix Keys of list of ticker, of the order of historyListsOfToken.
+func tokenListPrefix(uniqueLowerTicker string, list int) string {
+	return listPrefix(STORAGE_PREFIX_TICK_HISTORY, uniqueLowerTicker) + string([]byte{byte(list)})
+}
+
+func firstHistoryKey(height uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte(STORAGE_PREFIX_FIRST_HISTORY), height)
+}
+
+func encodeIndex(idx uint64) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, idx), nil
+}
+
+func decodeIndex(value []byte) (uint64, error) {
+	if len(value) != 8 {
+		return 0, fmt.Errorf("index of %d bytes", len(value))
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func encodeModuleHistory(h *model.BRC20ModuleHistory) ([]byte, error) {
+	return encodeGob(h)
+}
+
+// storedList List in storage by position from stored.Start to stored.Count, then tail in memory.
+type storedList[V any] struct {
+	kv     storage.KV
+	prefix string
+	stored model.HistoryListStored
+	tail   []V
+	decode func([]byte) (V, error)
+}
+
+func (l storedList[V]) Len() int {
+	return int(l.stored.Count-l.stored.Start) + len(l.tail)
+}
+
+// At Item of position i from the first kept.
+func (l storedList[V]) At(i int) (v V, err error) {
+	n := int(l.stored.Count - l.stored.Start)
+	if i >= n {
+		return l.tail[i-n], nil
+	}
+	if l.kv == nil {
+		return v, errors.New("list not in storage")
+	}
+	key := listKey(l.prefix, l.stored.Start+uint64(i))
+	value, err := l.kv.Get(key)
+	if err != nil {
+		return v, fmt.Errorf("load %x: %w", key, err)
+	}
+	if v, err = l.decode(value); err != nil {
+		return v, fmt.Errorf("decode %x: %w", key, err)
+	}
+	return v, nil
+}
+
+// All Items in memory, the ones in storage read by one iteration.
+func (l storedList[V]) All() ([]V, error) {
+	return l.Front(l.Len())
+}
+
+// Front First n items, see All.
+func (l storedList[V]) Front(n int) ([]V, error) {
+	list := make([]V, 0, n)
+	stored := l.stored
+	if m := uint64(n); m < stored.Count-stored.Start {
+		stored.Count = stored.Start + m
+	}
+	if stored.Count > stored.Start {
+		if l.kv == nil {
+			return nil, errors.New("list not in storage")
+		}
+		var decodeErr error
+		err := l.kv.Iterate([]byte(l.prefix), func(key, value []byte) bool {
+			if len(key) != len(l.prefix)+8 {
+				return true
+			}
+			pos := binary.BigEndian.Uint64(key[len(l.prefix):])
+			if pos < stored.Start {
+				return true
+			}
+			if pos >= stored.Count {
+				return false
+			}
+			v, err := l.decode(value)
+			if err != nil {
+				decodeErr = fmt.Errorf("decode %x: %w", key, err)
+				return false
+			}
+			list = append(list, v)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		if len(list) != int(stored.Count-stored.Start) {
+			return nil, fmt.Errorf("list %x of %d, expected %d", l.prefix, len(list), stored.Count-stored.Start)
+		}
+	}
+	return append(list, l.tail[:n-len(list)]...), nil
+}
+
+// prunedStoredList Items at front of list before the first not matched, nil if none.
+func prunedStoredList[V any](l storedList[V], before func(V) bool) ([]V, error) {
+	var atErr error
+	n := sort.Search(l.Len(), func(i int) bool {
+		v, err := l.At(i)
+		if err != nil {
+			atErr = err
+			return true
+		}
+		return !before(v)
+	})
+	if atErr != nil {
+		return nil, atErr
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return l.Front(n)
+}
+
+// putListTail Items after stored part, returns the stored part after them.
+func putListTail[V any](batch *storage.Batch, prefix string, stored model.HistoryListStored, tail []V, encode func(V) ([]byte, error)) (model.HistoryListStored, error) {
+	for i, v := range tail {
+		key := listKey(prefix, stored.Count+uint64(i))
+		value, err := encode(v)
+		if err != nil {
+			return stored, fmt.Errorf("encode %x: %w", key, err)
+		}
+		batch.Put(key, value)
+	}
+	stored.Count += uint64(len(tail))
+	return stored, nil
+}
+
+// putList All items of list from position 0, returns the stored part of them.
+func putList[V any](batch *storage.Batch, l storedList[V], encode func(V) ([]byte, error)) (model.HistoryListStored, error) {
+	all, err := l.All()
+	if err != nil {
+		return model.HistoryListStored{}, err
+	}
+	return putListTail(batch, l.prefix, model.HistoryListStored{}, all, encode)
+}
+
+// dropStoredFront Remove n items at front of list, the ones in storage are deleted on next write.
+func dropStoredFront[V any](g *BRC20ModuleIndexer, prefix string, stored *model.HistoryListStored, tail *[]V, n int) {
+	for ; n > 0 && stored.Start < stored.Count; n-- {
+		g.deleteStorage(listKey(prefix, stored.Start), false)
+		stored.Start++
+	}
+	dropFront(tail, n)
+}
+
+// attachStoredFront Put items at front of list. The whole list is kept in memory, and written
+// again on next write.
+func attachStoredFront[V any](g *BRC20ModuleIndexer, l storedList[V], archived []V, stored *model.HistoryListStored, tail *[]V) error {
+	all, err := l.All()
+	if err != nil {
+		return err
+	}
+	*tail = append(archived, all...)
+	*stored = model.HistoryListStored{}
+	g.deleteStorage([]byte(l.prefix), true)
+	return nil
+}
+
+// deleteStorage Key or keys of prefix deleted on next write, before entries changed.
+func (g *BRC20ModuleIndexer) deleteStorage(key []byte, prefix bool) {
+	if g.Storage == nil {
+		return
+	}
+	if g.storagePending == nil {
+		g.storagePending = storage.NewBatch()
+	}
+	if prefix {
+		g.storagePending.DeletePrefix(key)
+	} else {
+		g.storagePending.Delete(key)
+	}
+}
+
+func (g *BRC20ModuleIndexer) allHistoryList() storedList[uint64] {
+	return storedList[uint64]{g.stateKV, STORAGE_PREFIX_ALL_HISTORY, g.allHistoryStored, g.AllHistory, decodeIndex}
+}
+
+// tokenHistoryLists Index lists of ticker, same order as historyListsOfToken.
+func (g *BRC20ModuleIndexer) tokenHistoryLists(uniqueLowerTicker string, info *model.BRC20TokenInfo) []storedList[uint64] {
+	tails := historyListsOfToken(info)
+	lists := make([]storedList[uint64], len(tails))
+	for i, tail := range tails {
+		lists[i] = storedList[uint64]{g.stateKV, tokenListPrefix(uniqueLowerTicker, i), info.HistoryStored[i], *tail, decodeIndex}
+	}
+	return lists
+}
+
+func (g *BRC20ModuleIndexer) moduleHistoryList(moduleInfo *model.BRC20ModuleSwapInfo) storedList[*model.BRC20ModuleHistory] {
+	return storedList[*model.BRC20ModuleHistory]{g.stateKV, listPrefix(STORAGE_PREFIX_MODULE_HISTORY, moduleInfo.ID),
+		moduleInfo.HistoryStored, moduleInfo.History, decodeGob[*model.BRC20ModuleHistory]}
+}
+
+// truncateAllHistory Keep n of AllHistory, by rollback.
+func (g *BRC20ModuleIndexer) truncateAllHistory(n int) {
+	stored := int(g.allHistoryStored.Count - g.allHistoryStored.Start)
+	if n >= stored {
+		g.AllHistory = g.AllHistory[:n-stored]
+		return
+	}
+	g.allHistoryStored.Count = g.allHistoryStored.Start + uint64(n)
+	g.AllHistory = nil
+}
+
+// allFirstHistory FirstHistoryByHeight with heights in storage.
+func (g *BRC20ModuleIndexer) allFirstHistory() (map[uint32]uint64, error) {
+	if g.stateKV == nil {
+		return g.FirstHistoryByHeight, nil
+	}
+	all := make(map[uint32]uint64, len(g.FirstHistoryByHeight))
+	var decodeErr error
+	err := g.stateKV.Iterate([]byte(STORAGE_PREFIX_FIRST_HISTORY), func(key, value []byte) bool {
+		height := binary.BigEndian.Uint32(key[len(STORAGE_PREFIX_FIRST_HISTORY):])
+		// heights rolled back are deleted on next write
+		if g.LastHistoryHeight == 0 || height > g.LastHistoryHeight {
+			return false
+		}
+		idx, err := decodeIndex(value)
+		if err != nil {
+			decodeErr = fmt.Errorf("decode %x: %w", key, err)
+			return false
+		}
+		all[height] = idx
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	for height, idx := range g.FirstHistoryByHeight {
+		all[height] = idx
+	}
+	return all, nil
+}
+
+// TickerInfo Ticker with all of its index lists, read from storage. Not locked, see Query.View.
+func (g *BRC20ModuleIndexer) TickerInfo(ticker string) (*model.BRC20TokenInfo, bool, error) {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	info, ok := g.InscriptionsTickerInfoMap[uniqueLowerTicker]
+	if !ok {
+		return nil, false, nil
+	}
+	if g.stateKV == nil {
+		return info, true, nil
+	}
+	full := *info
+	full.HistoryStored = [5]model.HistoryListStored{}
+	tails := historyListsOfToken(&full)
+	for i, list := range g.tokenHistoryLists(uniqueLowerTicker, info) {
+		all, err := list.All()
+		if err != nil {
+			return nil, false, err
+		}
+		*tails[i] = all
+	}
+	return &full, true, nil
+}
+
+// ModuleHistory History of module, with the part in storage. Not locked, see Query.View.
+func (g *BRC20ModuleIndexer) ModuleHistory(moduleInfo *model.BRC20ModuleSwapInfo) ([]*model.BRC20ModuleHistory, error) {
+	return g.moduleHistoryList(moduleInfo).All()
+}
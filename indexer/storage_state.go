@@ -0,0 +1,909 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/stateroot"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
+)
+
+// With storage, balances, user history, inscriptions, outcomes, approves, commits and modules
+// are read from it on first use by the process loop and kept in their maps as a cache. Entries
+// changed are written back at end of each block in one batch, with history of the block, then
+// the cache is cut to StorageCacheSize. Tickers, state roots, checkpoints, holder counts,
+// outcome counts and commits by id are kept in memory, and written back by entry too. Lists of
+// history are written by position, see storedList.
+
+// prefixes of state replaced by a full write, history is not
+var storageStatePrefixes = []string{
+	STORAGE_PREFIX_TICK,
+	STORAGE_PREFIX_BALANCE,
+	STORAGE_PREFIX_HOLDER,
+	STORAGE_PREFIX_USER_HIST,
+	STORAGE_PREFIX_VALID,
+	STORAGE_PREFIX_TRANSFER,
+	STORAGE_PREFIX_INVALID_TRANSFER,
+	STORAGE_PREFIX_OUTCOME,
+	STORAGE_PREFIX_MODULE,
+	STORAGE_PREFIX_STATE_ROOT,
+	STORAGE_PREFIX_CHECKPOINT,
+	STORAGE_PREFIX_HOLDER_COUNT,
+	STORAGE_PREFIX_ALL_HISTORY,
+	STORAGE_PREFIX_FIRST_HISTORY,
+	STORAGE_PREFIX_TICK_HISTORY,
+	STORAGE_PREFIX_MODULE_HISTORY,
+	STORAGE_PREFIX_OUTCOME_COUNT,
+	STORAGE_PREFIX_USER_MODULE,
+	STORAGE_PREFIX_USER_LP_MODULE,
+	STORAGE_PREFIX_APPROVE,
+	STORAGE_PREFIX_INVALID_APPROVE,
+	STORAGE_PREFIX_COND_APPROVE,
+	STORAGE_PREFIX_INVALID_COND_APPROVE,
+	STORAGE_PREFIX_COMMIT,
+	STORAGE_PREFIX_INVALID_COMMIT,
+	STORAGE_PREFIX_COMMIT_BY_ID,
+}
+
+// holderPrefix Keys of holders of ticker, the ticker is prefixed by its length.
+func holderPrefix(uniqueLowerTicker string) string {
+	return STORAGE_PREFIX_HOLDER + string([]byte{byte(len(uniqueLowerTicker))}) + uniqueLowerTicker
+}
+
+func holderKey(uniqueLowerTicker, pkScript string) []byte {
+	return []byte(holderPrefix(uniqueLowerTicker) + pkScript)
+}
+
+func stateRootKey(height uint32) string {
+	return string(binary.BigEndian.AppendUint32(nil, height))
+}
+
+func encodeGob(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeGob[V any](value []byte) (v V, err error) {
+	err = gob.NewDecoder(bytes.NewReader(value)).Decode(&v)
+	return v, err
+}
+
+func decodeModule(value []byte) (*model.BRC20ModuleSwapInfo, error) {
+	infoStore, err := decodeGob[*model.BRC20ModuleSwapInfoStore](value)
+	if err != nil {
+		return nil, err
+	}
+	return moduleFromStore(infoStore), nil
+}
+
+// readEntry Entry of key in kv, not found if kv is nil.
+func readEntry[V any](kv storage.KV, prefix, key string, decode func([]byte) (V, error)) (v V, ok bool, err error) {
+	if kv == nil {
+		return v, false, nil
+	}
+	value, err := kv.Get([]byte(prefix + key))
+	if errors.Is(err, storage.ErrNotFound) {
+		return v, false, nil
+	} else if err != nil {
+		return v, false, err
+	}
+	if v, err = decode(value); err != nil {
+		return v, false, fmt.Errorf("decode %s%x: %w", prefix, key, err)
+	}
+	return v, true, nil
+}
+
+// cacheEntry Entry of key in m, read from storage into m if missing. For the process loop only,
+// an entry failed to read is not found, and processing stops at the event, see failStorage.
+func cacheEntry[V any](g *BRC20ModuleIndexer, prefix string, m map[string]V, key string, decode func([]byte) (V, error)) (V, bool) {
+	if v, ok := m[key]; ok || g.stateKV == nil {
+		return v, ok
+	}
+	v, ok, err := readEntry(g.stateKV, prefix, key, decode)
+	if err != nil {
+		g.failStorage(fmt.Errorf("load %s%x from storage: %w", prefix, key, err))
+		return v, false
+	}
+	if ok {
+		m[key] = v
+	}
+	return v, ok
+}
+
+// peekEntry Entry of key in m, or read from storage without keeping it. For readers.
+func peekEntry[V any](g *BRC20ModuleIndexer, prefix string, m map[string]V, key string, decode func([]byte) (V, error)) (V, bool) {
+	if v, ok := m[key]; ok || g.stateKV == nil {
+		return v, ok
+	}
+	v, ok, err := readEntry(g.stateKV, prefix, key, decode)
+	if err != nil {
+		log.Printf("load %s%x from storage failed: %s", prefix, key, err)
+	}
+	return v, ok
+}
+
+// rangeEntries All entries of m and storage in order of key, the ones in m first. Entries of
+// storage are not kept, fn must not write storage.
+func rangeEntries[V any](g *BRC20ModuleIndexer, prefix string, m map[string]V, decode func([]byte) (V, error), fn func(key string, v V) bool) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	i := 0
+	stopped := false
+	if g.stateKV != nil {
+		var decodeErr error
+		err := g.stateKV.Iterate([]byte(prefix), func(k, value []byte) bool {
+			key := string(k[len(prefix):])
+			for ; i < len(keys) && keys[i] < key; i++ {
+				if !fn(keys[i], m[keys[i]]) {
+					stopped = true
+					return false
+				}
+			}
+			if i < len(keys) && keys[i] == key {
+				i++
+				stopped = !fn(key, m[key])
+				return !stopped
+			}
+			v, err := decode(value)
+			if err != nil {
+				decodeErr = fmt.Errorf("decode %s%x: %w", prefix, key, err)
+				return false
+			}
+			stopped = !fn(key, v)
+			return !stopped
+		})
+		if err != nil {
+			return err
+		}
+		if decodeErr != nil {
+			return decodeErr
+		}
+	}
+	for ; i < len(keys) && !stopped; i++ {
+		stopped = !fn(keys[i], m[keys[i]])
+	}
+	return nil
+}
+
+// allEntries All entries of m and storage, in memory. For snapshot and diff only.
+func allEntries[V any](g *BRC20ModuleIndexer, prefix string, m map[string]V, decode func([]byte) (V, error)) (map[string]V, error) {
+	if g.stateKV == nil {
+		return m, nil
+	}
+	all := make(map[string]V, len(m))
+	err := rangeEntries(g, prefix, m, decode, func(key string, v V) bool {
+		all[key] = v
+		return true
+	})
+	return all, err
+}
+
+// userTokensOf Balances of user by lower ticker, read from storage if missing, with holders of
+// tickers set. For the process loop only.
+func (g *BRC20ModuleIndexer) userTokensOf(pkScript string) (map[string]*model.BRC20TokenBalance, bool) {
+	if userTokens, ok := g.UserTokensBalanceData[pkScript]; ok || g.stateKV == nil {
+		return userTokens, ok
+	}
+	userTokens, ok := cacheEntry(g, STORAGE_PREFIX_BALANCE, g.UserTokensBalanceData, pkScript, decodeGob[map[string]*model.BRC20TokenBalance])
+	for uniqueLowerTicker, balance := range userTokens {
+		tokenUsers, ok := g.TokenUsersBalanceData[uniqueLowerTicker]
+		if !ok {
+			tokenUsers = make(map[string]*model.BRC20TokenBalance, 0)
+			g.TokenUsersBalanceData[uniqueLowerTicker] = tokenUsers
+		}
+		if balance.OverallBalance().Sign() > 0 {
+			tokenUsers[pkScript] = balance
+		}
+	}
+	return userTokens, ok
+}
+
+// userHistoryOf History of user, read from storage if missing. For the process loop only.
+func (g *BRC20ModuleIndexer) userHistoryOf(pkScript string) (*model.BRC20UserHistory, bool) {
+	return cacheEntry(g, STORAGE_PREFIX_USER_HIST, g.UserAllHistory, pkScript, decodeGob[*model.BRC20UserHistory])
+}
+
+// moduleOf Module of id, read from storage if missing. For the process loop only.
+func (g *BRC20ModuleIndexer) moduleOf(moduleId string) (*model.BRC20ModuleSwapInfo, bool) {
+	return cacheEntry(g, STORAGE_PREFIX_MODULE, g.ModulesInfoMap, moduleId, decodeModule)
+}
+
+// outcomesOf Outcomes of inscription, read from storage if missing. For the process loop only.
+func (g *BRC20ModuleIndexer) outcomesOf(inscriptionId string) []*model.BRC20InscriptionOutcome {
+	outcomes, _ := cacheEntry(g, STORAGE_PREFIX_OUTCOME, g.InscriptionOutcomesMap, inscriptionId, decodeGob[[]*model.BRC20InscriptionOutcome])
+	return outcomes
+}
+
+// UserBalances Balances of user by lower ticker, nil if none. Not locked, see Query.View. The
+// map is of the state, not to be modified.
+func (g *BRC20ModuleIndexer) UserBalances(pkScript string) map[string]*model.BRC20TokenBalance {
+	userTokens, _ := peekEntry(g, STORAGE_PREFIX_BALANCE, g.UserTokensBalanceData, pkScript, decodeGob[map[string]*model.BRC20TokenBalance])
+	return userTokens
+}
+
+// UserBalance Balance of user in ticker. Not locked, see Query.View.
+func (g *BRC20ModuleIndexer) UserBalance(ticker, pkScript string) (balance *model.BRC20TokenBalance, ok bool) {
+	balance, ok = g.UserBalances(pkScript)[strings.ToLower(ticker)]
+	return balance, ok
+}
+
+// RangeUserBalances Balances of all users in order of pkScript, stop if fn returns false. Not
+// locked, see Query.View.
+func (g *BRC20ModuleIndexer) RangeUserBalances(fn func(pkScript string, userTokens map[string]*model.BRC20TokenBalance) bool) error {
+	return rangeEntries(g, STORAGE_PREFIX_BALANCE, g.UserTokensBalanceData, decodeGob[map[string]*model.BRC20TokenBalance], fn)
+}
+
+// TokenHolders Balances of holders of ticker by pkScript. Not locked, see Query.View. The map is
+// of the state, not to be modified.
+func (g *BRC20ModuleIndexer) TokenHolders(ticker string) map[string]*model.BRC20TokenBalance {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	holders := g.TokenUsersBalanceData[uniqueLowerTicker]
+	if g.stateKV == nil {
+		return holders
+	}
+
+	all := make(map[string]*model.BRC20TokenBalance, len(holders))
+	for pkScript, balance := range holders {
+		all[pkScript] = balance
+	}
+	prefix := holderPrefix(uniqueLowerTicker)
+	var pkScripts []string
+	err := g.stateKV.Iterate([]byte(prefix), func(key, _ []byte) bool {
+		pkScripts = append(pkScripts, string(key[len(prefix):]))
+		return true
+	})
+	if err != nil {
+		log.Printf("load holders of %s from storage failed: %s", uniqueLowerTicker, err)
+	}
+	for _, pkScript := range pkScripts {
+		// balances in memory are newer
+		if _, ok := g.UserTokensBalanceData[pkScript]; ok {
+			continue
+		}
+		if balance, ok := g.UserBalances(pkScript)[uniqueLowerTicker]; ok {
+			all[pkScript] = balance
+		}
+	}
+	return all
+}
+
+// TokenHolderCount Count of holders of ticker, without reading their balances. Not locked, see
+// Query.View.
+func (g *BRC20ModuleIndexer) TokenHolderCount(ticker string) int {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	n := len(g.TokenUsersBalanceData[uniqueLowerTicker])
+	if g.stateKV == nil {
+		return n
+	}
+	prefix := holderPrefix(uniqueLowerTicker)
+	err := g.stateKV.Iterate([]byte(prefix), func(key, _ []byte) bool {
+		// balances in memory are newer
+		if _, ok := g.UserTokensBalanceData[string(key[len(prefix):])]; !ok {
+			n++
+		}
+		return true
+	})
+	if err != nil {
+		log.Printf("load holders of %s from storage failed: %s", uniqueLowerTicker, err)
+	}
+	return n
+}
+
+// UserHistory History of user. Not locked, see Query.View.
+func (g *BRC20ModuleIndexer) UserHistory(pkScript string) (*model.BRC20UserHistory, bool) {
+	return peekEntry(g, STORAGE_PREFIX_USER_HIST, g.UserAllHistory, pkScript, decodeGob[*model.BRC20UserHistory])
+}
+
+// RangeUserHistory History of all users in order of pkScript, stop if fn returns false. Not
+// locked, see Query.View.
+func (g *BRC20ModuleIndexer) RangeUserHistory(fn func(pkScript string, userHistory *model.BRC20UserHistory) bool) error {
+	return rangeEntries(g, STORAGE_PREFIX_USER_HIST, g.UserAllHistory, decodeGob[*model.BRC20UserHistory], fn)
+}
+
+// RangeValidTransfers Valid transfers inscribed in order of key, stop if fn returns false. Not
+// locked, see Query.View.
+func (g *BRC20ModuleIndexer) RangeValidTransfers(fn func(createIdxKey string, transferInfo *model.InscriptionBRC20TickInfo) bool) error {
+	return rangeEntries(g, STORAGE_PREFIX_TRANSFER, g.InscriptionsValidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo], fn)
+}
+
+// Module Module of id. Not locked, see Query.View. The module is of the state, not to be
+// modified.
+func (g *BRC20ModuleIndexer) Module(moduleId string) (*model.BRC20ModuleSwapInfo, bool) {
+	return peekEntry(g, STORAGE_PREFIX_MODULE, g.ModulesInfoMap, moduleId, decodeModule)
+}
+
+// RangeModules All modules in order of id, stop if fn returns false. Not locked, see Query.View.
+func (g *BRC20ModuleIndexer) RangeModules(fn func(moduleId string, moduleInfo *model.BRC20ModuleSwapInfo) bool) error {
+	return rangeEntries(g, STORAGE_PREFIX_MODULE, g.ModulesInfoMap, decodeModule, fn)
+}
+
+// ModuleIds Ids of all modules, sorted. Not locked, see Query.View.
+func (g *BRC20ModuleIndexer) ModuleIds() (moduleIds []string) {
+	for moduleId := range g.ModulesInfoMap {
+		moduleIds = append(moduleIds, moduleId)
+	}
+	if g.stateKV != nil {
+		err := g.stateKV.Iterate([]byte(STORAGE_PREFIX_MODULE), func(key, _ []byte) bool {
+			if moduleId := string(key[len(STORAGE_PREFIX_MODULE):]); g.ModulesInfoMap[moduleId] == nil {
+				moduleIds = append(moduleIds, moduleId)
+			}
+			return true
+		})
+		if err != nil {
+			log.Printf("load modules from storage failed: %s", err)
+		}
+	}
+	sort.Strings(moduleIds)
+	return moduleIds
+}
+
+// markStorageDirty Entry of key changed, written into storage at end of block.
+func (g *BRC20ModuleIndexer) markStorageDirty(prefix, key string) {
+	if g.Storage == nil {
+		return
+	}
+	if g.storageDirty == nil {
+		g.storageDirty = make(map[string]map[string]struct{}, 0)
+	}
+	keys, ok := g.storageDirty[prefix]
+	if !ok {
+		keys = make(map[string]struct{}, 0)
+		g.storageDirty[prefix] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// failStorage Storage failed to read or write, the first error is kept. Processing stops, and
+// nothing is written into storage after, the state is to be loaded again.
+func (g *BRC20ModuleIndexer) failStorage(err error) {
+	if g.storageErr == nil {
+		g.storageErr = err
+	}
+}
+
+// flushStorage Write entries changed, history and the rest of state into storage in one batch,
+// then cut the cache. All state is written if storage does not have it yet.
+func (g *BRC20ModuleIndexer) flushStorage() error {
+	if g.Storage == nil {
+		return nil
+	}
+	if g.storageErr != nil {
+		return g.storageErr
+	}
+	// deletes of pruning first
+	batch := g.storagePending
+	if batch == nil {
+		batch = storage.NewBatch()
+	}
+	full := g.stateKV == nil
+	var err error
+	if full {
+		err = g.putStorageState(batch, g.Storage)
+	} else {
+		err = g.putStorageChanges(batch)
+	}
+	if err == nil {
+		err = g.Storage.Write(batch)
+	}
+	if err != nil {
+		g.failStorage(fmt.Errorf("save state into storage: %w", err))
+		return g.storageErr
+	}
+	g.storagePending = nil
+
+	// history is in storage now
+	if g.HistoryLog == nil {
+		g.history = kvHistory{g.Storage}
+		g.historyArchived = nil
+		g.HistoryData = make([][]byte, 0)
+		g.historyOffset = g.HistoryCount
+	}
+	g.stateKV = g.Storage
+	g.commitStorageLists(full)
+
+	// entries of blocks can be rolled back stay in memory, their journals refer to them
+	if g.undoCurrent != nil {
+		for prefix, keys := range g.storageDirty {
+			if g.undoCurrent.storageKeys == nil {
+				g.undoCurrent.storageKeys = make(map[string]map[string]struct{}, 0)
+			}
+			if _, ok := g.undoCurrent.storageKeys[prefix]; !ok {
+				g.undoCurrent.storageKeys[prefix] = make(map[string]struct{}, 0)
+			}
+			for key := range keys {
+				g.undoCurrent.storageKeys[prefix][key] = struct{}{}
+			}
+		}
+	}
+	g.storageDirty = nil
+	g.evictStorageCache()
+	return nil
+}
+
+// commitStorageLists Parts of lists in memory are in storage after write, of all tickers and
+// modules or the ones written.
+func (g *BRC20ModuleIndexer) commitStorageLists(all bool) {
+	g.allHistoryStored.Count += uint64(len(g.AllHistory))
+	g.AllHistory = nil
+	g.FirstHistoryByHeight = make(map[uint32]uint64, 0)
+	g.storageHistoryHeight = g.LastHistoryHeight
+
+	for ticker, info := range g.InscriptionsTickerInfoMap {
+		if _, ok := g.storageDirty[STORAGE_PREFIX_TICK][ticker]; ok || all {
+			for i, list := range historyListsOfToken(info) {
+				info.HistoryStored[i].Count += uint64(len(*list))
+				*list = nil
+			}
+		}
+	}
+	for moduleId, moduleInfo := range g.ModulesInfoMap {
+		if _, ok := g.storageDirty[STORAGE_PREFIX_MODULE][moduleId]; ok || all {
+			moduleInfo.HistoryStored.Count += uint64(len(moduleInfo.History))
+			moduleInfo.History = nil
+		}
+	}
+}
+
+// putStorageHistory History of indexer into kv, only the history not in kv or log yet.
+func (g *BRC20ModuleIndexer) putStorageHistory(batch *storage.Batch, kv storage.KV) error {
+	inStore := g.history != nil && (g.history == historyStore(kvHistory{kv}) || g.history == g.primaryHistory() && g.HistoryLog != nil)
+	if !inStore {
+		batch.DeletePrefix([]byte(STORAGE_PREFIX_HISTORY))
+		for idx := g.HistoryStart; idx < g.HistoryCount; idx++ {
+			data, ok := g.GetHistoryData(idx)
+			if !ok {
+				return fmt.Errorf("history %d missing", idx)
+			}
+			batch.Put(historyKey(idx), data)
+		}
+		return nil
+	}
+
+	// attached from archive, kept in memory with log
+	if g.HistoryLog == nil || kv != g.Storage {
+		for i, data := range g.historyArchived {
+			batch.Put(historyKey(g.HistoryStart+uint64(i)), data)
+		}
+	}
+	for i, data := range g.HistoryData {
+		batch.Put(historyKey(g.historyOffset+uint64(i)), data)
+	}
+	return nil
+}
+
+// putStorageMeta State not saved by entry into one key, with the part of AllHistory written.
+func (g *BRC20ModuleIndexer) putStorageMeta(batch *storage.Batch, allHistory model.HistoryListStored) error {
+	meta := g.getStoreMeta()
+	meta.AllHistoryStored = allHistory
+	value, err := encodeGob(meta)
+	if err != nil {
+		return fmt.Errorf("encode meta: %w", err)
+	}
+	batch.Put([]byte(STORAGE_KEY_META), value)
+	batch.Put([]byte(STORAGE_KEY_VERSION), binary.BigEndian.AppendUint32(nil, STORAGE_VERSION))
+	return nil
+}
+
+func putEntry[V any](batch *storage.Batch, prefix, key string, v V) error {
+	value, err := encodeGob(v)
+	if err != nil {
+		return fmt.Errorf("encode %s%x: %w", prefix, key, err)
+	}
+	batch.Put([]byte(prefix+key), value)
+	return nil
+}
+
+func putEntries[V any](batch *storage.Batch, prefix string, m map[string]V) error {
+	for key, v := range m {
+		if err := putEntry(batch, prefix, key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putMapEntry Entry of key in m, deleted if not in m.
+func putMapEntry[V any](batch *storage.Batch, prefix, key string, m map[string]V) error {
+	v, ok := m[key]
+	if !ok {
+		batch.Delete([]byte(prefix + key))
+		return nil
+	}
+	return putEntry(batch, prefix, key, v)
+}
+
+// putAllEntries All entries of m and storage of indexer into batch.
+func putAllEntries[V any](g *BRC20ModuleIndexer, batch *storage.Batch, prefix string, m map[string]V, decode func([]byte) (V, error)) (err error) {
+	rangeErr := rangeEntries(g, prefix, m, decode, func(key string, v V) bool {
+		err = putEntry(batch, prefix, key, v)
+		return err == nil
+	})
+	if rangeErr != nil {
+		return rangeErr
+	}
+	return err
+}
+
+// putTokenEntry Ticker without its lists, and the lists by position. Lists are written from
+// the first if full, otherwise only the parts in memory.
+func (g *BRC20ModuleIndexer) putTokenEntry(batch *storage.Batch, uniqueLowerTicker string, info *model.BRC20TokenInfo, full bool) (err error) {
+	entry := *info
+	for i, list := range g.tokenHistoryLists(uniqueLowerTicker, info) {
+		if full {
+			entry.HistoryStored[i], err = putList(batch, list, encodeIndex)
+		} else {
+			entry.HistoryStored[i], err = putListTail(batch, list.prefix, list.stored, list.tail, encodeIndex)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, list := range historyListsOfToken(&entry) {
+		*list = nil
+	}
+	return putEntry(batch, STORAGE_PREFIX_TICK, uniqueLowerTicker, &entry)
+}
+
+// putModuleEntry Module without its history, and the history by position, see putTokenEntry.
+func (g *BRC20ModuleIndexer) putModuleEntry(batch *storage.Batch, moduleId string, moduleInfo *model.BRC20ModuleSwapInfo, full bool) (err error) {
+	infoStore := moduleToStore(moduleInfo)
+	list := g.moduleHistoryList(moduleInfo)
+	if full {
+		infoStore.HistoryStored, err = putList(batch, list, encodeModuleHistory)
+	} else {
+		infoStore.HistoryStored, err = putListTail(batch, list.prefix, list.stored, list.tail, encodeModuleHistory)
+	}
+	if err != nil {
+		return err
+	}
+	infoStore.History = nil
+	return putEntry(batch, STORAGE_PREFIX_MODULE, moduleId, infoStore)
+}
+
+// putStorageState All state into kv, replacing state in it.
+func (g *BRC20ModuleIndexer) putStorageState(batch *storage.Batch, kv storage.KV) error {
+	if err := g.putStorageHistory(batch, kv); err != nil {
+		return err
+	}
+	for _, prefix := range storageStatePrefixes {
+		batch.DeletePrefix([]byte(prefix))
+	}
+
+	for ticker, info := range g.InscriptionsTickerInfoMap {
+		if err := g.putTokenEntry(batch, ticker, info, true); err != nil {
+			return err
+		}
+	}
+	var err error
+	rangeErr := g.RangeUserBalances(func(pkScript string, userTokens map[string]*model.BRC20TokenBalance) bool {
+		for uniqueLowerTicker, balance := range userTokens {
+			if balance.OverallBalance().Sign() > 0 {
+				batch.Put(holderKey(uniqueLowerTicker, pkScript), []byte{})
+			}
+		}
+		err = putEntry(batch, STORAGE_PREFIX_BALANCE, pkScript, userTokens)
+		return err == nil
+	})
+	if rangeErr != nil {
+		return rangeErr
+	} else if err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_USER_HIST, g.UserAllHistory, decodeGob[*model.BRC20UserHistory]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_VALID, g.InscriptionsValidBRC20DataMap, decodeGob[*model.InscriptionBRC20InfoResp]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_TRANSFER, g.InscriptionsValidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_INVALID_TRANSFER, g.InscriptionsInvalidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_OUTCOME, g.InscriptionOutcomesMap, decodeGob[[]*model.BRC20InscriptionOutcome]); err != nil {
+		return err
+	}
+	rangeErr = g.RangeModules(func(moduleId string, moduleInfo *model.BRC20ModuleSwapInfo) bool {
+		err = g.putModuleEntry(batch, moduleId, moduleInfo, true)
+		return err == nil
+	})
+	if rangeErr != nil {
+		return rangeErr
+	} else if err != nil {
+		return err
+	}
+	if err := g.putModuleRuntimeEntries(batch); err != nil {
+		return err
+	}
+
+	for h, root := range g.StateRootsByHeight {
+		batch.Put([]byte(STORAGE_PREFIX_STATE_ROOT+stateRootKey(h)), append([]byte{}, root[:]...))
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_CHECKPOINT, g.ModuleBalanceCheckpoints); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_HOLDER_COUNT, g.HolderCountSeries); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_OUTCOME_COUNT, g.TickOutcomeCountMap); err != nil {
+		return err
+	}
+
+	allHistory, err := putList(batch, g.allHistoryList(), encodeIndex)
+	if err != nil {
+		return err
+	}
+	firstHistory, err := g.allFirstHistory()
+	if err != nil {
+		return err
+	}
+	for h, idx := range firstHistory {
+		batch.Put(firstHistoryKey(h), binary.BigEndian.AppendUint64(nil, idx))
+	}
+	return g.putStorageMeta(batch, allHistory)
+}
+
+// putModuleRuntimeEntries Modules of users, approves and commits, all of memory and storage.
+func (g *BRC20ModuleIndexer) putModuleRuntimeEntries(batch *storage.Batch) error {
+	if err := putEntries(batch, STORAGE_PREFIX_USER_MODULE, g.UsersModuleWithTokenMap); err != nil {
+		return err
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_USER_LP_MODULE, g.UsersModuleWithLpTokenMap); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_APPROVE, g.InscriptionsValidApproveMap, decodeGob[*model.InscriptionBRC20SwapInfo]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_INVALID_APPROVE, g.InscriptionsInvalidApproveMap, decodeGob[*model.InscriptionBRC20SwapInfo]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_COND_APPROVE, g.InscriptionsValidConditionalApproveMap, decodeGob[*model.InscriptionBRC20SwapConditionalApproveInfo]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_INVALID_COND_APPROVE, g.InscriptionsInvalidConditionalApproveMap, decodeGob[*model.InscriptionBRC20SwapConditionalApproveInfo]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_COMMIT, g.InscriptionsValidCommitMap, decodeGob[*model.InscriptionBRC20Data]); err != nil {
+		return err
+	}
+	if err := putAllEntries(g, batch, STORAGE_PREFIX_INVALID_COMMIT, g.InscriptionsInvalidCommitMap, decodeGob[*model.InscriptionBRC20Data]); err != nil {
+		return err
+	}
+	return putEntries(batch, STORAGE_PREFIX_COMMIT_BY_ID, g.InscriptionsValidCommitMapById)
+}
+
+// putStorageChanges Entries changed since last write, and history of the blocks.
+func (g *BRC20ModuleIndexer) putStorageChanges(batch *storage.Batch) error {
+	if err := g.putStorageHistory(batch, g.Storage); err != nil {
+		return err
+	}
+	for prefix, keys := range g.storageDirty {
+		for key := range keys {
+			if err := g.putStorageEntry(batch, prefix, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	allHistory, err := putListTail(batch, STORAGE_PREFIX_ALL_HISTORY, g.allHistoryStored, g.AllHistory, encodeIndex)
+	if err != nil {
+		return err
+	}
+	// heights rolled back, then the ones not written yet
+	if g.LastHistoryHeight == 0 {
+		if g.storageHistoryHeight != 0 {
+			batch.DeletePrefix([]byte(STORAGE_PREFIX_FIRST_HISTORY))
+		}
+	} else {
+		for h := g.LastHistoryHeight + 1; h <= g.storageHistoryHeight; h++ {
+			batch.Delete(firstHistoryKey(h))
+		}
+	}
+	for h, idx := range g.FirstHistoryByHeight {
+		batch.Put(firstHistoryKey(h), binary.BigEndian.AppendUint64(nil, idx))
+	}
+	return g.putStorageMeta(batch, allHistory)
+}
+
+// putStorageEntry Entry of key as in memory, deleted if not in memory.
+func (g *BRC20ModuleIndexer) putStorageEntry(batch *storage.Batch, prefix, key string) error {
+	switch prefix {
+	case STORAGE_PREFIX_TICK:
+		info, ok := g.InscriptionsTickerInfoMap[key]
+		if !ok {
+			batch.Delete([]byte(prefix + key))
+			batch.DeletePrefix([]byte(listPrefix(STORAGE_PREFIX_TICK_HISTORY, key)))
+			return nil
+		}
+		return g.putTokenEntry(batch, key, info, false)
+	case STORAGE_PREFIX_BALANCE:
+		// holders of tickers, by balances in storage before
+		old, _, err := readEntry(g.stateKV, prefix, key, decodeGob[map[string]*model.BRC20TokenBalance])
+		if err != nil {
+			return err
+		}
+		for uniqueLowerTicker, balance := range old {
+			if balance.OverallBalance().Sign() > 0 {
+				batch.Delete(holderKey(uniqueLowerTicker, key))
+			}
+		}
+		for uniqueLowerTicker, balance := range g.UserTokensBalanceData[key] {
+			if balance.OverallBalance().Sign() > 0 {
+				batch.Put(holderKey(uniqueLowerTicker, key), []byte{})
+			}
+		}
+		return putMapEntry(batch, prefix, key, g.UserTokensBalanceData)
+	case STORAGE_PREFIX_USER_HIST:
+		return putMapEntry(batch, prefix, key, g.UserAllHistory)
+	case STORAGE_PREFIX_VALID:
+		return putMapEntry(batch, prefix, key, g.InscriptionsValidBRC20DataMap)
+	case STORAGE_PREFIX_TRANSFER:
+		return putMapEntry(batch, prefix, key, g.InscriptionsValidTransferMap)
+	case STORAGE_PREFIX_INVALID_TRANSFER:
+		return putMapEntry(batch, prefix, key, g.InscriptionsInvalidTransferMap)
+	case STORAGE_PREFIX_OUTCOME:
+		return putMapEntry(batch, prefix, key, g.InscriptionOutcomesMap)
+	case STORAGE_PREFIX_MODULE:
+		moduleInfo, ok := g.ModulesInfoMap[key]
+		if !ok {
+			batch.Delete([]byte(prefix + key))
+			batch.DeletePrefix([]byte(listPrefix(STORAGE_PREFIX_MODULE_HISTORY, key)))
+			return nil
+		}
+		return g.putModuleEntry(batch, key, moduleInfo, false)
+	case STORAGE_PREFIX_STATE_ROOT:
+		root, ok := g.StateRootsByHeight[binary.BigEndian.Uint32([]byte(key))]
+		if !ok {
+			batch.Delete([]byte(prefix + key))
+			return nil
+		}
+		batch.Put([]byte(prefix+key), append([]byte{}, root[:]...))
+		return nil
+	case STORAGE_PREFIX_CHECKPOINT:
+		return putMapEntry(batch, prefix, key, g.ModuleBalanceCheckpoints)
+	case STORAGE_PREFIX_HOLDER_COUNT:
+		return putMapEntry(batch, prefix, key, g.HolderCountSeries)
+	case STORAGE_PREFIX_OUTCOME_COUNT:
+		return putMapEntry(batch, prefix, key, g.TickOutcomeCountMap)
+	case STORAGE_PREFIX_APPROVE:
+		return putMapEntry(batch, prefix, key, g.InscriptionsValidApproveMap)
+	case STORAGE_PREFIX_INVALID_APPROVE:
+		return putMapEntry(batch, prefix, key, g.InscriptionsInvalidApproveMap)
+	case STORAGE_PREFIX_COND_APPROVE:
+		return putMapEntry(batch, prefix, key, g.InscriptionsValidConditionalApproveMap)
+	case STORAGE_PREFIX_INVALID_COND_APPROVE:
+		return putMapEntry(batch, prefix, key, g.InscriptionsInvalidConditionalApproveMap)
+	case STORAGE_PREFIX_COMMIT:
+		return putMapEntry(batch, prefix, key, g.InscriptionsValidCommitMap)
+	case STORAGE_PREFIX_INVALID_COMMIT:
+		return putMapEntry(batch, prefix, key, g.InscriptionsInvalidCommitMap)
+	case STORAGE_PREFIX_COMMIT_BY_ID:
+		return putMapEntry(batch, prefix, key, g.InscriptionsValidCommitMapById)
+	}
+	return fmt.Errorf("unknown storage prefix %s", prefix)
+}
+
+// evictStorageCache Drop entries beyond StorageCacheSize from maps, except the ones changed by
+// blocks can be rolled back.
+func (g *BRC20ModuleIndexer) evictStorageCache() {
+	if g.stateKV == nil {
+		return
+	}
+	pinned := func(prefix, key string) bool {
+		for _, undo := range g.undoJournals {
+			if _, ok := undo.storageKeys[prefix][key]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	for pkScript, userTokens := range g.UserTokensBalanceData {
+		if len(g.UserTokensBalanceData) <= g.StorageCacheSize {
+			break
+		}
+		if pinned(STORAGE_PREFIX_BALANCE, pkScript) {
+			continue
+		}
+		delete(g.UserTokensBalanceData, pkScript)
+		for uniqueLowerTicker, balance := range userTokens {
+			if tokenUsers := g.TokenUsersBalanceData[uniqueLowerTicker]; tokenUsers[pkScript] == balance {
+				delete(tokenUsers, pkScript)
+			}
+		}
+	}
+	evictEntries(g.UserAllHistory, g.StorageCacheSize, STORAGE_PREFIX_USER_HIST, pinned)
+	evictEntries(g.InscriptionsValidBRC20DataMap, g.StorageCacheSize, STORAGE_PREFIX_VALID, pinned)
+	evictEntries(g.InscriptionsValidTransferMap, g.StorageCacheSize, STORAGE_PREFIX_TRANSFER, pinned)
+	evictEntries(g.InscriptionsInvalidTransferMap, g.StorageCacheSize, STORAGE_PREFIX_INVALID_TRANSFER, pinned)
+	evictEntries(g.InscriptionOutcomesMap, g.StorageCacheSize, STORAGE_PREFIX_OUTCOME, pinned)
+	evictEntries(g.ModulesInfoMap, g.StorageCacheSize, STORAGE_PREFIX_MODULE, pinned)
+	evictEntries(g.InscriptionsValidApproveMap, g.StorageCacheSize, STORAGE_PREFIX_APPROVE, pinned)
+	evictEntries(g.InscriptionsInvalidApproveMap, g.StorageCacheSize, STORAGE_PREFIX_INVALID_APPROVE, pinned)
+	evictEntries(g.InscriptionsValidConditionalApproveMap, g.StorageCacheSize, STORAGE_PREFIX_COND_APPROVE, pinned)
+	evictEntries(g.InscriptionsInvalidConditionalApproveMap, g.StorageCacheSize, STORAGE_PREFIX_INVALID_COND_APPROVE, pinned)
+	evictEntries(g.InscriptionsValidCommitMap, g.StorageCacheSize, STORAGE_PREFIX_COMMIT, pinned)
+	evictEntries(g.InscriptionsInvalidCommitMap, g.StorageCacheSize, STORAGE_PREFIX_INVALID_COMMIT, pinned)
+}
+
+func evictEntries[V any](m map[string]V, size int, prefix string, pinned func(prefix, key string) bool) {
+	for key := range m {
+		if len(m) <= size {
+			return
+		}
+		if !pinned(prefix, key) {
+			delete(m, key)
+		}
+	}
+}
+
+// rollbackStorage Entries changed by block undone are written again on next write.
+func (g *BRC20ModuleIndexer) rollbackStorage(undo *BRC20BlockUndo) {
+	if g.Storage == nil {
+		return
+	}
+	for prefix, keys := range undo.storageKeys {
+		for key := range keys {
+			g.markStorageDirty(prefix, key)
+		}
+	}
+}
+
+// loadStorageState State saved by entry, without entries read on use.
+func loadStorageState(kv storage.KV, store *BRC20ModuleIndexerStore) error {
+	store.InscriptionsTickerInfoMap = make(map[string]*model.BRC20TokenInfo, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_TICK, store.InscriptionsTickerInfoMap); err != nil {
+		return err
+	}
+	store.ModuleBalanceCheckpoints = make(map[string]map[string]map[string][]*ModuleBalanceCheckpoint, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_CHECKPOINT, store.ModuleBalanceCheckpoints); err != nil {
+		return err
+	}
+	store.HolderCountSeries = make(map[string][]HolderCount, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_HOLDER_COUNT, store.HolderCountSeries); err != nil {
+		return err
+	}
+
+	store.StateRootsByHeight = make(map[uint32]stateroot.Hash, 0)
+	err := kv.Iterate([]byte(STORAGE_PREFIX_STATE_ROOT), func(key, value []byte) bool {
+		var root stateroot.Hash
+		copy(root[:], value)
+		store.StateRootsByHeight[binary.BigEndian.Uint32(key[len(STORAGE_PREFIX_STATE_ROOT):])] = root
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	store.TickOutcomeCountMap = make(map[string]map[string]uint32, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_OUTCOME_COUNT, store.TickOutcomeCountMap); err != nil {
+		return err
+	}
+	store.UsersModuleWithTokenMap = make(map[string]string, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_USER_MODULE, store.UsersModuleWithTokenMap); err != nil {
+		return err
+	}
+	store.UsersModuleWithLpTokenMap = make(map[string]string, 0)
+	if err := loadEntries(kv, STORAGE_PREFIX_USER_LP_MODULE, store.UsersModuleWithLpTokenMap); err != nil {
+		return err
+	}
+
+	// lists stay in storage
+	store.FirstHistoryByHeight = make(map[uint32]uint64, 0)
+	return nil
+}
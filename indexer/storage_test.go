@@ -0,0 +1,308 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/historylog"
+	"github.com/unisat-wallet/libbrc20-indexer/storage"
+)
+
+// digestWithoutHistoryData Digest of state, history data compared by index instead.
+func digestWithoutHistoryData(t *testing.T, g, want *BRC20ModuleIndexer) string {
+	for idx := uint64(0); idx < want.HistoryCount; idx++ {
+		got, ok := g.GetHistoryData(idx)
+		if !ok || !bytes.Equal(got, want.HistoryData[idx]) {
+			t.Fatalf("history %d differs", idx)
+		}
+	}
+	g.HistoryData, want.HistoryData = nil, nil
+	return storedDigest(g)
+}
+
+func TestStorage(t *testing.T) {
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	fullHistory := full.HistoryData
+
+	kv := storage.NewMemory()
+	g := New(Options{Storage: kv})
+	processTestBlocks(g, testBlocks())
+	if len(g.HistoryData) != 0 {
+		t.Fatal("history kept in memory")
+	}
+
+	// rollback, then process again
+	if err := g.RollbackToHeight(102); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	processTestBlocks(g, testBlocks()[3:])
+	if got, want := digestWithoutHistoryData(t, g, full), storedDigest(full); got != want {
+		t.Fatalf("state with storage differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// save state into disk, load without history in memory
+	bolt, err := storage.OpenBolt(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open bolt failed: %s", err)
+	}
+	defer bolt.Close()
+	full.HistoryData = fullHistory
+	if err := full.SaveStorage(bolt); err != nil {
+		t.Fatalf("save storage failed: %s", err)
+	}
+	loaded := New(Options{Storage: bolt})
+	if err := loaded.LoadStorage(bolt); err != nil {
+		t.Fatalf("load storage failed: %s", err)
+	}
+	full.HistoryData = fullHistory
+	if got, want := digestWithoutHistoryData(t, loaded, full), storedDigest(full); got != want {
+		t.Fatalf("state loaded from storage differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if !strings.Contains(storedDigest(loaded), "best 105") {
+		t.Fatal("best height not loaded")
+	}
+
+	if err := New(Options{}).LoadStorage(storage.NewMemory()); err == nil {
+		t.Fatal("load empty storage")
+	}
+}
+
+func TestStorageMigration(t *testing.T) {
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	fullHistory := full.HistoryData
+
+	// storage of version 1, history keys of 4 bytes, indexes in meta, tickers and modules with
+	// their lists, and no holders
+	kv := storage.NewMemory()
+	if err := full.SaveStorage(kv); err != nil {
+		t.Fatalf("save storage failed: %s", err)
+	}
+	meta := full.GetStore()
+	batch := storage.NewBatch()
+	for _, prefix := range []string{STORAGE_PREFIX_ALL_HISTORY, STORAGE_PREFIX_FIRST_HISTORY, STORAGE_PREFIX_TICK_HISTORY,
+		STORAGE_PREFIX_MODULE_HISTORY, STORAGE_PREFIX_OUTCOME_COUNT, STORAGE_PREFIX_USER_MODULE, STORAGE_PREFIX_USER_LP_MODULE,
+		STORAGE_PREFIX_APPROVE, STORAGE_PREFIX_INVALID_APPROVE, STORAGE_PREFIX_COND_APPROVE, STORAGE_PREFIX_INVALID_COND_APPROVE,
+		STORAGE_PREFIX_COMMIT, STORAGE_PREFIX_INVALID_COMMIT, STORAGE_PREFIX_COMMIT_BY_ID} {
+		batch.DeletePrefix([]byte(prefix))
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_TICK, meta.InscriptionsTickerInfoMap); err != nil {
+		t.Fatalf("encode tickers failed: %s", err)
+	}
+	if err := putEntries(batch, STORAGE_PREFIX_MODULE, meta.ModulesInfoMap); err != nil {
+		t.Fatalf("encode modules failed: %s", err)
+	}
+	meta.InscriptionsTickerInfoMap = nil
+	meta.UserTokensBalanceData = nil
+	meta.UserAllHistory = nil
+	meta.InscriptionsValidBRC20DataMap = nil
+	meta.InscriptionsValidTransferMap = nil
+	meta.InscriptionOutcomesMap = nil
+	meta.ModulesInfoMap = nil
+	value, err := encodeGob(meta)
+	if err != nil {
+		t.Fatalf("encode meta failed: %s", err)
+	}
+	for _, prefix := range []string{STORAGE_PREFIX_HOLDER, STORAGE_PREFIX_INVALID_TRANSFER, STORAGE_PREFIX_BLOCK,
+		STORAGE_PREFIX_STATE_ROOT, STORAGE_PREFIX_CHECKPOINT, STORAGE_PREFIX_HOLDER_COUNT} {
+		batch.DeletePrefix([]byte(prefix))
+	}
+	batch.Put([]byte(STORAGE_KEY_META), value)
+	batch.DeletePrefix([]byte(STORAGE_PREFIX_HISTORY))
+	batch.Delete([]byte(STORAGE_KEY_VERSION))
+	for idx, data := range fullHistory {
+		batch.Put(binary.BigEndian.AppendUint32([]byte(STORAGE_PREFIX_HISTORY), uint32(idx)), data)
+	}
+	if err := kv.Write(batch); err != nil {
+		t.Fatalf("write failed: %s", err)
+	}
+
+	loaded := New(Options{Storage: kv})
+	if err := loaded.LoadStorage(kv); err != nil {
+		t.Fatalf("load storage failed: %s", err)
+	}
+	if got, want := digestWithoutHistoryData(t, loaded, full), storedDigest(full); got != want {
+		t.Fatalf("state of migrated storage differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := kv.Get(binary.BigEndian.AppendUint32([]byte(STORAGE_PREFIX_HISTORY), 0)); err == nil {
+		t.Fatal("history key of 4 bytes left")
+	}
+	if value, err := kv.Get([]byte(STORAGE_KEY_VERSION)); err != nil || binary.BigEndian.Uint32(value) != STORAGE_VERSION {
+		t.Fatal("version not migrated")
+	}
+}
+
+func TestHistoryLog(t *testing.T) {
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	fullHistory := full.HistoryData
+
+	dir := t.TempDir()
+	historyLog, err := historylog.Open(dir, historylog.Options{SegmentBlocks: 2})
+	if err != nil {
+		t.Fatalf("open history log failed: %s", err)
+	}
+	g := New(Options{HistoryLog: historyLog})
+	processTestBlocks(g, testBlocks())
+	if len(g.HistoryData) != 0 || historyLog.Count() != uint64(full.HistoryCount) {
+		t.Fatalf("history not in log, memory %d, log %d", len(g.HistoryData), historyLog.Count())
+	}
+	if err := g.RollbackToHeight(102); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	if historyLog.Count() != uint64(g.HistoryCount) {
+		t.Fatal("history log not truncated by rollback")
+	}
+	processTestBlocks(g, testBlocks()[3:])
+	if got, want := digestWithoutHistoryData(t, g, full), storedDigest(full); got != want {
+		t.Fatalf("state with history log differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// restart with snapshot and the same log
+	fname := filepath.Join(t.TempDir(), "snapshot")
+	if err := g.SaveSnapshot(fname); err != nil {
+		t.Fatalf("save snapshot failed: %s", err)
+	}
+	historyLog.Close()
+	if historyLog, err = historylog.Open(dir, historylog.Options{SegmentBlocks: 2}); err != nil {
+		t.Fatalf("reopen history log failed: %s", err)
+	}
+	defer historyLog.Close()
+	loaded := New(Options{HistoryLog: historyLog})
+	if err := loaded.LoadSnapshot(fname); err != nil {
+		t.Fatalf("load snapshot failed: %s", err)
+	}
+	full.HistoryData = fullHistory
+	if got, want := digestWithoutHistoryData(t, loaded, full), storedDigest(full); got != want {
+		t.Fatalf("state loaded with history log differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// history of snapshot into an empty log
+	empty, _ := historylog.Open(t.TempDir(), historylog.Options{})
+	defer empty.Close()
+	loaded = New(Options{HistoryLog: empty})
+	if err := loaded.LoadSnapshot(fname); err != nil {
+		t.Fatalf("load snapshot failed: %s", err)
+	}
+	if empty.Count() != uint64(full.HistoryCount) {
+		t.Fatalf("history of snapshot not in log: %d", empty.Count())
+	}
+}
+
+// countingKV Storage counting writes of keys one by one and of batches.
+type countingKV struct {
+	*storage.Memory
+	puts, writes int
+}
+
+func (kv *countingKV) Put(key, value []byte) error {
+	kv.puts++
+	return kv.Memory.Put(key, value)
+}
+
+func (kv *countingKV) Write(batch *storage.Batch) error {
+	kv.writes++
+	return kv.Memory.Write(batch)
+}
+
+func TestStorageCache(t *testing.T) {
+	full := newTestIndexer()
+	full.EnableOutcomes = true
+	processTestBlocks(full, testBlocks())
+
+	kv := &countingKV{Memory: storage.NewMemory()}
+	g := New(Options{Storage: kv, StorageCacheSize: 1, UndoDepth: 3, EnableOutcomes: true})
+	processTestBlocks(g, testBlocks())
+	if kv.puts != 0 || kv.writes == 0 {
+		t.Fatalf("state not written by batch, puts %d, writes %d", kv.puts, kv.writes)
+	}
+	if len(g.InscriptionOutcomesMap) >= len(full.InscriptionOutcomesMap) {
+		t.Fatalf("cache not cut, outcomes %d", len(g.InscriptionOutcomesMap))
+	}
+
+	// entries of blocks rolled back are read from storage again
+	if err := g.RollbackToHeight(102); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	replay := newTestIndexer()
+	replay.EnableOutcomes = true
+	processTestBlocks(replay, testBlocks()[:3])
+	if got, want := digestWithoutHistoryData(t, g, replay), storedDigest(replay); got != want {
+		t.Fatalf("state with cache after rollback differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	processTestBlocks(g, testBlocks()[3:])
+	if got, want := digestWithoutHistoryData(t, g, full), storedDigest(full); got != want {
+		t.Fatalf("state with cache differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	loaded := New(Options{Storage: kv, StorageCacheSize: 1})
+	if err := loaded.LoadStorage(kv); err != nil {
+		t.Fatalf("load storage failed: %s", err)
+	}
+	if got, want := storedDigest(loaded), storedDigest(g); got != want {
+		t.Fatalf("state loaded from storage differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// failKV Storage failing to read and write if fail is set.
+type failKV struct {
+	*storage.Memory
+	fail bool
+}
+
+var errStorage = errors.New("storage failed")
+
+func (kv *failKV) Get(key []byte) ([]byte, error) {
+	if kv.fail {
+		return nil, errStorage
+	}
+	return kv.Memory.Get(key)
+}
+
+func (kv *failKV) Write(batch *storage.Batch) error {
+	if kv.fail {
+		return errStorage
+	}
+	return kv.Memory.Write(batch)
+}
+
+func TestStorageFailure(t *testing.T) {
+	kv := &failKV{Memory: storage.NewMemory()}
+	g := New(Options{Storage: kv})
+	if err := g.ProcessSource(context.Background(), &failSource{blocks: testBlocks()[:2], err: io.EOF}); err != nil {
+		t.Fatalf("process source failed: %s", err)
+	}
+	// lists of history written are in storage only
+	if len(g.AllHistory) != 0 {
+		t.Fatalf("all history kept in memory: %d", len(g.AllHistory))
+	}
+	for ticker, info := range g.InscriptionsTickerInfoMap {
+		if len(info.History) != 0 || info.HistoryLen() == 0 {
+			t.Fatalf("history of %s kept in memory: %d of %d", ticker, len(info.History), info.HistoryLen())
+		}
+	}
+
+	// error is returned, nothing written after it
+	kv.fail = true
+	err := g.ProcessSource(context.Background(), &failSource{blocks: testBlocks()[2:], err: io.EOF})
+	if !errors.Is(err, errStorage) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kv.fail = false
+	loaded := New(Options{Storage: kv})
+	if err := loaded.LoadStorage(kv); err != nil {
+		t.Fatalf("load storage failed: %s", err)
+	}
+	replay := newTestIndexer()
+	processTestBlocks(replay, testBlocks()[:2])
+	if got, want := digestWithoutHistoryData(t, loaded, replay), storedDigest(replay); got != want {
+		t.Fatalf("state in storage after failure differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -7,26 +7,49 @@ import (
 
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/stateroot"
 )
 
 type BRC20ModuleIndexerStore struct {
 	BestHeight    uint32
 	EnableHistory bool
 
-	HistoryCount uint32
+	LastCreateIdxKey string
+	LastSequence     uint16
 
-	FirstHistoryByHeight map[uint32]uint32
+	HistoryCount uint64
+	HistoryStart uint64
+
+	FirstHistoryByHeight map[uint32]uint64
 	LastHistoryHeight    uint32
 
 	// brc20 base
-	AllHistory     []uint32
-	UserAllHistory map[string]*model.BRC20UserHistory
+	AllHistory       []uint64
+	AllHistoryStored model.HistoryListStored // AllHistory in storage before it, in meta of storage only
+	UserAllHistory   map[string]*model.BRC20UserHistory
 
 	InscriptionsTickerInfoMap map[string]*model.BRC20TokenInfo
 	UserTokensBalanceData     map[string]map[string]*model.BRC20TokenBalance
 
 	InscriptionsValidBRC20DataMap map[string]*model.InscriptionBRC20InfoResp
 
+	// outcome of inscriptions
+	EnableOutcomes         bool
+	InscriptionOutcomesMap map[string][]*model.BRC20InscriptionOutcome
+	TickOutcomeCountMap    map[string]map[string]uint32
+
+	// state root by height
+	EnableStateRoot    bool
+	StateRootsByHeight map[uint32]stateroot.Hash
+
+	// module balances by height
+	EnableBalanceCheckpoints bool
+	ModuleBalanceCheckpoints map[string]map[string]map[string][]*ModuleBalanceCheckpoint
+	ModuleCheckpointsStart   uint32
+
+	// holders of tickers by block
+	HolderCountSeries map[string][]HolderCount
+
 	// inner valid transfer
 	InscriptionsValidTransferMap map[string]*model.InscriptionBRC20TickInfo
 	// inner invalid transfer
@@ -73,7 +96,9 @@ func (g *BRC20ModuleIndexer) Load(fname string) {
 		return
 	}
 
+	g.rw.Lock()
 	g.LoadStore(store)
+	g.rw.Unlock()
 
 	log.Printf("load brc20 ok")
 }
@@ -132,7 +157,8 @@ func (g *BRC20ModuleIndexer) SaveHistory(fname string) {
 	defer gobFile.Close()
 
 	enc := gob.NewEncoder(gobFile)
-	for _, h := range g.HistoryData {
+	for idx := uint64(0); idx < g.HistoryCount; idx++ {
+		h, _ := g.GetHistoryData(idx)
 		if err := enc.Encode(h); err != nil {
 			log.Printf("save history data failed: %s", err)
 			return
@@ -141,105 +167,247 @@ func (g *BRC20ModuleIndexer) SaveHistory(fname string) {
 	log.Printf("save brc20 history ok")
 }
 
-func (g *BRC20ModuleIndexer) GetStore() (store *BRC20ModuleIndexerStore) {
-	store = &BRC20ModuleIndexerStore{
+// getStoreMeta State without entries of tokens, users, inscriptions, approves, commits and
+// modules, and without indexes of history.
+func (g *BRC20ModuleIndexer) getStoreMeta() (store *BRC20ModuleIndexerStore) {
+	return &BRC20ModuleIndexerStore{
 		BestHeight:    g.BestHeight,
 		EnableHistory: g.EnableHistory,
 
+		LastCreateIdxKey: g.LastCreateIdxKey,
+		LastSequence:     g.LastSequence,
+
 		HistoryCount: g.HistoryCount,
+		HistoryStart: g.HistoryStart,
 
-		FirstHistoryByHeight: g.FirstHistoryByHeight,
-		LastHistoryHeight:    g.LastHistoryHeight,
+		LastHistoryHeight: g.LastHistoryHeight,
 
-		// brc20 base
-		AllHistory:     g.AllHistory,
-		UserAllHistory: g.UserAllHistory,
+		// outcome of inscriptions
+		EnableOutcomes: g.EnableOutcomes,
 
-		InscriptionsTickerInfoMap: g.InscriptionsTickerInfoMap,
-		UserTokensBalanceData:     g.UserTokensBalanceData,
+		EnableStateRoot: g.EnableStateRoot,
 
-		InscriptionsValidBRC20DataMap: g.InscriptionsValidBRC20DataMap,
+		EnableBalanceCheckpoints: g.EnableBalanceCheckpoints,
+		ModuleCheckpointsStart:   g.ModuleCheckpointsStart,
+	}
+}
 
-		// inner valid transfer
-		InscriptionsValidTransferMap: g.InscriptionsValidTransferMap,
-		// inner invalid transfer
-		InscriptionsInvalidTransferMap: g.InscriptionsInvalidTransferMap,
+// GetStore All state, entries in storage are read into memory.
+func (g *BRC20ModuleIndexer) GetStore() (store *BRC20ModuleIndexerStore) {
+	store = g.getStoreMeta()
 
-		// module
-		// all modules info
-		// module of users [address]moduleid
-		UsersModuleWithTokenMap: g.UsersModuleWithTokenMap,
+	var err error
+	if store.FirstHistoryByHeight, err = g.allFirstHistory(); err != nil {
+		log.Panicf("load history heights from storage failed: %s", err)
+	}
 
-		// module lp of users [address]moduleid
-		UsersModuleWithLpTokenMap: g.UsersModuleWithLpTokenMap,
+	// brc20 base
+	if store.AllHistory, err = g.allHistoryList().All(); err != nil {
+		log.Panicf("load all history from storage failed: %s", err)
+	}
+	store.InscriptionsTickerInfoMap = make(map[string]*model.BRC20TokenInfo, len(g.InscriptionsTickerInfoMap))
+	for uniqueLowerTicker := range g.InscriptionsTickerInfoMap {
+		if store.InscriptionsTickerInfoMap[uniqueLowerTicker], _, err = g.TickerInfo(uniqueLowerTicker); err != nil {
+			log.Panicf("load history of tickers from storage failed: %s", err)
+		}
+	}
+	store.TickOutcomeCountMap = g.TickOutcomeCountMap
+	store.StateRootsByHeight = g.StateRootsByHeight
+	store.ModuleBalanceCheckpoints = g.ModuleBalanceCheckpoints
+	store.HolderCountSeries = g.HolderCountSeries
 
-		// runtime for approve
-		InscriptionsValidApproveMap:   g.InscriptionsValidApproveMap,
-		InscriptionsInvalidApproveMap: g.InscriptionsInvalidApproveMap,
+	if store.UserAllHistory, err = allEntries(g, STORAGE_PREFIX_USER_HIST, g.UserAllHistory, decodeGob[*model.BRC20UserHistory]); err != nil {
+		log.Panicf("load user history from storage failed: %s", err)
+	}
+	if store.UserTokensBalanceData, err = allEntries(g, STORAGE_PREFIX_BALANCE, g.UserTokensBalanceData, decodeGob[map[string]*model.BRC20TokenBalance]); err != nil {
+		log.Panicf("load balances from storage failed: %s", err)
+	}
+	if store.InscriptionsValidBRC20DataMap, err = allEntries(g, STORAGE_PREFIX_VALID, g.InscriptionsValidBRC20DataMap, decodeGob[*model.InscriptionBRC20InfoResp]); err != nil {
+		log.Panicf("load inscriptions from storage failed: %s", err)
+	}
+	// outcome of inscriptions
+	if store.InscriptionOutcomesMap, err = allEntries(g, STORAGE_PREFIX_OUTCOME, g.InscriptionOutcomesMap, decodeGob[[]*model.BRC20InscriptionOutcome]); err != nil {
+		log.Panicf("load outcomes from storage failed: %s", err)
+	}
+	// inner valid transfer
+	if store.InscriptionsValidTransferMap, err = allEntries(g, STORAGE_PREFIX_TRANSFER, g.InscriptionsValidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo]); err != nil {
+		log.Panicf("load transfers from storage failed: %s", err)
+	}
+	// inner invalid transfer
+	if store.InscriptionsInvalidTransferMap, err = allEntries(g, STORAGE_PREFIX_INVALID_TRANSFER, g.InscriptionsInvalidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo]); err != nil {
+		log.Panicf("load transfers from storage failed: %s", err)
+	}
 
-		// runtime for conditional approve
-		InscriptionsValidConditionalApproveMap:   g.InscriptionsValidConditionalApproveMap,
-		InscriptionsInvalidConditionalApproveMap: g.InscriptionsInvalidConditionalApproveMap,
+	// module
+	// all modules info
+	store.ModulesInfoMap = make(map[string]*model.BRC20ModuleSwapInfoStore)
+	var historyErr error
+	err = g.RangeModules(func(module string, info *model.BRC20ModuleSwapInfo) bool {
+		infoStore := moduleToStore(info)
+		infoStore.History, historyErr = g.ModuleHistory(info)
+		infoStore.HistoryStored = model.HistoryListStored{}
+		store.ModulesInfoMap[module] = infoStore
+		return historyErr == nil
+	})
+	if err == nil {
+		err = historyErr
+	}
+	if err != nil {
+		log.Panicf("load modules from storage failed: %s", err)
+	}
 
-		// runtime for commit
-		InscriptionsValidCommitMap:   g.InscriptionsValidCommitMap,
-		InscriptionsInvalidCommitMap: g.InscriptionsInvalidCommitMap,
+	// module of users [address]moduleid
+	store.UsersModuleWithTokenMap = g.UsersModuleWithTokenMap
+
+	// module lp of users [address]moduleid
+	store.UsersModuleWithLpTokenMap = g.UsersModuleWithLpTokenMap
+
+	// runtime for approve
+	if store.InscriptionsValidApproveMap, err = allEntries(g, STORAGE_PREFIX_APPROVE, g.InscriptionsValidApproveMap, decodeGob[*model.InscriptionBRC20SwapInfo]); err != nil {
+		log.Panicf("load approves from storage failed: %s", err)
+	}
+	if store.InscriptionsInvalidApproveMap, err = allEntries(g, STORAGE_PREFIX_INVALID_APPROVE, g.InscriptionsInvalidApproveMap, decodeGob[*model.InscriptionBRC20SwapInfo]); err != nil {
+		log.Panicf("load approves from storage failed: %s", err)
 	}
 
-	store.ModulesInfoMap = make(map[string]*model.BRC20ModuleSwapInfoStore)
-	for module, info := range g.ModulesInfoMap {
-		infoStore := &model.BRC20ModuleSwapInfoStore{
-			ID:                info.ID,
-			Name:              info.Name,
-			DeployerPkScript:  info.DeployerPkScript,
-			SequencerPkScript: info.SequencerPkScript,
-			GasToPkScript:     info.GasToPkScript,
-			LpFeePkScript:     info.LpFeePkScript,
-
-			FeeRateSwap: info.FeeRateSwap,
-			GasTick:     info.GasTick,
-
-			History: info.History, // fixme
-
-			// runtime for commit
-			CommitInvalidMap: info.CommitInvalidMap,
-			CommitIdMap:      info.CommitIdMap,
-			CommitIdChainMap: info.CommitIdChainMap,
-
-			// token holders in module
-			// ticker of users in module [address][tick]balanceData
-			UsersTokenBalanceDataMap: info.UsersTokenBalanceDataMap,
-
-			// swap
-			// lp token balance of address in module [pool][address]balance
-			LPTokenUsersBalanceMap: info.LPTokenUsersBalanceMap,
-
-			// swap total balance
-			// total balance of pool in module [pool]balanceData
-			SwapPoolTotalBalanceDataMap: info.SwapPoolTotalBalanceDataMap,
-
-			// module deposit/withdraw state [tick]balanceData
-			ConditionalApproveStateBalanceDataMap: info.ConditionalApproveStateBalanceDataMap,
-		}
+	// runtime for conditional approve
+	if store.InscriptionsValidConditionalApproveMap, err = allEntries(g, STORAGE_PREFIX_COND_APPROVE, g.InscriptionsValidConditionalApproveMap, decodeGob[*model.InscriptionBRC20SwapConditionalApproveInfo]); err != nil {
+		log.Panicf("load conditional approves from storage failed: %s", err)
+	}
+	if store.InscriptionsInvalidConditionalApproveMap, err = allEntries(g, STORAGE_PREFIX_INVALID_COND_APPROVE, g.InscriptionsInvalidConditionalApproveMap, decodeGob[*model.InscriptionBRC20SwapConditionalApproveInfo]); err != nil {
+		log.Panicf("load conditional approves from storage failed: %s", err)
+	}
 
-		store.ModulesInfoMap[module] = infoStore
+	// runtime for commit
+	if store.InscriptionsValidCommitMap, err = allEntries(g, STORAGE_PREFIX_COMMIT, g.InscriptionsValidCommitMap, decodeGob[*model.InscriptionBRC20Data]); err != nil {
+		log.Panicf("load commits from storage failed: %s", err)
+	}
+	if store.InscriptionsInvalidCommitMap, err = allEntries(g, STORAGE_PREFIX_INVALID_COMMIT, g.InscriptionsInvalidCommitMap, decodeGob[*model.InscriptionBRC20Data]); err != nil {
+		log.Panicf("load commits from storage failed: %s", err)
 	}
 
 	return store
 
 }
 
+func moduleToStore(info *model.BRC20ModuleSwapInfo) *model.BRC20ModuleSwapInfoStore {
+	return &model.BRC20ModuleSwapInfoStore{
+		ID:                info.ID,
+		Name:              info.Name,
+		DeployerPkScript:  info.DeployerPkScript,
+		SequencerPkScript: info.SequencerPkScript,
+		GasToPkScript:     info.GasToPkScript,
+		LpFeePkScript:     info.LpFeePkScript,
+
+		FeeRateSwap: info.FeeRateSwap,
+		GasTick:     info.GasTick,
+
+		History:       info.History, // fixme
+		HistoryStored: info.HistoryStored,
+
+		// runtime for commit
+		CommitInvalidMap: info.CommitInvalidMap,
+		CommitIdMap:      info.CommitIdMap,
+		CommitIdChainMap: info.CommitIdChainMap,
+
+		// token holders in module
+		// ticker of users in module [address][tick]balanceData
+		UsersTokenBalanceDataMap: info.UsersTokenBalanceDataMap,
+
+		// swap
+		// lp token balance of address in module [pool][address]balance
+		LPTokenUsersBalanceMap: info.LPTokenUsersBalanceMap,
+
+		// swap total balance
+		// total balance of pool in module [pool]balanceData
+		SwapPoolTotalBalanceDataMap: info.SwapPoolTotalBalanceDataMap,
+
+		// module deposit/withdraw state [tick]balanceData
+		ConditionalApproveStateBalanceDataMap: info.ConditionalApproveStateBalanceDataMap,
+	}
+}
+
+func moduleFromStore(infoStore *model.BRC20ModuleSwapInfoStore) *model.BRC20ModuleSwapInfo {
+	info := &model.BRC20ModuleSwapInfo{
+		ID:                infoStore.ID,
+		Name:              infoStore.Name,
+		DeployerPkScript:  infoStore.DeployerPkScript,
+		SequencerPkScript: infoStore.SequencerPkScript,
+		GasToPkScript:     infoStore.GasToPkScript,
+		LpFeePkScript:     infoStore.LpFeePkScript,
+
+		FeeRateSwap: infoStore.FeeRateSwap,
+		GasTick:     infoStore.GasTick,
+
+		History:       infoStore.History,
+		HistoryStored: infoStore.HistoryStored,
+
+		// runtime for commit
+		CommitInvalidMap: infoStore.CommitInvalidMap,
+		CommitIdMap:      infoStore.CommitIdMap,
+		CommitIdChainMap: infoStore.CommitIdChainMap,
+
+		// token holders in module
+		// ticker of users in module [address][tick]balanceData
+		UsersTokenBalanceDataMap: infoStore.UsersTokenBalanceDataMap,
+		TokenUsersBalanceDataMap: make(map[string]map[string]*model.BRC20ModuleTokenBalance, 0),
+
+		// swap
+		// lp token balance of address in module [pool][address]balance
+		LPTokenUsersBalanceMap: infoStore.LPTokenUsersBalanceMap,
+		UsersLPTokenBalanceMap: make(map[string]map[string]*decimal.Decimal, 0),
+
+		// swap total balance
+		// total balance of pool in module [pool]balanceData
+		SwapPoolTotalBalanceDataMap: infoStore.SwapPoolTotalBalanceDataMap,
+
+		// module deposit/withdraw state [tick]balanceData
+		ConditionalApproveStateBalanceDataMap: infoStore.ConditionalApproveStateBalanceDataMap,
+	}
+
+	// tick/user: balance
+	for address, dataMap := range info.UsersTokenBalanceDataMap {
+		for uniqueLowerTicker, tokenBalance := range dataMap {
+			tokenUsers, ok := info.TokenUsersBalanceDataMap[uniqueLowerTicker]
+			if !ok {
+				tokenUsers = make(map[string]*model.BRC20ModuleTokenBalance, 0)
+				info.TokenUsersBalanceDataMap[uniqueLowerTicker] = tokenUsers
+			}
+			tokenUsers[address] = tokenBalance
+		}
+	}
+
+	// pair/user: lpbalance
+	for pair, dataMap := range info.LPTokenUsersBalanceMap {
+		for address, lpBalance := range dataMap {
+			userTokens, ok := info.UsersLPTokenBalanceMap[address]
+			if !ok {
+				userTokens = make(map[string]*decimal.Decimal, 0)
+				info.UsersLPTokenBalanceMap[address] = userTokens
+			}
+			userTokens[pair] = lpBalance
+		}
+	}
+	return info
+}
+
 func (g *BRC20ModuleIndexer) LoadStore(store *BRC20ModuleIndexerStore) {
 	g.BestHeight = store.BestHeight
 	g.EnableHistory = store.EnableHistory
 
+	g.LastCreateIdxKey = store.LastCreateIdxKey
+	g.LastSequence = store.LastSequence
+
 	g.HistoryCount = store.HistoryCount
+	g.HistoryStart = store.HistoryStart
+	g.historyArchived = nil
 
 	g.FirstHistoryByHeight = store.FirstHistoryByHeight
 	g.LastHistoryHeight = store.LastHistoryHeight
 
 	// brc20 base
 	g.AllHistory = store.AllHistory
+	g.allHistoryStored = store.AllHistoryStored
 	g.UserAllHistory = store.UserAllHistory
 
 	g.InscriptionsTickerInfoMap = store.InscriptionsTickerInfoMap
@@ -261,6 +429,30 @@ func (g *BRC20ModuleIndexer) LoadStore(store *BRC20ModuleIndexerStore) {
 
 	g.InscriptionsValidBRC20DataMap = store.InscriptionsValidBRC20DataMap
 
+	// outcome of inscriptions, missing in old store
+	g.EnableOutcomes = store.EnableOutcomes
+	if store.InscriptionOutcomesMap != nil {
+		g.InscriptionOutcomesMap = store.InscriptionOutcomesMap
+	}
+	if store.TickOutcomeCountMap != nil {
+		g.TickOutcomeCountMap = store.TickOutcomeCountMap
+	}
+
+	// state root, tree is rebuilt on next root
+	g.EnableStateRoot = store.EnableStateRoot
+	g.loadStateRoots(store.StateRootsByHeight)
+
+	// module balances by height, missing in old store
+	g.EnableBalanceCheckpoints = store.EnableBalanceCheckpoints
+	g.ModuleBalanceCheckpoints = store.ModuleBalanceCheckpoints
+	g.ModuleCheckpointsStart = store.ModuleCheckpointsStart
+	g.checkpointDirtyModules = nil
+
+	// holders index is rebuilt on first use
+	g.holdersEverMu.Lock()
+	g.holdersEver = nil
+	g.holdersEverMu.Unlock()
+
 	// inner valid transfer
 	g.InscriptionsValidTransferMap = store.InscriptionsValidTransferMap
 	// inner invalid transfer
@@ -292,66 +484,22 @@ func (g *BRC20ModuleIndexer) LoadStore(store *BRC20ModuleIndexerStore) {
 	}
 
 	for module, infoStore := range store.ModulesInfoMap {
-		info := &model.BRC20ModuleSwapInfo{
-			ID:                infoStore.ID,
-			Name:              infoStore.Name,
-			DeployerPkScript:  infoStore.DeployerPkScript,
-			SequencerPkScript: infoStore.SequencerPkScript,
-			GasToPkScript:     infoStore.GasToPkScript,
-			LpFeePkScript:     infoStore.LpFeePkScript,
-
-			FeeRateSwap: infoStore.FeeRateSwap,
-			GasTick:     infoStore.GasTick,
-
-			History: infoStore.History,
-
-			// runtime for commit
-			CommitInvalidMap: infoStore.CommitInvalidMap,
-			CommitIdMap:      infoStore.CommitIdMap,
-			CommitIdChainMap: infoStore.CommitIdChainMap,
-
-			// token holders in module
-			// ticker of users in module [address][tick]balanceData
-			UsersTokenBalanceDataMap: infoStore.UsersTokenBalanceDataMap,
-			TokenUsersBalanceDataMap: make(map[string]map[string]*model.BRC20ModuleTokenBalance, 0),
-
-			// swap
-			// lp token balance of address in module [pool][address]balance
-			LPTokenUsersBalanceMap: infoStore.LPTokenUsersBalanceMap,
-			UsersLPTokenBalanceMap: make(map[string]map[string]*decimal.Decimal, 0),
-
-			// swap total balance
-			// total balance of pool in module [pool]balanceData
-			SwapPoolTotalBalanceDataMap: infoStore.SwapPoolTotalBalanceDataMap,
-
-			// module deposit/withdraw state [tick]balanceData
-			ConditionalApproveStateBalanceDataMap: infoStore.ConditionalApproveStateBalanceDataMap,
-		}
-
-		// tick/user: balance
-		for address, dataMap := range info.UsersTokenBalanceDataMap {
-			for uniqueLowerTicker, tokenBalance := range dataMap {
-				tokenUsers, ok := info.TokenUsersBalanceDataMap[uniqueLowerTicker]
-				if !ok {
-					tokenUsers = make(map[string]*model.BRC20ModuleTokenBalance, 0)
-					info.TokenUsersBalanceDataMap[uniqueLowerTicker] = tokenUsers
-				}
-				tokenUsers[address] = tokenBalance
-			}
-		}
-
-		// pair/user: lpbalance
-		for pair, dataMap := range info.LPTokenUsersBalanceMap {
-			for address, lpBalance := range dataMap {
-				userTokens, ok := info.UsersLPTokenBalanceMap[address]
-				if !ok {
-					userTokens = make(map[string]*decimal.Decimal, 0)
-					info.UsersLPTokenBalanceMap[address] = userTokens
-				}
-				userTokens[pair] = lpBalance
-			}
-		}
+		g.ModulesInfoMap[module] = moduleFromStore(infoStore)
+	}
 
-		g.ModulesInfoMap[module] = info
+	// holders of tickers by block, missing in old store
+	g.holderCountTouched = nil
+	g.resetHolderRanking()
+	if store.HolderCountSeries != nil {
+		g.HolderCountSeries = store.HolderCountSeries
+	} else {
+		g.seedHolderCounts()
 	}
+
+	// all state in memory, written into storage on next block
+	g.stateKV = nil
+	g.storageDirty = nil
+	g.storageHistoryHeight = 0
+	g.storagePending = nil
+	g.storageErr = nil
 }
@@ -0,0 +1,39 @@
+package indexer
+
+import (
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// touchTokenInfo Ticker info changed in this block, for state root and storage.
+func (g *BRC20ModuleIndexer) touchTokenInfo(tokenInfo *model.BRC20TokenInfo) {
+	g.markStateTickDirty(tokenInfo.Ticker)
+	g.markStorageDirty(STORAGE_PREFIX_TICK, strings.ToLower(tokenInfo.Ticker))
+}
+
+// touchTokenBalance Balance changed in this block, for state root, storage and holders of ticker.
+func (g *BRC20ModuleIndexer) touchTokenBalance(tokenBalance *model.BRC20TokenBalance) {
+	g.markStateBalanceDirty(tokenBalance.PkScript, tokenBalance.Ticker)
+	g.markStorageDirty(STORAGE_PREFIX_BALANCE, tokenBalance.PkScript)
+	g.addTokenHolderEver(strings.ToLower(tokenBalance.Ticker), tokenBalance.PkScript)
+	g.touchHolderCount(tokenBalance)
+}
+
+// touchConditionalApproveInfo Conditional approve changed in this block, for storage. The entry
+// is of the map holding it, valid or invalid.
+func (g *BRC20ModuleIndexer) touchConditionalApproveInfo(approveInfo *model.InscriptionBRC20SwapConditionalApproveInfo) {
+	key := approveInfo.Data.CreateIdxKey
+	if g.InscriptionsValidConditionalApproveMap[key] == approveInfo {
+		g.markStorageDirty(STORAGE_PREFIX_COND_APPROVE, key)
+	} else if g.InscriptionsInvalidConditionalApproveMap[key] == approveInfo {
+		g.markStorageDirty(STORAGE_PREFIX_INVALID_COND_APPROVE, key)
+	}
+}
+
+// touchModule Module changed in this block, for state root, storage and balance checkpoints.
+func (g *BRC20ModuleIndexer) touchModule(moduleInfo *model.BRC20ModuleSwapInfo) {
+	g.markStateModuleDirty(moduleInfo.ID)
+	g.markStorageDirty(STORAGE_PREFIX_MODULE, moduleInfo.ID)
+	g.markCheckpointModuleDirty(moduleInfo.ID)
+}
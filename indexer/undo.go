@@ -0,0 +1,382 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// BRC20BlockUndo Journal of all state changes applied by one block, used to
+// restore the exact state before the block on reorg.
+type BRC20BlockUndo struct {
+	Height uint32
+
+	// history counters before block
+	HistoryCount      uint64
+	LastHistoryHeight uint32
+	AllHistoryLen     int
+
+	// runtime for approve before block
+	ThisTxId                                    string
+	TxStaticTransferStatesForConditionalApprove []*model.TransferStateForConditionalApprove
+
+	// first touch in block
+	userHistoryTouched   map[string]struct{}
+	tokenInfoTouched     map[*model.BRC20TokenInfo]struct{}
+	tokenBalanceTouched  map[*model.BRC20TokenBalance]struct{}
+	moduleTouched        map[*model.BRC20ModuleSwapInfo]struct{}
+	moduleBalanceTouched map[*model.BRC20ModuleTokenBalance]struct{}
+	modulePoolTouched    map[*model.BRC20ModulePoolTotalBalance]struct{}
+	moduleStateTouched   map[*model.BRC20ModuleConditionalApproveStateBalance]struct{}
+	condApproveTouched   map[*model.InscriptionBRC20SwapConditionalApproveInfo]struct{}
+	inverseOps           []func() // applied in reverse order
+
+	// entries written into storage at end of block, by prefix
+	storageKeys map[string]map[string]struct{}
+}
+
+func newBRC20BlockUndo(g *BRC20ModuleIndexer, height uint32) *BRC20BlockUndo {
+	undo := &BRC20BlockUndo{
+		Height: height,
+
+		HistoryCount:      g.HistoryCount,
+		LastHistoryHeight: g.LastHistoryHeight,
+		AllHistoryLen:     g.allHistoryList().Len(),
+
+		ThisTxId: g.ThisTxId,
+
+		userHistoryTouched:   make(map[string]struct{}, 0),
+		tokenInfoTouched:     make(map[*model.BRC20TokenInfo]struct{}, 0),
+		tokenBalanceTouched:  make(map[*model.BRC20TokenBalance]struct{}, 0),
+		moduleTouched:        make(map[*model.BRC20ModuleSwapInfo]struct{}, 0),
+		moduleBalanceTouched: make(map[*model.BRC20ModuleTokenBalance]struct{}, 0),
+		modulePoolTouched:    make(map[*model.BRC20ModulePoolTotalBalance]struct{}, 0),
+		moduleStateTouched:   make(map[*model.BRC20ModuleConditionalApproveStateBalance]struct{}, 0),
+		condApproveTouched:   make(map[*model.InscriptionBRC20SwapConditionalApproveInfo]struct{}, 0),
+	}
+	undo.TxStaticTransferStatesForConditionalApprove = append(undo.TxStaticTransferStatesForConditionalApprove,
+		g.TxStaticTransferStatesForConditionalApprove...)
+	return undo
+}
+
+// BeginBlockUndo Open the undo journal of a new block. Journals beyond UndoDepth are dropped.
+func (g *BRC20ModuleIndexer) BeginBlockUndo(height uint32) {
+	if g.UndoDepth <= 0 {
+		return
+	}
+	if g.undoCurrent != nil && g.undoCurrent.Height == height {
+		return
+	}
+
+	g.undoCurrent = newBRC20BlockUndo(g, height)
+	g.undoJournals = append(g.undoJournals, g.undoCurrent)
+
+	if n := len(g.undoJournals) - g.UndoDepth; n > 0 {
+		// state after the dropped block is the earliest one can go back to
+		g.UndoFloorHeight = g.undoJournals[n-1].Height
+		g.undoJournals = g.undoJournals[n:]
+	}
+}
+
+// dropUndoJournals Blocks before can not be rolled back, by changes not in journals.
+func (g *BRC20ModuleIndexer) dropUndoJournals() {
+	g.undoJournals = nil
+	g.undoCurrent = nil
+	g.UndoFloorHeight = g.BestHeight
+}
+
+// UndoJournalHeights Heights of the blocks which can be rolled back.
+func (g *BRC20ModuleIndexer) UndoJournalHeights() (heights []uint32) {
+	for _, undo := range g.undoJournals {
+		heights = append(heights, undo.Height)
+	}
+	return heights
+}
+
+// RollbackToHeight Restore the exact state after block `height`, undoing all later blocks.
+func (g *BRC20ModuleIndexer) RollbackToHeight(height uint32) error {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	if height >= g.BestHeight {
+		return nil
+	}
+	if height < g.UndoFloorHeight {
+		return fmt.Errorf("rollback to %d, but undo journal starts at %d", height, g.UndoFloorHeight)
+	}
+	if len(g.undoJournals) == 0 {
+		return errors.New("rollback, undo journal empty")
+	}
+
+	n := len(g.undoJournals)
+	for n > 0 && g.undoJournals[n-1].Height > height {
+		undo := g.undoJournals[n-1]
+		g.applyBlockUndo(undo)
+		g.rollbackStorage(undo)
+		log.Printf("rollback block %d", undo.Height)
+		n--
+	}
+	g.undoJournals = g.undoJournals[:n]
+	g.undoCurrent = nil
+
+	// per-tx runtime state can not survive a block boundary
+	for _, moduleInfo := range g.ModulesInfoMap {
+		moduleInfo.ThisTxId = ""
+		moduleInfo.TransferStatesForConditionalApprove = nil
+		moduleInfo.ApproveStatesForConditionalApprove = nil
+	}
+
+	// same as the end of process loop
+	g.removeEmptyTokenHolders()
+
+	g.BestHeight = height
+	g.Durty = true
+
+	// block of height is complete
+	g.LastCreateIdxKey = ""
+	g.LastSequence = 0
+
+	// tree is rebuilt on next block
+	g.resetStateRoot(height)
+	g.rollbackBalanceCheckpoints(height)
+	g.rollbackHolderCounts(height)
+	g.resetHolderRanking()
+
+	// entries of blocks undone are written again
+	if err := g.flushStorage(); err != nil {
+		return err
+	}
+
+	g.notify(func(o Observer) { o.OnRollback(height) })
+	return nil
+}
+
+func (g *BRC20ModuleIndexer) applyBlockUndo(undo *BRC20BlockUndo) {
+	for i := len(undo.inverseOps) - 1; i >= 0; i-- {
+		undo.inverseOps[i]()
+	}
+
+	// history
+	g.HistoryCount = undo.HistoryCount
+	g.truncateHistory(undo.HistoryCount)
+	for h := range g.FirstHistoryByHeight {
+		if undo.LastHistoryHeight == 0 || h > undo.LastHistoryHeight {
+			delete(g.FirstHistoryByHeight, h)
+		}
+	}
+	g.LastHistoryHeight = undo.LastHistoryHeight
+	g.truncateAllHistory(undo.AllHistoryLen)
+
+	g.ThisTxId = undo.ThisTxId
+	g.TxStaticTransferStatesForConditionalApprove = undo.TxStaticTransferStatesForConditionalApprove
+}
+
+func (g *BRC20ModuleIndexer) addUndoOp(op func()) {
+	if g.undoCurrent == nil {
+		return
+	}
+	g.undoCurrent.inverseOps = append(g.undoCurrent.inverseOps, op)
+}
+
+// undoMapEntry Record the entry of the map before set or delete.
+func undoMapEntry[K comparable, V any](g *BRC20ModuleIndexer, m map[K]V, key K) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if v, ok := m[key]; ok {
+		g.addUndoOp(func() { m[key] = v })
+	} else {
+		g.addUndoOp(func() { delete(m, key) })
+	}
+}
+
+func (g *BRC20ModuleIndexer) undoUserHistory(pkScript string) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.userHistoryTouched[pkScript]; ok {
+		return
+	}
+	g.undoCurrent.userHistoryTouched[pkScript] = struct{}{}
+
+	userHistory, ok := g.UserAllHistory[pkScript]
+	if !ok {
+		g.addUndoOp(func() { delete(g.UserAllHistory, pkScript) })
+		return
+	}
+	n := len(userHistory.History)
+	g.addUndoOp(func() { userHistory.History = userHistory.History[:n] })
+}
+
+func (g *BRC20ModuleIndexer) undoTokenInfo(tokenInfo *model.BRC20TokenInfo) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.tokenInfoTouched[tokenInfo]; ok {
+		return
+	}
+	g.undoCurrent.tokenInfoTouched[tokenInfo] = struct{}{}
+
+	// decimals are never changed in place, copy values only
+	info := *tokenInfo
+	deploy := *tokenInfo.Deploy
+	data := *tokenInfo.Deploy.Data
+	g.addUndoOp(func() {
+		*tokenInfo.Deploy.Data = data
+		deploy.Data = tokenInfo.Deploy.Data
+		*tokenInfo.Deploy = deploy
+		info.Deploy = tokenInfo.Deploy
+		*tokenInfo = info
+	})
+}
+
+func (g *BRC20ModuleIndexer) undoTokenBalance(tokenBalance *model.BRC20TokenBalance) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.tokenBalanceTouched[tokenBalance]; ok {
+		return
+	}
+	g.undoCurrent.tokenBalanceTouched[tokenBalance] = struct{}{}
+
+	balance := *tokenBalance
+	if tokenBalance.ValidTransferMap != nil {
+		balance.ValidTransferMap = make(map[string]*model.InscriptionBRC20TickInfo, len(tokenBalance.ValidTransferMap))
+		for k, v := range tokenBalance.ValidTransferMap {
+			balance.ValidTransferMap[k] = v
+		}
+	}
+	g.addUndoOp(func() { *tokenBalance = balance })
+}
+
+// undoModule Record scalars, history and runtime of module. Entries of its maps are recorded
+// one by one, see undoMapEntry and undoModuleTokenBalance.
+func (g *BRC20ModuleIndexer) undoModule(moduleInfo *model.BRC20ModuleSwapInfo) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.moduleTouched[moduleInfo]; ok {
+		return
+	}
+	g.undoCurrent.moduleTouched[moduleInfo] = struct{}{}
+
+	// maps are kept, their entries are restored by own ops
+	info := *moduleInfo
+	g.addUndoOp(func() { *moduleInfo = info })
+}
+
+func (g *BRC20ModuleIndexer) undoModuleTokenBalance(tokenBalance *model.BRC20ModuleTokenBalance) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.moduleBalanceTouched[tokenBalance]; ok {
+		return
+	}
+	g.undoCurrent.moduleBalanceTouched[tokenBalance] = struct{}{}
+
+	balance := *tokenBalance
+	balance.ValidConditionalApproveMap = copyInscriptionMap(tokenBalance.ValidConditionalApproveMap)
+	balance.ValidApproveMap = copyInscriptionMap(tokenBalance.ValidApproveMap)
+	balance.ReadyToWithdrawMap = copyInscriptionMap(tokenBalance.ReadyToWithdrawMap)
+	g.addUndoOp(func() { *tokenBalance = balance })
+}
+
+func copyInscriptionMap(m map[string]*model.InscriptionBRC20Data) map[string]*model.InscriptionBRC20Data {
+	if m == nil {
+		return nil
+	}
+	copyMap := make(map[string]*model.InscriptionBRC20Data, len(m))
+	for k, v := range m {
+		copyMap[k] = v
+	}
+	return copyMap
+}
+
+func (g *BRC20ModuleIndexer) undoModulePool(pool *model.BRC20ModulePoolTotalBalance) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.modulePoolTouched[pool]; ok {
+		return
+	}
+	g.undoCurrent.modulePoolTouched[pool] = struct{}{}
+
+	balance := *pool
+	g.addUndoOp(func() { *pool = balance })
+}
+
+func (g *BRC20ModuleIndexer) undoModuleConditionalApproveState(stateBalance *model.BRC20ModuleConditionalApproveStateBalance) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.moduleStateTouched[stateBalance]; ok {
+		return
+	}
+	g.undoCurrent.moduleStateTouched[stateBalance] = struct{}{}
+
+	balance := *stateBalance
+	g.addUndoOp(func() { *stateBalance = balance })
+}
+
+// getModuleUserTokenBalance Balance of user in module to update, created if missing. Same as
+// BRC20ModuleSwapInfo.GetUserTokenBalance, with changes recorded in undo journal.
+func (g *BRC20ModuleIndexer) getModuleUserTokenBalance(moduleInfo *model.BRC20ModuleSwapInfo, ticker, userPkScript string) (tokenBalance *model.BRC20ModuleTokenBalance) {
+	uniqueLowerTicker := strings.ToLower(ticker)
+	if _, ok := moduleInfo.UsersTokenBalanceDataMap[userPkScript][uniqueLowerTicker]; !ok {
+		if usersTokens, ok := moduleInfo.UsersTokenBalanceDataMap[userPkScript]; ok {
+			undoMapEntry(g, usersTokens, uniqueLowerTicker)
+		} else {
+			undoMapEntry(g, moduleInfo.UsersTokenBalanceDataMap, userPkScript)
+		}
+		if tokenUsers, ok := moduleInfo.TokenUsersBalanceDataMap[uniqueLowerTicker]; ok {
+			undoMapEntry(g, tokenUsers, userPkScript)
+		} else {
+			undoMapEntry(g, moduleInfo.TokenUsersBalanceDataMap, uniqueLowerTicker)
+		}
+	}
+	tokenBalance = moduleInfo.GetUserTokenBalance(ticker, userPkScript)
+	g.undoModuleTokenBalance(tokenBalance)
+	return tokenBalance
+}
+
+// getModuleConditionalApproveStateBalance Deposit/withdraw state of ticker in module to update,
+// created if missing.
+func (g *BRC20ModuleIndexer) getModuleConditionalApproveStateBalance(moduleInfo *model.BRC20ModuleSwapInfo, ticker string) (stateBalance *model.BRC20ModuleConditionalApproveStateBalance) {
+	undoMapEntry(g, moduleInfo.ConditionalApproveStateBalanceDataMap, strings.ToLower(ticker))
+	stateBalance = moduleInfo.GetTickConditionalApproveStateBalance(ticker)
+	g.undoModuleConditionalApproveState(stateBalance)
+	return stateBalance
+}
+
+// setModuleLPBalance Set lp balance of user in pool, in both lp maps of module.
+func (g *BRC20ModuleIndexer) setModuleLPBalance(moduleInfo *model.BRC20ModuleSwapInfo, poolPair, userPkScript string, balance *decimal.Decimal) {
+	usersLpBalanceInPool := moduleInfo.LPTokenUsersBalanceMap[poolPair]
+	undoMapEntry(g, usersLpBalanceInPool, userPkScript)
+	usersLpBalanceInPool[userPkScript] = balance
+
+	lpsBalance, ok := moduleInfo.UsersLPTokenBalanceMap[userPkScript]
+	if !ok {
+		lpsBalance = make(map[string]*decimal.Decimal, 0)
+		undoMapEntry(g, moduleInfo.UsersLPTokenBalanceMap, userPkScript)
+		moduleInfo.UsersLPTokenBalanceMap[userPkScript] = lpsBalance
+	}
+	undoMapEntry(g, lpsBalance, poolPair)
+	lpsBalance[poolPair] = balance
+}
+
+func (g *BRC20ModuleIndexer) undoConditionalApproveInfo(approveInfo *model.InscriptionBRC20SwapConditionalApproveInfo) {
+	if g.undoCurrent == nil {
+		return
+	}
+	if _, ok := g.undoCurrent.condApproveTouched[approveInfo]; ok {
+		return
+	}
+	g.undoCurrent.condApproveTouched[approveInfo] = struct{}{}
+
+	info := *approveInfo
+	g.addUndoOp(func() { *approveInfo = info })
+}
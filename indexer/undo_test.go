@@ -0,0 +1,280 @@
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+const (
+	testUserA = "\x51\x20aaaa"
+	testUserB = "\x51\x20bbbb"
+)
+
+// testTxId returns a 32 bytes txid, so inscription ids differ.
+func testTxId(name string) string {
+	txid := make([]byte, 32)
+	copy(txid, name)
+	return string(txid)
+}
+
+// testBlocks returns fresh inscription data for heights 100..105, the
+// indexer keeps pointers to the data so each run needs its own copy.
+func testBlocks() (blocks [][]*model.InscriptionBRC20Data) {
+	inscribe := func(height uint32, idx uint64, pkScript, content string) *model.InscriptionBRC20Data {
+		key := &model.NFTCreateIdxKey{Height: height, IdxInBlock: idx}
+		return &model.InscriptionBRC20Data{
+			TxId:         testTxId(fmt.Sprintf("tx-%d-%d", height, idx)),
+			Satoshi:      546,
+			PkScript:     pkScript,
+			ContentBody:  []byte(content),
+			CreateIdxKey: key.String(),
+			Height:       height,
+			TxIdx:        uint32(idx),
+			BlockTime:    1700000000 + height,
+		}
+	}
+	move := func(height uint32, from *model.InscriptionBRC20Data, pkScript string) *model.InscriptionBRC20Data {
+		return &model.InscriptionBRC20Data{
+			IsTransfer:   true,
+			TxId:         testTxId(fmt.Sprintf("move-%d", height)),
+			Satoshi:      546,
+			PkScript:     pkScript,
+			ContentBody:  from.ContentBody,
+			CreateIdxKey: from.CreateIdxKey,
+			Height:       height,
+			BlockTime:    1700000000 + height,
+			Sequence:     1,
+		}
+	}
+
+	transferA := inscribe(102, 0, testUserA, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"60"}`)
+	transferB := inscribe(104, 0, testUserB, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"100"}`)
+	blocks = [][]*model.InscriptionBRC20Data{
+		{
+			inscribe(100, 0, testUserA, `{"p":"brc-20","op":"deploy","tick":"ordi","max":"1000","lim":"100"}`),
+		},
+		{
+			inscribe(101, 0, testUserA, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"100"}`),
+			inscribe(101, 1, testUserB, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"100"}`),
+		},
+		{
+			transferA,
+			inscribe(102, 1, testUserA, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"50"}`),
+		},
+		{
+			move(103, transferA, testUserB),
+		},
+		{
+			transferB,
+			inscribe(104, 1, testUserB, `{"p":"brc-20","op":"deploy","tick":"sats","max":"2000","lim":"100"}`),
+		},
+		{
+			move(105, transferB, testUserA),
+			inscribe(105, 1, testUserA, `{"p":"brc-20","op":"mint","tick":"sats","amt":"100"}`),
+		},
+	}
+	return blocks
+}
+
+func processTestBlocks(g *BRC20ModuleIndexer, blocks [][]*model.InscriptionBRC20Data) {
+	brc20Datas := make(chan interface{}, 64)
+	for _, block := range blocks {
+		for _, data := range block {
+			brc20Datas <- data
+		}
+	}
+	close(brc20Datas)
+	g.ProcessUpdateLatestBRC20Loop(brc20Datas, nil)
+}
+
+// digestEntries All entries of m and storage of g.
+func digestEntries[V any](g *BRC20ModuleIndexer, prefix string, m map[string]V, decode func([]byte) (V, error)) map[string]V {
+	all, err := allEntries(g, prefix, m, decode)
+	if err != nil {
+		panic(err)
+	}
+	return all
+}
+
+// stateDigest dumps the state in a stable text form.
+func stateDigest(g *BRC20ModuleIndexer) string {
+	var lines []string
+	allHistory, err := g.allHistoryList().All()
+	if err != nil {
+		panic(err)
+	}
+	lines = append(lines, fmt.Sprintf("best %d history %d/%d last %d all %v",
+		g.BestHeight, g.HistoryCount, len(g.HistoryData), g.LastHistoryHeight, allHistory))
+	firstHistory, err := g.allFirstHistory()
+	if err != nil {
+		panic(err)
+	}
+	for h, idx := range firstHistory {
+		lines = append(lines, fmt.Sprintf("first %d %d", h, idx))
+	}
+	for pk, userHistory := range digestEntries(g, STORAGE_PREFIX_USER_HIST, g.UserAllHistory, decodeGob[*model.BRC20UserHistory]) {
+		lines = append(lines, fmt.Sprintf("user %x %v", pk, userHistory.History))
+	}
+	for tick := range g.InscriptionsTickerInfoMap {
+		info, _, err := g.TickerInfo(tick)
+		if err != nil {
+			panic(err)
+		}
+		lines = append(lines, fmt.Sprintf("tick %s minted %s times %d data %s %v %v",
+			tick, info.Deploy.TotalMinted, info.Deploy.MintTimes, info.Deploy.Data.BRC20Minted, info.History, info.HistoryMint))
+	}
+	for pk, tokens := range digestEntries(g, STORAGE_PREFIX_BALANCE, g.UserTokensBalanceData, decodeGob[map[string]*model.BRC20TokenBalance]) {
+		for tick, balance := range tokens {
+			lines = append(lines, fmt.Sprintf("balance %x %s %s %s %d %v",
+				pk, tick, balance.AvailableBalance, balance.TransferableBalance, len(balance.ValidTransferMap), balance.History))
+		}
+	}
+	for tick := range g.TokenUsersBalanceData {
+		for pk := range g.TokenHolders(tick) {
+			lines = append(lines, fmt.Sprintf("holder %s %x", tick, pk))
+		}
+	}
+	for key := range digestEntries(g, STORAGE_PREFIX_TRANSFER, g.InscriptionsValidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo]) {
+		lines = append(lines, fmt.Sprintf("valid transfer %x", key))
+	}
+	for key := range digestEntries(g, STORAGE_PREFIX_INVALID_TRANSFER, g.InscriptionsInvalidTransferMap, decodeGob[*model.InscriptionBRC20TickInfo]) {
+		lines = append(lines, fmt.Sprintf("invalid transfer %x", key))
+	}
+	for key, height := range g.InscriptionsTransferRemoveMap {
+		lines = append(lines, fmt.Sprintf("remove transfer %x %d", key, height))
+	}
+	for key := range digestEntries(g, STORAGE_PREFIX_VALID, g.InscriptionsValidBRC20DataMap, decodeGob[*model.InscriptionBRC20InfoResp]) {
+		lines = append(lines, fmt.Sprintf("valid data %x", key))
+	}
+	for id, outcomes := range digestEntries(g, STORAGE_PREFIX_OUTCOME, g.InscriptionOutcomesMap, decodeGob[[]*model.BRC20InscriptionOutcome]) {
+		for _, outcome := range outcomes {
+			lines = append(lines, fmt.Sprintf("outcome %s %d %s", id, outcome.Height, outcome.Reason))
+		}
+	}
+	for tick, counts := range g.TickOutcomeCountMap {
+		for reason, n := range counts {
+			lines = append(lines, fmt.Sprintf("outcome count %s %s %d", tick, reason, n))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func newTestIndexer() *BRC20ModuleIndexer {
+	g := &BRC20ModuleIndexer{}
+	g.Init()
+	g.UndoDepth = constant.DEFAULT_UNDO_DEPTH
+	return g
+}
+
+func TestUndoDepthDefault(t *testing.T) {
+	g := &BRC20ModuleIndexer{}
+	g.Init()
+	if g.UndoDepth != 0 {
+		t.Fatalf("legacy undo depth %d, want disabled", g.UndoDepth)
+	}
+	for _, c := range []struct{ opt, want int }{{0, constant.DEFAULT_UNDO_DEPTH}, {-1, 0}, {3, 3}} {
+		if g := New(Options{UndoDepth: c.opt}); g.UndoDepth != c.want {
+			t.Fatalf("undo depth of option %d is %d, want %d", c.opt, g.UndoDepth, c.want)
+		}
+	}
+}
+
+func TestRollbackToHeight(t *testing.T) {
+	g := newTestIndexer()
+	processTestBlocks(g, testBlocks())
+
+	if err := g.RollbackToHeight(102); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	replay := newTestIndexer()
+	processTestBlocks(replay, testBlocks()[:3])
+	if got, want := stateDigest(g), stateDigest(replay); got != want {
+		t.Fatalf("state after rollback differs from replay\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// process the blocks again on the rolled back state
+	processTestBlocks(g, testBlocks()[3:])
+	full := newTestIndexer()
+	processTestBlocks(full, testBlocks())
+	if got, want := stateDigest(g), stateDigest(full); got != want {
+		t.Fatalf("state after reprocess differs from full run\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRollbackBelowUndoDepth(t *testing.T) {
+	g := newTestIndexer()
+	g.UndoDepth = 2
+	processTestBlocks(g, testBlocks())
+
+	if heights := g.UndoJournalHeights(); len(heights) != 2 || heights[0] != 104 {
+		t.Fatalf("unexpected journal heights: %v", heights)
+	}
+	if err := g.RollbackToHeight(102); err == nil {
+		t.Fatal("rollback beyond undo depth should fail")
+	}
+	if err := g.RollbackToHeight(103); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+}
+
+func TestRollbackModuleEntries(t *testing.T) {
+	g := newTestIndexer()
+	g.BestHeight = 100
+	moduleInfo := &model.BRC20ModuleSwapInfo{
+		ID:                                    "module",
+		CommitInvalidMap:                      make(map[string]struct{}, 0),
+		CommitIdMap:                           make(map[string]struct{}, 0),
+		CommitIdChainMap:                      make(map[string]struct{}, 0),
+		UsersTokenBalanceDataMap:              make(map[string]map[string]*model.BRC20ModuleTokenBalance, 0),
+		TokenUsersBalanceDataMap:              make(map[string]map[string]*model.BRC20ModuleTokenBalance, 0),
+		LPTokenUsersBalanceMap:                make(map[string]map[string]*decimal.Decimal, 0),
+		LPTokenUsersBalanceUpdatedMap:         make(map[string]struct{}, 0),
+		UsersLPTokenBalanceMap:                make(map[string]map[string]*decimal.Decimal, 0),
+		SwapPoolTotalBalanceDataMap:           make(map[string]*model.BRC20ModulePoolTotalBalance, 0),
+		ConditionalApproveStateBalanceDataMap: make(map[string]*model.BRC20ModuleConditionalApproveStateBalance, 0),
+	}
+	g.ModulesInfoMap[moduleInfo.ID] = moduleInfo
+	g.getModuleUserTokenBalance(moduleInfo, "ordi", testUserA).SwapAccountBalance = decimal.NewDecimal(50, 0)
+	moduleInfo.LPTokenUsersBalanceMap["ordi/sats"] = make(map[string]*decimal.Decimal, 0)
+	moduleInfo.SwapPoolTotalBalanceDataMap["ordi/sats"] = &model.BRC20ModulePoolTotalBalance{
+		Tick:        [2]string{"ordi", "sats"},
+		TickBalance: [2]*decimal.Decimal{decimal.NewDecimal(10, 0), decimal.NewDecimal(20, 0)},
+		LpBalance:   decimal.NewDecimal(5, 0),
+	}
+	before := g.DeepCopy()
+
+	g.BeginBlockUndo(101)
+	g.BestHeight = 101
+	g.undoModule(moduleInfo)
+	g.getModuleUserTokenBalance(moduleInfo, "ordi", testUserA).SwapAccountBalance = decimal.NewDecimal(40, 0)
+	g.getModuleUserTokenBalance(moduleInfo, "sats", testUserB).SwapAccountBalance = decimal.NewDecimal(7, 0)
+	pool := moduleInfo.SwapPoolTotalBalanceDataMap["ordi/sats"]
+	g.undoModulePool(pool)
+	pool.TickBalance[0] = decimal.NewDecimal(20, 0)
+	pool.LpBalance = decimal.NewDecimal(8, 0)
+	g.setModuleLPBalance(moduleInfo, "ordi/sats", testUserB, decimal.NewDecimal(3, 0))
+	undoMapEntry(g, moduleInfo.CommitIdMap, "commit")
+	moduleInfo.CommitIdMap["commit"] = struct{}{}
+	moduleInfo.History = append(moduleInfo.History, &model.BRC20ModuleHistory{})
+	if len(DiffStates(before, g)) == 0 {
+		t.Fatal("module changes not seen")
+	}
+
+	if err := g.RollbackToHeight(100); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+	before.BestHeight = 100
+	if diffs := DiffStates(before, g); len(diffs) != 0 {
+		t.Fatalf("module after rollback differs: %v", diffs)
+	}
+	if len(moduleInfo.History) != 0 || len(moduleInfo.TokenUsersBalanceDataMap["sats"]) != 0 {
+		t.Fatalf("module history or holders not rolled back")
+	}
+}
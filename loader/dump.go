@@ -8,14 +8,14 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/unisat-wallet/libbrc20-indexer/conf"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	"github.com/unisat-wallet/libbrc20-indexer/decimal"
 	"github.com/unisat-wallet/libbrc20-indexer/model"
 	"github.com/unisat-wallet/libbrc20-indexer/utils"
 )
 
-func DumpBRC20InputData(fname string, brc20Datas chan interface{}, hexBody bool) {
+func DumpBRC20InputData(fname string, brc20Datas chan interface{}, hexBody bool, netParams *chaincfg.Params) {
 	file, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
 	if err != nil {
 		log.Fatalf("open block index file failed, %s", err)
@@ -32,7 +32,7 @@ func DumpBRC20InputData(fname string, brc20Datas chan interface{}, hexBody bool)
 			address = hex.EncodeToString([]byte(data.PkScript))
 		} else {
 			body = strings.ReplaceAll(string(data.ContentBody), "\n", " ")
-			address, err = utils.GetAddressFromScript([]byte(data.PkScript), conf.GlobalNetParams)
+			address, err = utils.GetAddressFromScript([]byte(data.PkScript), netParams)
 			if err != nil {
 				address = hex.EncodeToString([]byte(data.PkScript))
 			}
@@ -61,6 +61,26 @@ func DumpTickerInfoMap(fname string,
 	inscriptionsTickerInfoMap map[string]*model.BRC20TokenInfo,
 	userTokensBalanceData map[string]map[string]*model.BRC20TokenBalance,
 	tokenUsersBalanceData map[string]map[string]*model.BRC20TokenBalance,
+	netParams *chaincfg.Params,
+) {
+	DumpTickerInfoMapByHistory(fname,
+		func(idx uint64) ([]byte, bool) {
+			return historyData[idx], true
+		},
+		inscriptionsTickerInfoMap,
+		userTokensBalanceData,
+		tokenUsersBalanceData,
+		netParams,
+	)
+}
+
+// DumpTickerInfoMapByHistory Same as DumpTickerInfoMap, history read by getHistory, not all in memory.
+func DumpTickerInfoMapByHistory(fname string,
+	getHistory func(idx uint64) ([]byte, bool),
+	inscriptionsTickerInfoMap map[string]*model.BRC20TokenInfo,
+	userTokensBalanceData map[string]map[string]*model.BRC20TokenBalance,
+	tokenUsersBalanceData map[string]map[string]*model.BRC20TokenBalance,
+	netParams *chaincfg.Params,
 ) {
 
 	file, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
@@ -82,7 +102,7 @@ func DumpTickerInfoMap(fname string,
 		info := inscriptionsTickerInfoMap[ticker]
 		nValid := 0
 		for _, hIdx := range info.History {
-			buf := historyData[hIdx]
+			buf, _ := getHistory(hIdx)
 			h := &model.BRC20History{}
 			h.Unmarshal(buf)
 
@@ -101,7 +121,7 @@ func DumpTickerInfoMap(fname string,
 
 		// history
 		for _, hIdx := range info.History {
-			buf := historyData[hIdx]
+			buf, _ := getHistory(hIdx)
 			h := &model.BRC20History{}
 			h.Unmarshal(buf)
 
@@ -109,12 +129,12 @@ func DumpTickerInfoMap(fname string,
 				continue
 			}
 
-			addressFrom, err := utils.GetAddressFromScript([]byte(h.PkScriptFrom), conf.GlobalNetParams)
+			addressFrom, err := utils.GetAddressFromScript([]byte(h.PkScriptFrom), netParams)
 			if err != nil {
 				addressFrom = hex.EncodeToString([]byte(h.PkScriptFrom))
 			}
 
-			addressTo, err := utils.GetAddressFromScript([]byte(h.PkScriptTo), conf.GlobalNetParams)
+			addressTo, err := utils.GetAddressFromScript([]byte(h.PkScriptTo), netParams)
 			if err != nil {
 				addressTo = hex.EncodeToString([]byte(h.PkScriptTo))
 			}
@@ -143,7 +163,7 @@ func DumpTickerInfoMap(fname string,
 		for _, holder := range allHoldersPkScript {
 			balanceData := tokenUsersBalanceData[ticker][holder]
 
-			address, err := utils.GetAddressFromScript([]byte(balanceData.PkScript), conf.GlobalNetParams)
+			address, err := utils.GetAddressFromScript([]byte(balanceData.PkScript), netParams)
 			if err != nil {
 				address = hex.EncodeToString([]byte(balanceData.PkScript))
 			}
@@ -161,6 +181,7 @@ func DumpTickerInfoMap(fname string,
 
 func DumpModuleInfoMap(fname string,
 	modulesInfoMap map[string]*model.BRC20ModuleSwapInfo,
+	netParams *chaincfg.Params,
 ) {
 	file, err := os.OpenFile(fname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
 	if err != nil {
@@ -199,14 +220,15 @@ func DumpModuleInfoMap(fname string,
 			len(info.UsersLPTokenBalanceMap),
 		)
 
-		DumpModuleTickInfoMap(file, info.ConditionalApproveStateBalanceDataMap, info.TokenUsersBalanceDataMap, info.UsersTokenBalanceDataMap)
+		DumpModuleTickInfoMap(file, info.ConditionalApproveStateBalanceDataMap, info.TokenUsersBalanceDataMap, info.UsersTokenBalanceDataMap, netParams)
 
-		DumpModuleSwapInfoMap(file, info.SwapPoolTotalBalanceDataMap, info.LPTokenUsersBalanceMap, info.UsersLPTokenBalanceMap)
+		DumpModuleSwapInfoMap(file, info.SwapPoolTotalBalanceDataMap, info.LPTokenUsersBalanceMap, info.UsersLPTokenBalanceMap, netParams)
 	}
 }
 
 func DumpModuleTickInfoMap(file *os.File, condStateBalanceDataMap map[string]*model.BRC20ModuleConditionalApproveStateBalance,
 	inscriptionsTickerInfoMap, userTokensBalanceData map[string]map[string]*model.BRC20ModuleTokenBalance,
+	netParams *chaincfg.Params,
 ) {
 
 	var allTickers []string
@@ -249,7 +271,7 @@ func DumpModuleTickInfoMap(file *os.File, condStateBalanceDataMap map[string]*mo
 		for _, holder := range allHoldersPkScript {
 			balanceData := holdersMap[holder]
 
-			address, err := utils.GetAddressFromScript([]byte(balanceData.PkScript), conf.GlobalNetParams)
+			address, err := utils.GetAddressFromScript([]byte(balanceData.PkScript), netParams)
 			if err != nil {
 				address = hex.EncodeToString([]byte(balanceData.PkScript))
 			}
@@ -302,7 +324,8 @@ func DumpModuleTickInfoMap(file *os.File, condStateBalanceDataMap map[string]*mo
 
 func DumpModuleSwapInfoMap(file *os.File,
 	swapPoolTotalBalanceDataMap map[string]*model.BRC20ModulePoolTotalBalance,
-	inscriptionsTickerInfoMap, userTokensBalanceData map[string]map[string]*decimal.Decimal) {
+	inscriptionsTickerInfoMap, userTokensBalanceData map[string]map[string]*decimal.Decimal,
+	netParams *chaincfg.Params) {
 
 	var allTickers []string
 	for ticker := range inscriptionsTickerInfoMap {
@@ -340,7 +363,7 @@ func DumpModuleSwapInfoMap(file *os.File,
 		for _, holder := range allHoldersPkScript {
 			balanceData := holdersMap[holder]
 
-			address, err := utils.GetAddressFromScript([]byte(holder), conf.GlobalNetParams)
+			address, err := utils.GetAddressFromScript([]byte(holder), netParams)
 			if err != nil {
 				address = hex.EncodeToString([]byte(holder))
 			}
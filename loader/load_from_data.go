@@ -24,102 +24,112 @@ func LoadBRC20InputData(fname string, brc20Datas chan interface{}) error {
 	buf := make([]byte, max)
 	scanner.Buffer(buf, max)
 
+	lineNum := 0
 	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Split(line, " ")
-
-		if len(fields) != 13 {
-			return fmt.Errorf("invalid data format")
+		lineNum++
+		data, err := ParseBRC20InputLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
 		}
+		brc20Datas <- data
+	}
 
-		var data model.InscriptionBRC20Data
+	if err := scanner.Err(); err != nil {
+		return err
+	}
 
-		sequence, err := strconv.ParseUint(fields[0], 10, 16)
-		if err != nil {
-			return err
-		}
-		data.Sequence = uint16(sequence)
-		data.IsTransfer = (data.Sequence > 0)
+	return nil
+}
 
-		txid, err := hex.DecodeString(fields[1])
-		if err != nil {
-			return err
-		}
-		data.TxId = string(utils.ReverseBytes([]byte(txid)))
+// ParseBRC20InputLine Parse one line of input data.
+func ParseBRC20InputLine(line string) (*model.InscriptionBRC20Data, error) {
+	fields := strings.Split(line, " ")
 
-		idx, err := strconv.ParseUint(fields[2], 10, 32)
-		if err != nil {
-			return err
-		}
-		data.Idx = uint32(idx)
+	if len(fields) != 13 {
+		return nil, fmt.Errorf("invalid data format")
+	}
 
-		vout, err := strconv.ParseUint(fields[3], 10, 32)
-		if err != nil {
-			return err
-		}
-		data.Vout = uint32(vout)
+	var data model.InscriptionBRC20Data
 
-		offset, err := strconv.ParseUint(fields[4], 10, 64)
-		if err != nil {
-			return err
-		}
-		data.Offset = uint64(offset)
+	sequence, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	data.Sequence = uint16(sequence)
+	data.IsTransfer = (data.Sequence > 0)
 
-		satoshi, err := strconv.ParseUint(fields[5], 10, 64)
-		if err != nil {
-			return err
-		}
-		data.Satoshi = uint64(satoshi)
+	txid, err := hex.DecodeString(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	data.TxId = string(utils.ReverseBytes([]byte(txid)))
 
-		pkScript, err := hex.DecodeString(fields[6])
-		if err != nil {
-			return err
-		}
-		data.PkScript = string(pkScript)
+	idx, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	data.Idx = uint32(idx)
 
-		inscriptionNumber, err := strconv.ParseInt(fields[7], 10, 64)
-		if err != nil {
-			return err
-		}
-		data.InscriptionNumber = int64(inscriptionNumber)
+	vout, err := strconv.ParseUint(fields[3], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	data.Vout = uint32(vout)
 
-		content, err := hex.DecodeString(fields[8])
-		if err != nil {
-			return err
-		}
-		data.ContentBody = content
+	offset, err := strconv.ParseUint(fields[4], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	data.Offset = uint64(offset)
 
-		createIdxKey, err := hex.DecodeString(fields[9])
-		if err != nil {
-			return err
-		}
+	satoshi, err := strconv.ParseUint(fields[5], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	data.Satoshi = uint64(satoshi)
 
-		data.CreateIdxKey = string(createIdxKey)
+	pkScript, err := hex.DecodeString(fields[6])
+	if err != nil {
+		return nil, err
+	}
+	data.PkScript = string(pkScript)
 
-		height, err := strconv.ParseUint(fields[10], 10, 32)
-		if err != nil {
-			return err
-		}
-		data.Height = uint32(height)
+	inscriptionNumber, err := strconv.ParseInt(fields[7], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	data.InscriptionNumber = int64(inscriptionNumber)
 
-		txIdx, err := strconv.ParseUint(fields[11], 10, 32)
-		if err != nil {
-			return err
-		}
-		data.TxIdx = uint32(txIdx)
+	content, err := hex.DecodeString(fields[8])
+	if err != nil {
+		return nil, err
+	}
+	data.ContentBody = content
 
-		blockTime, err := strconv.ParseUint(fields[12], 10, 32)
-		if err != nil {
-			return err
-		}
-		data.BlockTime = uint32(blockTime)
+	createIdxKey, err := hex.DecodeString(fields[9])
+	if err != nil {
+		return nil, err
+	}
+
+	data.CreateIdxKey = string(createIdxKey)
 
-		brc20Datas <- &data
+	height, err := strconv.ParseUint(fields[10], 10, 32)
+	if err != nil {
+		return nil, err
 	}
+	data.Height = uint32(height)
 
-	if err := scanner.Err(); err != nil {
-		return err
+	txIdx, err := strconv.ParseUint(fields[11], 10, 32)
+	if err != nil {
+		return nil, err
 	}
+	data.TxIdx = uint32(txIdx)
 
-	return nil
+	blockTime, err := strconv.ParseUint(fields[12], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	data.BlockTime = uint32(blockTime)
+
+	return &data, nil
 }
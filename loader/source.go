@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+)
+
+// FileSource Read input data file block by block.
+type FileSource struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	lineNum int
+
+	next *model.InscriptionBRC20Data // first event of next block
+}
+
+func NewFileSource(fname string) (*FileSource, error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	max := 128 * 1024 * 1024
+	buf := make([]byte, max)
+	scanner.Buffer(buf, max)
+
+	return &FileSource{file: file, scanner: scanner}, nil
+}
+
+// NextBlock Events of the next block, io.EOF at end of file. Cancel is checked before the block
+// only, a block started is always read whole.
+func (s *FileSource) NextBlock(ctx context.Context) (block []*model.InscriptionBRC20Data, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.next != nil {
+		block = append(block, s.next)
+		s.next = nil
+	}
+
+	for s.scanner.Scan() {
+		s.lineNum++
+		data, err := ParseBRC20InputLine(s.scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", s.file.Name(), s.lineNum, err)
+		}
+		if len(block) > 0 && block[0].Height != data.Height {
+			s.next = data
+			return block, nil
+		}
+		block = append(block, data)
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s line %d: %w", s.file.Name(), s.lineNum, err)
+	}
+
+	if len(block) == 0 {
+		return nil, io.EOF
+	}
+	return block, nil
+}
+
+func (s *FileSource) Close() error {
+	return s.file.Close()
+}
+
+// DataSource Events already in memory, e.g. from LoadBRC20InputJsonData.
+type DataSource struct {
+	datas []*model.InscriptionBRC20Data
+}
+
+func NewDataSource(datas []*model.InscriptionBRC20Data) *DataSource {
+	return &DataSource{datas: datas}
+}
+
+// NextBlock Events of the next block, io.EOF at end of data.
+func (s *DataSource) NextBlock(ctx context.Context) (block []*model.InscriptionBRC20Data, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.datas) == 0 {
+		return nil, io.EOF
+	}
+
+	n := 1
+	for n < len(s.datas) && s.datas[n].Height == s.datas[0].Height {
+		n++
+	}
+	block, s.datas = s.datas[:n], s.datas[n:]
+	return block, nil
+}
@@ -2,7 +2,9 @@ package model
 
 import (
 	"encoding/binary"
+	"strconv"
 
+	"github.com/unisat-wallet/libbrc20-indexer/constant"
 	scriptDecoder "github.com/unisat-wallet/libbrc20-indexer/utils/script"
 )
 
@@ -25,6 +27,14 @@ type BRC20HistoryBase struct {
 	BlockTime uint32
 }
 
+// TypeName Name of type, see constant.BRC20_HISTORY_TYPE_NAMES.
+func (h *BRC20HistoryBase) TypeName() string {
+	if int(h.Type) < len(constant.BRC20_HISTORY_TYPE_NAMES) {
+		return constant.BRC20_HISTORY_TYPE_NAMES[h.Type]
+	}
+	return strconv.Itoa(int(h.Type))
+}
+
 // history
 type BRC20History struct {
 	BRC20HistoryBase
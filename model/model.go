@@ -70,6 +70,7 @@ type InscriptionBRC20InfoResp struct {
 type InscriptionBRC20ProtocalContent struct {
 	Proto     string `json:"p,omitempty"`
 	Operation string `json:"op,omitempty"`
+	BRC20Tick string `json:"tick,omitempty"`
 }
 
 func (body *InscriptionBRC20ProtocalContent) Unmarshal(contentBody []byte) (err error) {
@@ -83,6 +84,9 @@ func (body *InscriptionBRC20ProtocalContent) Unmarshal(contentBody []byte) (err
 	if v, ok := bodyMap["op"].(string); ok {
 		body.Operation = v
 	}
+	if v, ok := bodyMap["tick"].(string); ok {
+		body.BRC20Tick = v
+	}
 	return nil
 }
 
@@ -174,11 +178,52 @@ type BRC20TokenInfo struct {
 	Ticker string
 	Deploy *InscriptionBRC20TickInfo
 
-	History                 []uint32
-	HistoryMint             []uint32
-	HistoryInscribeTransfer []uint32
-	HistoryTransfer         []uint32
-	HistoryWithdraw         []uint32 // fixme
+	History                 []uint64
+	HistoryMint             []uint64
+	HistoryInscribeTransfer []uint64
+	HistoryTransfer         []uint64
+	HistoryWithdraw         []uint64 // fixme
+
+	// lists in storage before the ones above, same order
+	HistoryStored [5]HistoryListStored
+}
+
+// HistoryListStored Part of a list kept in storage by position, from Start to Count. The list in
+// memory follows it.
+type HistoryListStored struct {
+	Start uint64 // positions before it are pruned
+	Count uint64
+}
+
+// HistoryLen Count of History, in storage and memory.
+func (in *BRC20TokenInfo) HistoryLen() int {
+	return int(in.HistoryStored[0].Count-in.HistoryStored[0].Start) + len(in.History)
+}
+
+func (in *BRC20TokenInfo) DeepCopy() (tinfo *BRC20TokenInfo) {
+	tinfo = &BRC20TokenInfo{
+		UpdateHeight:  in.UpdateHeight,
+		Ticker:        in.Ticker,
+		Deploy:        in.Deploy.DeepCopy(),
+		HistoryStored: in.HistoryStored,
+	}
+
+	// history
+	tinfo.History = make([]uint64, len(in.History))
+	copy(tinfo.History, in.History)
+
+	tinfo.HistoryMint = make([]uint64, len(in.HistoryMint))
+	copy(tinfo.HistoryMint, in.HistoryMint)
+
+	tinfo.HistoryInscribeTransfer = make([]uint64, len(in.HistoryInscribeTransfer))
+	copy(tinfo.HistoryInscribeTransfer, in.HistoryInscribeTransfer)
+
+	tinfo.HistoryTransfer = make([]uint64, len(in.HistoryTransfer))
+	copy(tinfo.HistoryTransfer, in.HistoryTransfer)
+
+	tinfo.HistoryWithdraw = make([]uint64, len(in.HistoryWithdraw))
+	copy(tinfo.HistoryWithdraw, in.HistoryWithdraw)
+	return tinfo
 }
 
 type InscriptionBRC20TransferInfo struct {
@@ -239,6 +284,7 @@ func (in *InscriptionBRC20TickInfo) DeepCopy() (copy *InscriptionBRC20TickInfo)
 		SelfMint: in.SelfMint,
 
 		Data:    in.Data,
+		Meta:    in.Meta,
 		Decimal: in.Decimal,
 
 		TxId:   in.TxId,
@@ -273,6 +319,11 @@ func (in *InscriptionBRC20TickInfo) DeepCopy() (copy *InscriptionBRC20TickInfo)
 		InscriptionNumberStart: in.InscriptionNumberStart,
 		InscriptionNumberEnd:   in.InscriptionNumberEnd,
 	}
+	// minted in data changes with state
+	if in.Data != nil {
+		data := *in.Data
+		copy.Data = &data
+	}
 	return copy
 }
 
@@ -304,7 +355,7 @@ func NewInscriptionBRC20TickInfo(tick, operation string, data *InscriptionBRC20D
 
 // all history for user
 type BRC20UserHistory struct {
-	History []uint32
+	History []uint64
 }
 
 // state of address for each tick, (balance and history)
@@ -318,11 +369,12 @@ type BRC20TokenBalance struct {
 	TransferableBalance  *decimal.Decimal
 	ValidTransferMap     map[string]*InscriptionBRC20TickInfo
 
-	History                 []uint32
-	HistoryMint             []uint32
-	HistoryInscribeTransfer []uint32
-	HistorySend             []uint32
-	HistoryReceive          []uint32
+	History                 []uint64
+	HistoryMint             []uint64
+	HistoryInscribeTransfer []uint64
+	HistorySend             []uint64
+	HistoryReceive          []uint64
+	HistoryPruned           uint64 // count of History dropped by pruning
 }
 
 func (bal *BRC20TokenBalance) OverallBalance() *decimal.Decimal {
@@ -331,11 +383,13 @@ func (bal *BRC20TokenBalance) OverallBalance() *decimal.Decimal {
 
 func (in *BRC20TokenBalance) DeepCopy() (tb *BRC20TokenBalance) {
 	tb = &BRC20TokenBalance{
+		UpdateHeight:         in.UpdateHeight,
 		Ticker:               in.Ticker,
 		PkScript:             in.PkScript,
 		AvailableBalanceSafe: decimal.NewDecimalCopy(in.AvailableBalanceSafe),
 		AvailableBalance:     decimal.NewDecimalCopy(in.AvailableBalance),
 		TransferableBalance:  decimal.NewDecimalCopy(in.TransferableBalance),
+		HistoryPruned:        in.HistoryPruned,
 	}
 
 	tb.ValidTransferMap = make(map[string]*InscriptionBRC20TickInfo, len(in.ValidTransferMap))
@@ -343,19 +397,19 @@ func (in *BRC20TokenBalance) DeepCopy() (tb *BRC20TokenBalance) {
 		tb.ValidTransferMap[k] = v.DeepCopy()
 	}
 
-	tb.History = make([]uint32, len(in.History))
+	tb.History = make([]uint64, len(in.History))
 	copy(tb.History, in.History)
 
-	tb.HistoryMint = make([]uint32, len(in.HistoryMint))
+	tb.HistoryMint = make([]uint64, len(in.HistoryMint))
 	copy(tb.HistoryMint, in.HistoryMint)
 
-	tb.HistoryInscribeTransfer = make([]uint32, len(in.HistoryInscribeTransfer))
+	tb.HistoryInscribeTransfer = make([]uint64, len(in.HistoryInscribeTransfer))
 	copy(tb.HistoryInscribeTransfer, in.HistoryInscribeTransfer)
 
-	tb.HistorySend = make([]uint32, len(in.HistorySend))
+	tb.HistorySend = make([]uint64, len(in.HistorySend))
 	copy(tb.HistorySend, in.HistorySend)
 
-	tb.HistoryReceive = make([]uint32, len(in.HistoryReceive))
+	tb.HistoryReceive = make([]uint64, len(in.HistoryReceive))
 	copy(tb.HistoryReceive, in.HistoryReceive)
 	return tb
 }
@@ -0,0 +1,14 @@
+package model
+
+// outcome of an inscription event seen by the indexer
+type BRC20InscriptionOutcome struct {
+	Height     uint32
+	IsTransfer bool // move of inscription
+	Proto      string
+	Operation  string
+	Tick       string
+
+	Valid   bool
+	Reason  string // reason code, valid if accepted
+	Message string // detail of reject
+}
@@ -14,7 +14,8 @@ type BRC20ModuleSwapInfoStore struct {
 	FeeRateSwap string
 	GasTick     string
 
-	History []*BRC20ModuleHistory // history for deploy, deposit, commit, quit
+	History       []*BRC20ModuleHistory // history for deploy, deposit, commit, quit
+	HistoryStored HistoryListStored     // history in storage before History
 
 	// runtime for commit
 	CommitInvalidMap map[string]struct{} // All invalid create commits
@@ -207,7 +207,8 @@ type BRC20ModuleSwapInfo struct {
 	FeeRateSwap string
 	GasTick     string
 
-	History []*BRC20ModuleHistory // history for deploy, deposit, commit, quit
+	History       []*BRC20ModuleHistory // history for deploy, deposit, commit, quit
+	HistoryStored HistoryListStored     // history in storage before History
 
 	// runtime for commit
 	CommitInvalidMap map[string]struct{} // All invalid create commits
@@ -242,6 +243,7 @@ type BRC20ModuleSwapInfo struct {
 
 func (m *BRC20ModuleSwapInfo) DeepCopy() (copy *BRC20ModuleSwapInfo) {
 	copy = &BRC20ModuleSwapInfo{
+		UpdateHeight:      m.UpdateHeight,
 		ID:                m.ID,
 		Name:              m.Name,
 		DeployerPkScript:  m.DeployerPkScript,  // deployer
@@ -252,7 +254,8 @@ func (m *BRC20ModuleSwapInfo) DeepCopy() (copy *BRC20ModuleSwapInfo) {
 		FeeRateSwap: m.FeeRateSwap,
 		GasTick:     m.GasTick,
 
-		History: make([]*BRC20ModuleHistory, 0),
+		History:       make([]*BRC20ModuleHistory, 0),
+		HistoryStored: m.HistoryStored,
 
 		// runtime for commit
 		CommitInvalidMap: make(map[string]struct{}, 0),
@@ -298,6 +301,9 @@ func (m *BRC20ModuleSwapInfo) DeepCopy() (copy *BRC20ModuleSwapInfo) {
 	for k := range m.CommitIdMap {
 		copy.CommitIdMap[k] = struct{}{}
 	}
+	for k := range m.LPTokenUsersBalanceUpdatedMap {
+		copy.LPTokenUsersBalanceUpdatedMap[k] = struct{}{}
+	}
 
 	// user/tick: balance
 	for address, dataMap := range m.UsersTokenBalanceDataMap {
@@ -644,6 +650,8 @@ func (bal *BRC20ModuleTokenBalance) ModuleBalance() *decimal.Decimal {
 
 func (in *BRC20ModuleTokenBalance) DeepCopy() *BRC20ModuleTokenBalance {
 	tb := &BRC20ModuleTokenBalance{
+		UpdateHeight: in.UpdateHeight,
+
 		Tick:     in.Tick,
 		PkScript: in.PkScript,
 
@@ -721,6 +729,8 @@ type BRC20ModulePoolTotalBalance struct {
 
 func (in *BRC20ModulePoolTotalBalance) DeepCopy() *BRC20ModulePoolTotalBalance {
 	tb := &BRC20ModulePoolTotalBalance{
+		UpdateHeight: in.UpdateHeight,
+
 		Tick:        in.Tick,
 		TickBalance: in.TickBalance,
 
@@ -768,6 +778,7 @@ type InscriptionBRC20SwapConditionalApproveInfo struct {
 
 func (d *InscriptionBRC20SwapConditionalApproveInfo) DeepCopy() (copy *InscriptionBRC20SwapConditionalApproveInfo) {
 	copy = &InscriptionBRC20SwapConditionalApproveInfo{
+		UpdateHeight:      d.UpdateHeight,
 		Module:            d.Module,
 		Tick:              d.Tick,
 		Amount:            decimal.NewDecimalCopy(d.Amount),  // maybe no need copy
@@ -0,0 +1,441 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: brc20.proto
+
+package brc20pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BRC20Indexer_GetStatus_FullMethodName        = "/brc20.v1.BRC20Indexer/GetStatus"
+	BRC20Indexer_GetToken_FullMethodName         = "/brc20.v1.BRC20Indexer/GetToken"
+	BRC20Indexer_GetBalance_FullMethodName       = "/brc20.v1.BRC20Indexer/GetBalance"
+	BRC20Indexer_GetBalances_FullMethodName      = "/brc20.v1.BRC20Indexer/GetBalances"
+	BRC20Indexer_GetHolders_FullMethodName       = "/brc20.v1.BRC20Indexer/GetHolders"
+	BRC20Indexer_GetHistory_FullMethodName       = "/brc20.v1.BRC20Indexer/GetHistory"
+	BRC20Indexer_GetModuleAccount_FullMethodName = "/brc20.v1.BRC20Indexer/GetModuleAccount"
+	BRC20Indexer_GetPool_FullMethodName          = "/brc20.v1.BRC20Indexer/GetPool"
+	BRC20Indexer_StreamBlocks_FullMethodName     = "/brc20.v1.BRC20Indexer/StreamBlocks"
+)
+
+// BRC20IndexerClient is the client API for BRC20Indexer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BRC20Indexer Read-only queries of confirmed state, and stream of blocks processed.
+// Amounts are decimal strings. Addresses are of the network, or hex of pkscript if not standard.
+type BRC20IndexerClient interface {
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*Token, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error)
+	GetBalances(ctx context.Context, in *GetBalancesRequest, opts ...grpc.CallOption) (*BalanceList, error)
+	// holders by overall balance desc
+	GetHolders(ctx context.Context, in *GetHoldersRequest, opts ...grpc.CallOption) (*BalanceList, error)
+	// history of address, in order of index
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*HistoryList, error)
+	GetModuleAccount(ctx context.Context, in *GetModuleAccountRequest, opts ...grpc.CallOption) (*ModuleAccount, error)
+	GetPool(ctx context.Context, in *GetPoolRequest, opts ...grpc.CallOption) (*Pool, error)
+	// blocks processed from now on, with state changes of each block
+	StreamBlocks(ctx context.Context, in *StreamBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlockEvent], error)
+}
+
+type bRC20IndexerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBRC20IndexerClient(cc grpc.ClientConnInterface) BRC20IndexerClient {
+	return &bRC20IndexerClient{cc}
+}
+
+func (c *bRC20IndexerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*Token, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Token)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetBalances(ctx context.Context, in *GetBalancesRequest, opts ...grpc.CallOption) (*BalanceList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceList)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetBalances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetHolders(ctx context.Context, in *GetHoldersRequest, opts ...grpc.CallOption) (*BalanceList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceList)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetHolders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*HistoryList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryList)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetModuleAccount(ctx context.Context, in *GetModuleAccountRequest, opts ...grpc.CallOption) (*ModuleAccount, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModuleAccount)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetModuleAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) GetPool(ctx context.Context, in *GetPoolRequest, opts ...grpc.CallOption) (*Pool, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pool)
+	err := c.cc.Invoke(ctx, BRC20Indexer_GetPool_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bRC20IndexerClient) StreamBlocks(ctx context.Context, in *StreamBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlockEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BRC20Indexer_ServiceDesc.Streams[0], BRC20Indexer_StreamBlocks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamBlocksRequest, BlockEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BRC20Indexer_StreamBlocksClient = grpc.ServerStreamingClient[BlockEvent]
+
+// BRC20IndexerServer is the server API for BRC20Indexer service.
+// All implementations must embed UnimplementedBRC20IndexerServer
+// for forward compatibility.
+//
+// BRC20Indexer Read-only queries of confirmed state, and stream of blocks processed.
+// Amounts are decimal strings. Addresses are of the network, or hex of pkscript if not standard.
+type BRC20IndexerServer interface {
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	GetToken(context.Context, *GetTokenRequest) (*Token, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*Balance, error)
+	GetBalances(context.Context, *GetBalancesRequest) (*BalanceList, error)
+	// holders by overall balance desc
+	GetHolders(context.Context, *GetHoldersRequest) (*BalanceList, error)
+	// history of address, in order of index
+	GetHistory(context.Context, *GetHistoryRequest) (*HistoryList, error)
+	GetModuleAccount(context.Context, *GetModuleAccountRequest) (*ModuleAccount, error)
+	GetPool(context.Context, *GetPoolRequest) (*Pool, error)
+	// blocks processed from now on, with state changes of each block
+	StreamBlocks(*StreamBlocksRequest, grpc.ServerStreamingServer[BlockEvent]) error
+	mustEmbedUnimplementedBRC20IndexerServer()
+}
+
+// UnimplementedBRC20IndexerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBRC20IndexerServer struct{}
+
+func (UnimplementedBRC20IndexerServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetToken(context.Context, *GetTokenRequest) (*Token, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetToken not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetBalance(context.Context, *GetBalanceRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetBalances(context.Context, *GetBalancesRequest) (*BalanceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalances not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetHolders(context.Context, *GetHoldersRequest) (*BalanceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHolders not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetHistory(context.Context, *GetHistoryRequest) (*HistoryList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetModuleAccount(context.Context, *GetModuleAccountRequest) (*ModuleAccount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModuleAccount not implemented")
+}
+func (UnimplementedBRC20IndexerServer) GetPool(context.Context, *GetPoolRequest) (*Pool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPool not implemented")
+}
+func (UnimplementedBRC20IndexerServer) StreamBlocks(*StreamBlocksRequest, grpc.ServerStreamingServer[BlockEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBlocks not implemented")
+}
+func (UnimplementedBRC20IndexerServer) mustEmbedUnimplementedBRC20IndexerServer() {}
+func (UnimplementedBRC20IndexerServer) testEmbeddedByValue()                      {}
+
+// UnsafeBRC20IndexerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BRC20IndexerServer will
+// result in compilation errors.
+type UnsafeBRC20IndexerServer interface {
+	mustEmbedUnimplementedBRC20IndexerServer()
+}
+
+func RegisterBRC20IndexerServer(s grpc.ServiceRegistrar, srv BRC20IndexerServer) {
+	// If the following call pancis, it indicates UnimplementedBRC20IndexerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BRC20Indexer_ServiceDesc, srv)
+}
+
+func _BRC20Indexer_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetToken(ctx, req.(*GetTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetBalances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetBalances(ctx, req.(*GetBalancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetHolders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHoldersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetHolders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetHolders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetHolders(ctx, req.(*GetHoldersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetModuleAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetModuleAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetModuleAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetModuleAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetModuleAccount(ctx, req.(*GetModuleAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_GetPool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPoolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BRC20IndexerServer).GetPool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BRC20Indexer_GetPool_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BRC20IndexerServer).GetPool(ctx, req.(*GetPoolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BRC20Indexer_StreamBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BRC20IndexerServer).StreamBlocks(m, &grpc.GenericServerStream[StreamBlocksRequest, BlockEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BRC20Indexer_StreamBlocksServer = grpc.ServerStreamingServer[BlockEvent]
+
+// BRC20Indexer_ServiceDesc is the grpc.ServiceDesc for BRC20Indexer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BRC20Indexer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "brc20.v1.BRC20Indexer",
+	HandlerType: (*BRC20IndexerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _BRC20Indexer_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetToken",
+			Handler:    _BRC20Indexer_GetToken_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _BRC20Indexer_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetBalances",
+			Handler:    _BRC20Indexer_GetBalances_Handler,
+		},
+		{
+			MethodName: "GetHolders",
+			Handler:    _BRC20Indexer_GetHolders_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _BRC20Indexer_GetHistory_Handler,
+		},
+		{
+			MethodName: "GetModuleAccount",
+			Handler:    _BRC20Indexer_GetModuleAccount_Handler,
+		},
+		{
+			MethodName: "GetPool",
+			Handler:    _BRC20Indexer_GetPool_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBlocks",
+			Handler:       _BRC20Indexer_StreamBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "brc20.proto",
+}
@@ -0,0 +1,317 @@
+package rpc
+
+//go:generate protoc -I brc20pb --go_out=brc20pb --go_opt=paths=source_relative --go-grpc_out=brc20pb --go-grpc_opt=paths=source_relative brc20pb/brc20.proto
+
+import (
+	"context"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/rpc/brc20pb"
+	"github.com/unisat-wallet/libbrc20-indexer/view"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// blocks buffered for each stream, a stream lagging more is closed
+	STREAM_BUFFER_BLOCKS = 64
+)
+
+// Service gRPC service of brc20pb.BRC20IndexerServer backed by the indexer. Reads are of
+// Query, blocks are streamed by an observer of the indexer.
+type Service struct {
+	brc20pb.UnimplementedBRC20IndexerServer
+
+	q      *indexer.Query
+	params *chaincfg.Params
+	hub    *blockHub
+}
+
+// NewService Service of indexer. Call before processing, as observers are not safe to add
+// while blocks are processed.
+func NewService(g *indexer.BRC20ModuleIndexer) *Service {
+	s := &Service{q: g.Query(), params: g.NetParams}
+	s.hub = newBlockHub(s)
+	g.AddObserver(s.hub)
+	return s
+}
+
+func (s *Service) pkScript(address string) (string, error) {
+	pkScript, err := view.PkScript(address, s.params)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid address %s", address)
+	}
+	return pkScript, nil
+}
+
+// pageBounds Bounds of page in list of total.
+func pageBounds(p *brc20pb.Page, total int) (start, end int, err error) {
+	limit := int(p.GetLimit())
+	if limit == 0 {
+		limit = view.DEFAULT_PAGE_LIMIT
+	}
+	if limit > view.MAX_PAGE_LIMIT {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "limit over %d", view.MAX_PAGE_LIMIT)
+	}
+	start, end = view.PageBounds(int(p.GetStart()), limit, total)
+	return start, end, nil
+}
+
+func (s *Service) GetStatus(ctx context.Context, req *brc20pb.GetStatusRequest) (resp *brc20pb.Status, err error) {
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		resp = &brc20pb.Status{
+			Network:      g.Rules.Network,
+			Height:       g.BestHeight,
+			HistoryStart: g.HistoryStart,
+			HistoryCount: g.HistoryCount,
+			Tokens:       uint32(len(g.InscriptionsTickerInfoMap)),
+			Modules:      uint32(len(g.ModuleIds())),
+		}
+	})
+	return resp, nil
+}
+
+func (s *Service) GetToken(ctx context.Context, req *brc20pb.GetTokenRequest) (resp *brc20pb.Token, err error) {
+	ticker := strings.ToLower(req.Ticker)
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		info, ok := g.InscriptionsTickerInfoMap[ticker]
+		if !ok {
+			return
+		}
+		deploy := info.Deploy
+		resp = &brc20pb.Token{
+			Ticker:            deploy.Tick,
+			InscriptionId:     deploy.GetInscriptionId(),
+			InscriptionNumber: deploy.InscriptionNumber,
+			Deployer:          view.Address(deploy.PkScript, s.params),
+			DeployHeight:      deploy.Height,
+			DeployBlockTime:   deploy.BlockTime,
+			Max:               view.DecimalString(deploy.Max),
+			Limit:             view.DecimalString(deploy.Limit),
+			Decimal:           uint32(deploy.Decimal),
+			SelfMint:          deploy.SelfMint,
+			Minted:            view.DecimalString(deploy.TotalMinted),
+			ConfirmedMinted:   view.DecimalString(deploy.ConfirmedMinted),
+			Burned:            view.DecimalString(deploy.Burned),
+			MintTimes:         deploy.MintTimes,
+			CompleteHeight:    deploy.CompleteHeight,
+			Holders:           uint32(g.TokenHolderCount(ticker)),
+			HistoryCount:      uint32(info.HistoryLen()),
+		}
+	})
+	if resp == nil {
+		return nil, status.Errorf(codes.NotFound, "token %s not found", req.Ticker)
+	}
+	return resp, nil
+}
+
+func (s *Service) balanceInfo(ticker string, balance *model.BRC20TokenBalance) *brc20pb.Balance {
+	return &brc20pb.Balance{
+		Ticker:        ticker,
+		Address:       view.Address(balance.PkScript, s.params),
+		Overall:       view.DecimalString(balance.OverallBalance()),
+		Available:     view.DecimalString(balance.AvailableBalance),
+		AvailableSafe: view.DecimalString(balance.AvailableBalanceSafe),
+		Transferable:  view.DecimalString(balance.TransferableBalance),
+		TransferCount: uint32(len(balance.ValidTransferMap)),
+		UpdateHeight:  balance.UpdateHeight,
+	}
+}
+
+func (s *Service) GetBalance(ctx context.Context, req *brc20pb.GetBalanceRequest) (resp *brc20pb.Balance, err error) {
+	pkScript, err := s.pkScript(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	ticker := strings.ToLower(req.Ticker)
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if balance, ok := g.UserBalance(ticker, pkScript); ok {
+			resp = s.balanceInfo(ticker, balance)
+		}
+	})
+	if resp == nil {
+		return nil, status.Errorf(codes.NotFound, "balance of %s not found", req.Ticker)
+	}
+	return resp, nil
+}
+
+func (s *Service) GetBalances(ctx context.Context, req *brc20pb.GetBalancesRequest) (resp *brc20pb.BalanceList, err error) {
+	pkScript, err := s.pkScript(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	resp = &brc20pb.BalanceList{}
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		userTokens := g.UserBalances(pkScript)
+		for _, ticker := range view.SortedKeys(userTokens) {
+			resp.Balances = append(resp.Balances, s.balanceInfo(ticker, userTokens[ticker]))
+		}
+	})
+	resp.Total = uint32(len(resp.Balances))
+	return resp, nil
+}
+
+func (s *Service) GetHolders(ctx context.Context, req *brc20pb.GetHoldersRequest) (resp *brc20pb.BalanceList, err error) {
+	ticker := strings.ToLower(req.Ticker)
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if _, ok := g.InscriptionsTickerInfoMap[ticker]; !ok {
+			err = status.Errorf(codes.NotFound, "token %s not found", req.Ticker)
+			return
+		}
+		balances := g.HolderRanking(ticker)
+		var start, end int
+		if start, end, err = pageBounds(req.Page, len(balances)); err != nil {
+			return
+		}
+		resp = &brc20pb.BalanceList{Total: uint32(len(balances))}
+		for _, balance := range balances[start:end] {
+			resp.Balances = append(resp.Balances, s.balanceInfo(ticker, balance))
+		}
+	})
+	return resp, err
+}
+
+// historyInfo History of decoded row.
+func historyInfo(row view.HistoryRow) *brc20pb.History {
+	return &brc20pb.History{
+		Idx:                 row.Idx,
+		Type:                row.Type,
+		Valid:               row.Valid,
+		Ticker:              row.Ticker,
+		InscriptionId:       row.InscriptionId,
+		InscriptionNumber:   row.InscriptionNumber,
+		Txid:                row.TxId,
+		Vout:                row.Vout,
+		Offset:              row.Offset,
+		From:                row.FromAddress,
+		To:                  row.ToAddress,
+		Amount:              row.Amount,
+		OverallBalance:      row.OverallBalance,
+		AvailableBalance:    row.AvailableBalance,
+		TransferableBalance: row.TransferableBalance,
+		Satoshi:             row.Satoshi,
+		Fee:                 row.Fee,
+		Height:              row.Height,
+		TxIdx:               row.TxIdx,
+		BlockTime:           row.BlockTime,
+	}
+}
+
+// GetHistory History of address, history pruned is skipped.
+func (s *Service) GetHistory(ctx context.Context, req *brc20pb.GetHistoryRequest) (resp *brc20pb.HistoryList, err error) {
+	pkScript, err := s.pkScript(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		userHistory := g.GetBRC20HistoryByUserForAPI(pkScript).History
+		var start, end int
+		if start, end, err = pageBounds(req.Page, len(userHistory)); err != nil {
+			return
+		}
+		resp = &brc20pb.HistoryList{Total: uint32(len(userHistory))}
+		for _, idx := range userHistory[start:end] {
+			data, ok := g.GetHistoryData(idx)
+			if !ok {
+				continue
+			}
+			h := &model.BRC20History{}
+			h.Unmarshal(data)
+			resp.History = append(resp.History, historyInfo(view.DecodeHistory(idx, h, s.params)))
+		}
+	})
+	return resp, err
+}
+
+func (s *Service) GetModuleAccount(ctx context.Context, req *brc20pb.GetModuleAccountRequest) (resp *brc20pb.ModuleAccount, err error) {
+	pkScript, err := s.pkScript(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		moduleInfo, ok := g.Module(req.ModuleId)
+		if !ok {
+			return
+		}
+		resp = &brc20pb.ModuleAccount{ModuleId: req.ModuleId, Address: req.Address}
+		userTokens := moduleInfo.UsersTokenBalanceDataMap[pkScript]
+		for _, ticker := range view.SortedKeys(userTokens) {
+			b := userTokens[ticker]
+			resp.Balances = append(resp.Balances, &brc20pb.ModuleBalance{
+				Ticker:            ticker,
+				SwapBalance:       view.DecimalString(b.SwapAccountBalance),
+				SwapBalanceSafe:   view.DecimalString(b.SwapAccountBalanceSafe),
+				ModuleBalanceSafe: view.DecimalString(b.ModuleAccountBalanceSafe),
+				Available:         view.DecimalString(b.AvailableBalance),
+				AvailableSafe:     view.DecimalString(b.AvailableBalanceSafe),
+				Approveable:       view.DecimalString(b.ApproveableBalance),
+				CondApproveable:   view.DecimalString(b.CondApproveableBalance),
+				ReadyToWithdraw:   view.DecimalString(b.ReadyToWithdrawAmount),
+				UpdateHeight:      b.UpdateHeight,
+			})
+		}
+		lps := moduleInfo.UsersLPTokenBalanceMap[pkScript]
+		for _, pair := range view.SortedKeys(lps) {
+			resp.Lp = append(resp.Lp, &brc20pb.LpBalance{Pair: pair, Balance: view.DecimalString(lps[pair])})
+		}
+	})
+	if resp == nil {
+		return nil, status.Errorf(codes.NotFound, "module %s not found", req.ModuleId)
+	}
+	return resp, nil
+}
+
+func poolInfo(moduleId, pair string, pool *model.BRC20ModulePoolTotalBalance, holders int) *brc20pb.Pool {
+	return &brc20pb.Pool{
+		ModuleId: moduleId,
+		Pair:     pair,
+		Tick0:    pool.Tick[0],
+		Tick1:    pool.Tick[1],
+		Reserve0: view.DecimalString(pool.TickBalance[0]),
+		Reserve1: view.DecimalString(pool.TickBalance[1]),
+		LpSupply: view.DecimalString(pool.LpBalance),
+		Holders:  uint32(holders),
+	}
+}
+
+func (s *Service) GetPool(ctx context.Context, req *brc20pb.GetPoolRequest) (resp *brc20pb.Pool, err error) {
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		moduleInfo, ok := g.Module(req.ModuleId)
+		if !ok {
+			return
+		}
+		if pool, ok := moduleInfo.SwapPoolTotalBalanceDataMap[req.Pair]; ok {
+			resp = poolInfo(req.ModuleId, req.Pair, pool, len(moduleInfo.LPTokenUsersBalanceMap[req.Pair]))
+		}
+	})
+	if resp == nil {
+		return nil, status.Errorf(codes.NotFound, "pool %s of module %s not found", req.Pair, req.ModuleId)
+	}
+	return resp, nil
+}
+
+// StreamBlocks Blocks processed after the call, until canceled. Changes of the first block are
+// partial if called in the middle of it. The stream is closed with ResourceExhausted if the
+// client does not keep up.
+func (s *Service) StreamBlocks(req *brc20pb.StreamBlocksRequest, stream brc20pb.BRC20Indexer_StreamBlocksServer) error {
+	sub := s.hub.subscribe()
+	defer s.hub.unsubscribe(sub)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-sub:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "stream lagged behind blocks")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
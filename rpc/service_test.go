@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer/indexertest"
+	"github.com/unisat-wallet/libbrc20-indexer/rpc/brc20pb"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testClient Client of service in process.
+func testClient(t *testing.T, s *Service) brc20pb.BRC20IndexerClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	brc20pb.RegisterBRC20IndexerServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial failed: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return brc20pb.NewBRC20IndexerClient(conn)
+}
+
+func TestService(t *testing.T) {
+	g := indexer.New(indexer.Options{NetParams: &chaincfg.MainNetParams})
+	client := testClient(t, NewService(g))
+	indexertest.Process(g, indexertest.Blocks())
+
+	ctx := context.Background()
+	addressA, _ := utils.GetAddressFromScript([]byte(indexertest.USER_A), &chaincfg.MainNetParams)
+	addressB, _ := utils.GetAddressFromScript([]byte(indexertest.USER_B), &chaincfg.MainNetParams)
+
+	token, err := client.GetToken(ctx, &brc20pb.GetTokenRequest{Ticker: "ORDI"})
+	if err != nil || token.Minted != "200" || token.Holders != 2 || token.Deployer != addressA {
+		t.Fatalf("token: %v %s", token, err)
+	}
+	if _, err := client.GetToken(ctx, &brc20pb.GetTokenRequest{Ticker: "none"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("token not found: %s", err)
+	}
+
+	balance, err := client.GetBalance(ctx, &brc20pb.GetBalanceRequest{Address: addressA, Ticker: "ordi"})
+	if err != nil || balance.Overall != "70" {
+		t.Fatalf("balance: %v %s", balance, err)
+	}
+	if _, err := client.GetBalances(ctx, &brc20pb.GetBalancesRequest{Address: "invalid"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("invalid address: %s", err)
+	}
+
+	holders, err := client.GetHolders(ctx, &brc20pb.GetHoldersRequest{Ticker: "ordi", Page: &brc20pb.Page{Limit: 1}})
+	if err != nil || holders.Total != 2 || len(holders.Balances) != 1 || holders.Balances[0].Address != addressB {
+		t.Fatalf("holders: %v %s", holders, err)
+	}
+
+	history, err := client.GetHistory(ctx, &brc20pb.GetHistoryRequest{Address: addressB})
+	if err != nil || history.Total != 2 || history.History[1].Type != "receive" || history.History[1].From != addressA {
+		t.Fatalf("history: %v %s", history, err)
+	}
+
+	if _, err := client.GetPool(ctx, &brc20pb.GetPoolRequest{ModuleId: "none"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("pool not found: %s", err)
+	}
+}
+
+func TestStreamBlocks(t *testing.T) {
+	g := indexer.New(indexer.Options{NetParams: &chaincfg.MainNetParams})
+	s := NewService(g)
+	client := testClient(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamBlocks(ctx, &brc20pb.StreamBlocksRequest{})
+	if err != nil {
+		t.Fatalf("stream failed: %s", err)
+	}
+	// wait for subscription of stream
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		s.hub.mu.Lock()
+		subs := len(s.hub.subs)
+		s.hub.mu.Unlock()
+		if subs == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream not subscribed")
+		}
+	}
+	indexertest.Process(g, indexertest.Blocks())
+
+	var events []string
+	for height := uint32(100); height <= 104; height++ {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("recv failed: %s", err)
+		}
+		if event.Height != height {
+			t.Fatalf("block %d, expected %d", event.Height, height)
+		}
+		for _, change := range event.Changes {
+			events = append(events, fmt.Sprintf("%d %s %s", event.Height, change.Type, change.Amount))
+		}
+	}
+	expected := fmt.Sprint([]string{
+		"100 TYPE_TOKEN_DEPLOYED ",
+		"101 TYPE_BALANCE_CHANGED ",
+		"102 TYPE_BALANCE_CHANGED ",
+		"103 TYPE_TRANSFER_CREATED 30",
+		"103 TYPE_BALANCE_CHANGED ",
+		"104 TYPE_TRANSFER_SPENT 30",
+		"104 TYPE_BALANCE_CHANGED ",
+		"104 TYPE_BALANCE_CHANGED ",
+	})
+	if fmt.Sprint(events) != expected {
+		t.Fatalf("changes: %v", events)
+	}
+}
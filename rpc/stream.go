@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"sync"
+
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/rpc/brc20pb"
+	"github.com/unisat-wallet/libbrc20-indexer/view"
+)
+
+// blockHub Observer collecting changes of the block being processed, sent to all streams
+// when the block is done. Changes are only collected while there are streams.
+type blockHub struct {
+	indexer.BaseObserver
+	s *Service
+
+	mu      sync.Mutex
+	subs    map[chan *brc20pb.BlockEvent]struct{}
+	changes []*brc20pb.StateChange
+}
+
+func newBlockHub(s *Service) *blockHub {
+	return &blockHub{s: s, subs: make(map[chan *brc20pb.BlockEvent]struct{}, 0)}
+}
+
+func (h *blockHub) subscribe() chan *brc20pb.BlockEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub := make(chan *brc20pb.BlockEvent, STREAM_BUFFER_BLOCKS)
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *blockHub) unsubscribe(sub chan *brc20pb.BlockEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub)
+	}
+}
+
+// publish Send event to all streams without waiting, streams full are dropped.
+func (h *blockHub) publish(event *brc20pb.BlockEvent) {
+	for sub := range h.subs {
+		select {
+		case sub <- event:
+		default:
+			delete(h.subs, sub)
+			close(sub)
+		}
+	}
+}
+
+// add Change built by change, only if there are streams.
+func (h *blockHub) add(change func() *brc20pb.StateChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subs) > 0 {
+		h.changes = append(h.changes, change())
+	}
+}
+
+func (h *blockHub) OnBlockProcessed(height uint32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publish(&brc20pb.BlockEvent{Height: height, Changes: h.changes})
+	h.changes = nil
+}
+
+func (h *blockHub) OnRollback(height uint32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publish(&brc20pb.BlockEvent{Height: height, Rollback: true})
+	h.changes = nil
+}
+
+func (h *blockHub) OnTokenDeployed(height uint32, tokenInfo *model.BRC20TokenInfo) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:   brc20pb.StateChange_TYPE_TOKEN_DEPLOYED,
+			Ticker: tokenInfo.Ticker,
+		}
+	})
+}
+
+func (h *blockHub) OnBalanceChanged(height uint32, userPkScript string, tokenBalance *model.BRC20TokenBalance) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:    brc20pb.StateChange_TYPE_BALANCE_CHANGED,
+			Ticker:  tokenBalance.Ticker,
+			Address: view.Address(userPkScript, h.s.params),
+			Balance: h.s.balanceInfo(tokenBalance.Ticker, tokenBalance),
+		}
+	})
+}
+
+func (h *blockHub) OnTransferCreated(height uint32, transferInfo *model.InscriptionBRC20TickInfo) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:          brc20pb.StateChange_TYPE_TRANSFER_CREATED,
+			Ticker:        transferInfo.Tick,
+			Address:       view.Address(transferInfo.PkScript, h.s.params),
+			InscriptionId: transferInfo.GetInscriptionId(),
+			Amount:        view.DecimalString(transferInfo.Amount),
+		}
+	})
+}
+
+func (h *blockHub) OnTransferSpent(height uint32, transferInfo *model.InscriptionBRC20TickInfo, receiverPkScript string) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:          brc20pb.StateChange_TYPE_TRANSFER_SPENT,
+			Ticker:        transferInfo.Tick,
+			Address:       view.Address(transferInfo.PkScript, h.s.params),
+			ToAddress:     view.Address(receiverPkScript, h.s.params),
+			InscriptionId: transferInfo.GetInscriptionId(),
+			Amount:        view.DecimalString(transferInfo.Amount),
+		}
+	})
+}
+
+func (h *blockHub) OnModuleDeposit(height uint32, moduleId, ticker, userPkScript string, amt *decimal.Decimal) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:     brc20pb.StateChange_TYPE_MODULE_DEPOSIT,
+			ModuleId: moduleId,
+			Ticker:   ticker,
+			Address:  view.Address(userPkScript, h.s.params),
+			Amount:   view.DecimalString(amt),
+		}
+	})
+}
+
+func (h *blockHub) OnModuleWithdraw(height uint32, moduleId, ticker, userPkScript string, amt *decimal.Decimal) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:     brc20pb.StateChange_TYPE_MODULE_WITHDRAW,
+			ModuleId: moduleId,
+			Ticker:   ticker,
+			Address:  view.Address(userPkScript, h.s.params),
+			Amount:   view.DecimalString(amt),
+		}
+	})
+}
+
+// OnPoolReservesChanged Holders of pool not set.
+func (h *blockHub) OnPoolReservesChanged(height uint32, moduleId, poolPair string, pool *model.BRC20ModulePoolTotalBalance) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:     brc20pb.StateChange_TYPE_POOL_CHANGED,
+			ModuleId: moduleId,
+			Pool:     poolInfo(moduleId, poolPair, pool, 0),
+		}
+	})
+}
+
+func (h *blockHub) OnCommitApplied(height uint32, moduleId, commitId string) {
+	h.add(func() *brc20pb.StateChange {
+		return &brc20pb.StateChange{
+			Type:          brc20pb.StateChange_TYPE_COMMIT_APPLIED,
+			ModuleId:      moduleId,
+			InscriptionId: commitId,
+		}
+	})
+}
@@ -0,0 +1,526 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/view"
+)
+
+var errNotFound = errors.New("not found")
+
+// Server Read-only JSON API of indexer state, GET only:
+//
+//	/status
+//	/tokens                                   tokens, paged
+//	/tokens/<ticker>
+//	/tokens/<ticker>/holders                  holders by overall balance, paged
+//	/tokens/<ticker>/stats                    supply distribution, top holders up to limit
+//	/tokens/<ticker>/holder_counts            holders over blocks
+//	/addresses/<address>/balances
+//	/addresses/<address>/balances/<ticker>
+//	/addresses/<address>/history              history of user, paged
+//	/modules/<module>/balances/<address>
+//	/modules/<module>/pools
+//	/modules/<module>/lp/<address>
+//
+// Paged lists accept start and limit in query.
+type Server struct {
+	q      *indexer.Query
+	params *chaincfg.Params
+}
+
+func New(q *indexer.Query, params *chaincfg.Params) *Server {
+	return &Server{q: q, params: params}
+}
+
+// page Range of list in response.
+type page struct {
+	start int
+	limit int
+}
+
+type pageResp struct {
+	Total int `json:"total"`
+	Start int `json:"start"`
+	List  any `json:"list"`
+}
+
+// slice Bounds of page in list of total.
+func (p page) slice(total int) (start, end int) {
+	return view.PageBounds(p.start, p.limit, total)
+}
+
+func parsePage(query url.Values) (p page, err error) {
+	p.limit = view.DEFAULT_PAGE_LIMIT
+	if s := query.Get("start"); s != "" {
+		if p.start, err = strconv.Atoi(s); err != nil || p.start < 0 {
+			return p, errors.New("invalid start")
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		if p.limit, err = strconv.Atoi(s); err != nil || p.limit <= 0 || p.limit > view.MAX_PAGE_LIMIT {
+			return p, errors.New("invalid limit")
+		}
+	}
+	return p, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	var parts []string
+	for _, part := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		part, err := url.PathUnescape(part)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		parts = append(parts, part)
+	}
+	p, err := parsePage(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var resp any
+	switch {
+	case len(parts) == 1 && parts[0] == "status":
+		resp = s.status()
+	case len(parts) == 1 && parts[0] == "tokens":
+		resp = s.tokens(p)
+	case len(parts) == 2 && parts[0] == "tokens":
+		resp, err = s.token(parts[1])
+	case len(parts) == 3 && parts[0] == "tokens" && parts[2] == "holders":
+		resp, err = s.holders(parts[1], p)
+	case len(parts) == 3 && parts[0] == "tokens" && parts[2] == "stats":
+		resp, err = s.tokenStats(parts[1], p)
+	case len(parts) == 3 && parts[0] == "tokens" && parts[2] == "holder_counts":
+		resp, err = s.holderCounts(parts[1], p)
+	case len(parts) == 3 && parts[0] == "addresses" && parts[2] == "balances":
+		resp, err = s.balances(parts[1], "")
+	case len(parts) == 4 && parts[0] == "addresses" && parts[2] == "balances":
+		resp, err = s.balances(parts[1], parts[3])
+	case len(parts) == 3 && parts[0] == "addresses" && parts[2] == "history":
+		resp, err = s.history(parts[1], p)
+	case len(parts) == 4 && parts[0] == "modules" && parts[2] == "balances":
+		resp, err = s.moduleBalances(parts[1], parts[3])
+	case len(parts) == 3 && parts[0] == "modules" && parts[2] == "pools":
+		resp, err = s.pools(parts[1])
+	case len(parts) == 4 && parts[0] == "modules" && parts[2] == "lp":
+		resp, err = s.lp(parts[1], parts[3])
+	default:
+		err = errNotFound
+	}
+
+	switch {
+	case errors.Is(err, errNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("write response failed, %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+type statusResp struct {
+	Network      string `json:"network"`
+	Height       uint32 `json:"height"`
+	HistoryStart uint64 `json:"historyStart"`
+	HistoryCount uint64 `json:"historyCount"`
+	Tokens       int    `json:"tokens"`
+	Modules      int    `json:"modules"`
+}
+
+func (s *Server) status() (resp statusResp) {
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		resp = statusResp{
+			Network:      g.Rules.Network,
+			Height:       g.BestHeight,
+			HistoryStart: g.HistoryStart,
+			HistoryCount: g.HistoryCount,
+			Tokens:       len(g.InscriptionsTickerInfoMap),
+			Modules:      len(g.ModuleIds()),
+		}
+	})
+	return resp
+}
+
+type tokenResp struct {
+	Ticker            string `json:"ticker"`
+	InscriptionId     string `json:"inscriptionId"`
+	InscriptionNumber int64  `json:"inscriptionNumber"`
+	Deployer          string `json:"deployer"`
+	DeployHeight      uint32 `json:"deployHeight"`
+	DeployBlockTime   uint32 `json:"deployBlocktime"`
+	Max               string `json:"max"`
+	Limit             string `json:"limit"`
+	Decimal           uint8  `json:"decimal"`
+	SelfMint          bool   `json:"selfMint"`
+	Minted            string `json:"minted"`
+	ConfirmedMinted   string `json:"confirmedMinted"`
+	Burned            string `json:"burned"`
+	MintTimes         uint32 `json:"mintTimes"`
+	CompleteHeight    uint32 `json:"completeHeight"`
+	Holders           int    `json:"holders"`
+	HistoryCount      int    `json:"historyCount"`
+}
+
+func (s *Server) tokenInfo(g *indexer.BRC20ModuleIndexer, ticker string, info *model.BRC20TokenInfo) tokenResp {
+	deploy := info.Deploy
+	return tokenResp{
+		Ticker:            deploy.Tick,
+		InscriptionId:     deploy.GetInscriptionId(),
+		InscriptionNumber: deploy.InscriptionNumber,
+		Deployer:          view.Address(deploy.PkScript, s.params),
+		DeployHeight:      deploy.Height,
+		DeployBlockTime:   deploy.BlockTime,
+		Max:               view.DecimalString(deploy.Max),
+		Limit:             view.DecimalString(deploy.Limit),
+		Decimal:           deploy.Decimal,
+		SelfMint:          deploy.SelfMint,
+		Minted:            view.DecimalString(deploy.TotalMinted),
+		ConfirmedMinted:   view.DecimalString(deploy.ConfirmedMinted),
+		Burned:            view.DecimalString(deploy.Burned),
+		MintTimes:         deploy.MintTimes,
+		CompleteHeight:    deploy.CompleteHeight,
+		Holders:           g.TokenHolderCount(ticker),
+		HistoryCount:      info.HistoryLen(),
+	}
+}
+
+func (s *Server) tokens(p page) (resp pageResp) {
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		tickers := view.SortedKeys(g.InscriptionsTickerInfoMap)
+
+		start, end := p.slice(len(tickers))
+		list := make([]tokenResp, 0, end-start)
+		for _, ticker := range tickers[start:end] {
+			list = append(list, s.tokenInfo(g, ticker, g.InscriptionsTickerInfoMap[ticker]))
+		}
+		resp = pageResp{Total: len(tickers), Start: start, List: list}
+	})
+	return resp
+}
+
+func (s *Server) token(ticker string) (resp tokenResp, err error) {
+	ticker = strings.ToLower(ticker)
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if info, ok := g.InscriptionsTickerInfoMap[ticker]; ok {
+			resp, err = s.tokenInfo(g, ticker, info), nil
+		}
+	})
+	return resp, err
+}
+
+type balanceResp struct {
+	Ticker              string `json:"ticker"`
+	Address             string `json:"address"`
+	OverallBalance      string `json:"overallBalance"`
+	AvailableBalance    string `json:"availableBalance"`
+	AvailableSafe       string `json:"availableBalanceSafe"`
+	TransferableBalance string `json:"transferableBalance"`
+	TransferCount       int    `json:"transferCount"`
+	UpdateHeight        uint32 `json:"updateHeight"`
+}
+
+func (s *Server) balanceInfo(ticker string, balance *model.BRC20TokenBalance) balanceResp {
+	return balanceResp{
+		Ticker:              ticker,
+		Address:             view.Address(balance.PkScript, s.params),
+		OverallBalance:      view.DecimalString(balance.OverallBalance()),
+		AvailableBalance:    view.DecimalString(balance.AvailableBalance),
+		AvailableSafe:       view.DecimalString(balance.AvailableBalanceSafe),
+		TransferableBalance: view.DecimalString(balance.TransferableBalance),
+		TransferCount:       len(balance.ValidTransferMap),
+		UpdateHeight:        balance.UpdateHeight,
+	}
+}
+
+func (s *Server) holders(ticker string, p page) (resp pageResp, err error) {
+	ticker = strings.ToLower(ticker)
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if _, ok := g.InscriptionsTickerInfoMap[ticker]; !ok {
+			return
+		}
+		balances := g.HolderRanking(ticker)
+		start, end := p.slice(len(balances))
+		list := make([]balanceResp, 0, end-start)
+		for _, balance := range balances[start:end] {
+			list = append(list, s.balanceInfo(ticker, balance))
+		}
+		resp, err = pageResp{Total: len(balances), Start: start, List: list}, nil
+	})
+	return resp, err
+}
+
+type holderShareResp struct {
+	Address             string  `json:"address"`
+	OverallBalance      string  `json:"overallBalance"`
+	AvailableBalance    string  `json:"availableBalance"`
+	TransferableBalance string  `json:"transferableBalance"`
+	Share               float64 `json:"share"`
+}
+
+type tokenStatsResp struct {
+	Ticker                  string            `json:"ticker"`
+	Height                  uint32            `json:"height"`
+	Max                     string            `json:"max"`
+	Minted                  string            `json:"minted"`
+	Burned                  string            `json:"burned"`
+	Supply                  string            `json:"supply"`
+	Holders                 int               `json:"holders"`
+	TransferableOnlyHolders int               `json:"transferableOnlyHolders"`
+	TopHolders              []holderShareResp `json:"topHolders"`
+	TopShare                float64           `json:"topShare"`
+	Gini                    float64           `json:"gini"`
+}
+
+// tokenStats Distribution of supply, top holders limited by limit of page.
+func (s *Server) tokenStats(ticker string, p page) (resp tokenStatsResp, err error) {
+	var stats *indexer.TickerStats
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if _, ok := g.InscriptionsTickerInfoMap[strings.ToLower(ticker)]; ok {
+			stats, err = g.TickerStats(ticker, p.limit)
+		}
+	})
+	if err != nil {
+		return resp, err
+	}
+	resp = tokenStatsResp{
+		Ticker:                  stats.Ticker,
+		Height:                  stats.Height,
+		Max:                     view.DecimalString(stats.Max),
+		Minted:                  view.DecimalString(stats.Minted),
+		Burned:                  view.DecimalString(stats.Burned),
+		Supply:                  view.DecimalString(stats.Supply),
+		Holders:                 stats.Holders,
+		TransferableOnlyHolders: stats.TransferableOnlyHolders,
+		TopHolders:              make([]holderShareResp, 0, len(stats.TopHolders)),
+		TopShare:                stats.TopShare,
+		Gini:                    stats.Gini,
+	}
+	for _, holder := range stats.TopHolders {
+		resp.TopHolders = append(resp.TopHolders, holderShareResp{
+			Address:             view.Address(holder.PkScript, s.params),
+			OverallBalance:      view.DecimalString(holder.OverallBalance),
+			AvailableBalance:    view.DecimalString(holder.AvailableBalance),
+			TransferableBalance: view.DecimalString(holder.TransferableBalance),
+			Share:               holder.Share,
+		})
+	}
+	return resp, nil
+}
+
+type holderCountResp struct {
+	Height  uint32 `json:"height"`
+	Holders int    `json:"holders"`
+}
+
+func (s *Server) holderCounts(ticker string, p page) (resp pageResp, err error) {
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		if _, ok := g.InscriptionsTickerInfoMap[strings.ToLower(ticker)]; !ok {
+			return
+		}
+		var counts []indexer.HolderCount
+		if counts, err = g.HolderCounts(ticker); err != nil {
+			return
+		}
+		start, end := p.slice(len(counts))
+		list := make([]holderCountResp, 0, end-start)
+		for _, c := range counts[start:end] {
+			list = append(list, holderCountResp{Height: c.Height, Holders: c.Holders})
+		}
+		resp = pageResp{Total: len(counts), Start: start, List: list}
+	})
+	return resp, err
+}
+
+// balances All balances of address, or only of ticker if set.
+func (s *Server) balances(address, ticker string) (resp any, err error) {
+	pkScript, err := view.PkScript(address, s.params)
+	if err != nil {
+		return nil, err
+	}
+	ticker = strings.ToLower(ticker)
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		userTokens := g.UserBalances(pkScript)
+		if ticker != "" {
+			if balance, ok := userTokens[ticker]; ok {
+				resp = s.balanceInfo(ticker, balance)
+			} else {
+				err = errNotFound
+			}
+			return
+		}
+
+		tickers := view.SortedKeys(userTokens)
+		list := make([]balanceResp, 0, len(tickers))
+		for _, ticker := range tickers {
+			list = append(list, s.balanceInfo(ticker, userTokens[ticker]))
+		}
+		resp = list
+	})
+	return resp, err
+}
+
+// history History of address, in order of index. History pruned is skipped.
+func (s *Server) history(address string, p page) (resp pageResp, err error) {
+	pkScript, err := view.PkScript(address, s.params)
+	if err != nil {
+		return resp, err
+	}
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		userHistory := g.GetBRC20HistoryByUserForAPI(pkScript).History
+		start, end := p.slice(len(userHistory))
+		list := make([]view.HistoryRow, 0, end-start)
+		for _, idx := range userHistory[start:end] {
+			data, ok := g.GetHistoryData(idx)
+			if !ok {
+				continue
+			}
+			h := &model.BRC20History{}
+			h.Unmarshal(data)
+			list = append(list, view.DecodeHistory(idx, h, s.params))
+		}
+		resp = pageResp{Total: len(userHistory), Start: start, List: list}
+	})
+	return resp, nil
+}
+
+type moduleBalanceResp struct {
+	Ticker                 string `json:"ticker"`
+	SwapAccountBalance     string `json:"swapAccountBalance"`
+	SwapAccountBalanceSafe string `json:"swapAccountBalanceSafe"`
+	ModuleBalanceSafe      string `json:"moduleAccountBalanceSafe"`
+	AvailableBalance       string `json:"availableBalance"`
+	AvailableBalanceSafe   string `json:"availableBalanceSafe"`
+	ApproveableBalance     string `json:"approveableBalance"`
+	CondApproveableBalance string `json:"condApproveableBalance"`
+	ReadyToWithdrawAmount  string `json:"readyToWithdrawAmount"`
+	UpdateHeight           uint32 `json:"updateHeight"`
+}
+
+func (s *Server) moduleBalances(moduleId, address string) (resp []moduleBalanceResp, err error) {
+	pkScript, err := view.PkScript(address, s.params)
+	if err != nil {
+		return nil, err
+	}
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		moduleInfo, ok := g.Module(moduleId)
+		if !ok {
+			return
+		}
+		userTokens := moduleInfo.UsersTokenBalanceDataMap[pkScript]
+		tickers := view.SortedKeys(userTokens)
+		resp = make([]moduleBalanceResp, 0, len(tickers))
+		for _, ticker := range tickers {
+			b := userTokens[ticker]
+			resp = append(resp, moduleBalanceResp{
+				Ticker:                 ticker,
+				SwapAccountBalance:     view.DecimalString(b.SwapAccountBalance),
+				SwapAccountBalanceSafe: view.DecimalString(b.SwapAccountBalanceSafe),
+				ModuleBalanceSafe:      view.DecimalString(b.ModuleAccountBalanceSafe),
+				AvailableBalance:       view.DecimalString(b.AvailableBalance),
+				AvailableBalanceSafe:   view.DecimalString(b.AvailableBalanceSafe),
+				ApproveableBalance:     view.DecimalString(b.ApproveableBalance),
+				CondApproveableBalance: view.DecimalString(b.CondApproveableBalance),
+				ReadyToWithdrawAmount:  view.DecimalString(b.ReadyToWithdrawAmount),
+				UpdateHeight:           b.UpdateHeight,
+			})
+		}
+		err = nil
+	})
+	return resp, err
+}
+
+type poolResp struct {
+	Pair     string `json:"pair"`
+	Tick0    string `json:"tick0"`
+	Tick1    string `json:"tick1"`
+	Reserve0 string `json:"reserve0"`
+	Reserve1 string `json:"reserve1"`
+	LpSupply string `json:"lp"`
+	Holders  int    `json:"holders"`
+}
+
+func (s *Server) pools(moduleId string) (resp []poolResp, err error) {
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		moduleInfo, ok := g.Module(moduleId)
+		if !ok {
+			return
+		}
+		pairs := view.SortedKeys(moduleInfo.SwapPoolTotalBalanceDataMap)
+		resp = make([]poolResp, 0, len(pairs))
+		for _, pair := range pairs {
+			pool := moduleInfo.SwapPoolTotalBalanceDataMap[pair]
+			resp = append(resp, poolResp{
+				Pair:     pair,
+				Tick0:    pool.Tick[0],
+				Tick1:    pool.Tick[1],
+				Reserve0: view.DecimalString(pool.TickBalance[0]),
+				Reserve1: view.DecimalString(pool.TickBalance[1]),
+				LpSupply: view.DecimalString(pool.LpBalance),
+				Holders:  len(moduleInfo.LPTokenUsersBalanceMap[pair]),
+			})
+		}
+		err = nil
+	})
+	return resp, err
+}
+
+type lpResp struct {
+	Pair    string `json:"pair"`
+	Balance string `json:"balance"`
+}
+
+func (s *Server) lp(moduleId, address string) (resp []lpResp, err error) {
+	pkScript, err := view.PkScript(address, s.params)
+	if err != nil {
+		return nil, err
+	}
+	err = errNotFound
+	s.q.View(func(g *indexer.BRC20ModuleIndexer) {
+		moduleInfo, ok := g.Module(moduleId)
+		if !ok {
+			return
+		}
+		lps := moduleInfo.UsersLPTokenBalanceMap[pkScript]
+		pairs := view.SortedKeys(lps)
+		resp = make([]lpResp, 0, len(pairs))
+		for _, pair := range pairs {
+			resp = append(resp, lpResp{Pair: pair, Balance: view.DecimalString(lps[pair])})
+		}
+		err = nil
+	})
+	return resp, err
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/indexer/indexertest"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+	"github.com/unisat-wallet/libbrc20-indexer/view"
+)
+
+// testServer Server of state with a deploy, mints and a transfer.
+func testServer(t *testing.T) *httptest.Server {
+	g := indexertest.NewIndexer()
+	srv := httptest.NewServer(New(g.Query(), &chaincfg.MainNetParams))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, srv *httptest.Server, path string, status int, resp any) {
+	res, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("%s: %s", path, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != status {
+		t.Fatalf("%s: status %d, expected %d", path, res.StatusCode, status)
+	}
+	if resp != nil {
+		if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv := testServer(t)
+	addressA, _ := utils.GetAddressFromScript([]byte(indexertest.USER_A), &chaincfg.MainNetParams)
+	addressB, _ := utils.GetAddressFromScript([]byte(indexertest.USER_B), &chaincfg.MainNetParams)
+
+	var status statusResp
+	get(t, srv, "/status", http.StatusOK, &status)
+	if status.Height != 104 || status.Tokens != 1 {
+		t.Fatalf("status: %+v", status)
+	}
+
+	var token tokenResp
+	get(t, srv, "/tokens/ORDI", http.StatusOK, &token)
+	if token.Minted != "200" || token.Holders != 2 || token.Deployer != addressA {
+		t.Fatalf("token: %+v", token)
+	}
+	get(t, srv, "/tokens/none", http.StatusNotFound, nil)
+
+	var holders struct {
+		Total int
+		List  []balanceResp
+	}
+	get(t, srv, "/tokens/ordi/holders?limit=1", http.StatusOK, &holders)
+	if holders.Total != 2 || len(holders.List) != 1 || holders.List[0].Address != addressB ||
+		holders.List[0].OverallBalance != "130" {
+		t.Fatalf("holders: %+v", holders)
+	}
+
+	var stats tokenStatsResp
+	get(t, srv, "/tokens/ordi/stats?limit=1", http.StatusOK, &stats)
+	if stats.Holders != 2 || stats.Supply != "200" || len(stats.TopHolders) != 1 ||
+		stats.TopHolders[0].Address != addressB || stats.TopHolders[0].Share != 65 || stats.TopShare != 100 {
+		t.Fatalf("stats: %+v", stats)
+	}
+	get(t, srv, "/tokens/none/stats", http.StatusNotFound, nil)
+
+	var balance balanceResp
+	get(t, srv, "/addresses/"+addressA+"/balances/ordi", http.StatusOK, &balance)
+	if balance.OverallBalance != "70" || balance.TransferableBalance != "0" {
+		t.Fatalf("balance: %+v", balance)
+	}
+	get(t, srv, "/addresses/invalid/balances", http.StatusBadRequest, nil)
+
+	var history struct {
+		Total int
+		Start int
+		List  []view.HistoryRow
+	}
+	get(t, srv, "/addresses/"+addressA+"/history?start=1", http.StatusOK, &history)
+	if history.Total != 4 || history.Start != 1 || len(history.List) != 3 ||
+		history.List[2].Type != "send" || history.List[2].ToAddress != addressB {
+		t.Fatalf("history: %+v", history)
+	}
+	get(t, srv, "/addresses/"+addressA+"/history?limit=0", http.StatusBadRequest, nil)
+
+	get(t, srv, "/modules/none/pools", http.StatusNotFound, nil)
+}
@@ -0,0 +1,50 @@
+package stateroot
+
+import (
+	"strings"
+)
+
+// leaf types, first field of every leaf key
+const (
+	LEAF_TICK           = 1
+	LEAF_BALANCE        = 2
+	LEAF_MODULE_BALANCE = 3
+	LEAF_POOL           = 4
+	LEAF_LP             = 5
+)
+
+// TickLeaf Supply of ticker.
+func TickLeaf(ticker, max, limit string, decimal uint8, minted string) (key, value []byte) {
+	key = new(Encoder).Uint(LEAF_TICK).String(strings.ToLower(ticker)).Bytes()
+	value = new(Encoder).String(max).String(limit).Uint(uint64(decimal)).String(minted).Bytes()
+	return key, value
+}
+
+// BalanceLeaf Balance of user's ticker.
+func BalanceLeaf(pkScript, ticker, available, transferable string) (key, value []byte) {
+	key = new(Encoder).Uint(LEAF_BALANCE).String(pkScript).String(strings.ToLower(ticker)).Bytes()
+	value = new(Encoder).String(available).String(transferable).Bytes()
+	return key, value
+}
+
+// ModuleBalanceLeaf Balance of user's ticker in module.
+func ModuleBalanceLeaf(moduleId, pkScript, ticker,
+	swap, available, approveable, condApproveable, readyToWithdraw string) (key, value []byte) {
+	key = new(Encoder).Uint(LEAF_MODULE_BALANCE).String(moduleId).String(pkScript).String(strings.ToLower(ticker)).Bytes()
+	value = new(Encoder).String(swap).String(available).String(approveable).String(condApproveable).String(readyToWithdraw).Bytes()
+	return key, value
+}
+
+// PoolLeaf Reserves of pool in module.
+func PoolLeaf(moduleId, poolPair, tick0, tick1, balance0, balance1, lp string) (key, value []byte) {
+	key = new(Encoder).Uint(LEAF_POOL).String(moduleId).String(poolPair).Bytes()
+	value = new(Encoder).String(tick0).String(tick1).String(balance0).String(balance1).String(lp).Bytes()
+	return key, value
+}
+
+// LpLeaf Lp balance of user in pool.
+func LpLeaf(moduleId, poolPair, pkScript, lp string) (key, value []byte) {
+	key = new(Encoder).Uint(LEAF_LP).String(moduleId).String(poolPair).String(pkScript).Bytes()
+	value = new(Encoder).String(lp).Bytes()
+	return key, value
+}
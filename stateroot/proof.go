@@ -0,0 +1,108 @@
+package stateroot
+
+// Proof Inclusion proof of a leaf in the state root. Only stdlib is needed to
+// verify, so the package can be used by a light client without the indexer.
+type Proof struct {
+	Height uint32 `json:"height"` // height of the root
+
+	// path in merkle tree of the bucket
+	LeafIndex  uint32 `json:"leafIndex"`
+	LeafCount  uint32 `json:"leafCount"`
+	BucketPath []Hash `json:"bucketPath"`
+
+	// path from the bucket to root
+	RootPath []Hash `json:"rootPath"`
+}
+
+// Prove Inclusion proof of key in current root.
+func (t *Tree) Prove(key []byte) (proof *Proof, ok bool) {
+	if len(t.dirty) > 0 {
+		t.Root()
+	}
+
+	idx := BucketIndex(key)
+	if _, ok := t.buckets[idx][string(key)]; !ok {
+		return nil, false
+	}
+	keys, level := t.bucketLeaves(idx)
+
+	proof = &Proof{LeafCount: uint32(len(level))}
+	for i, k := range keys {
+		if k == string(key) {
+			proof.LeafIndex = uint32(i)
+			break
+		}
+	}
+
+	pos := int(proof.LeafIndex)
+	for len(level) > 1 {
+		if pos%2 == 1 {
+			proof.BucketPath = append(proof.BucketPath, level[pos-1])
+		} else if pos+1 < len(level) {
+			proof.BucketPath = append(proof.BucketPath, level[pos+1])
+		}
+		level = nextLevel(level)
+		pos /= 2
+	}
+
+	pos = int(idx)
+	for l := 0; l < len(t.levels)-1; l++ {
+		proof.RootPath = append(proof.RootPath, t.levels[l][pos^1])
+		pos /= 2
+	}
+	return proof, true
+}
+
+// VerifyProof Check the leaf of key and value is included in root.
+func VerifyProof(root Hash, key, value []byte, proof *Proof) bool {
+	if proof == nil || proof.LeafIndex >= proof.LeafCount || len(proof.RootPath) != BUCKET_BITS {
+		return false
+	}
+
+	h := LeafHash(key, value)
+	pos, count := proof.LeafIndex, proof.LeafCount
+	path := proof.BucketPath
+	for count > 1 {
+		if pos%2 == 1 || pos+1 < count {
+			if len(path) == 0 {
+				return false
+			}
+			if pos%2 == 1 {
+				h = NodeHash(path[0], h)
+			} else {
+				h = NodeHash(h, path[0])
+			}
+			path = path[1:]
+		}
+		// else the odd node is promoted
+		pos /= 2
+		count = (count + 1) / 2
+	}
+	if len(path) != 0 {
+		return false
+	}
+
+	bucket := BucketIndex(key)
+	for _, sibling := range proof.RootPath {
+		if bucket%2 == 1 {
+			h = NodeHash(sibling, h)
+		} else {
+			h = NodeHash(h, sibling)
+		}
+		bucket /= 2
+	}
+	return h == root
+}
+
+// VerifyBalance Check the brc20 balance of user is included in root.
+func VerifyBalance(root Hash, pkScript, ticker, available, transferable string, proof *Proof) bool {
+	key, value := BalanceLeaf(pkScript, ticker, available, transferable)
+	return VerifyProof(root, key, value, proof)
+}
+
+// VerifyModuleBalance Check the balance of user in module is included in root.
+func VerifyModuleBalance(root Hash, moduleId, pkScript, ticker,
+	swap, available, approveable, condApproveable, readyToWithdraw string, proof *Proof) bool {
+	key, value := ModuleBalanceLeaf(moduleId, pkScript, ticker, swap, available, approveable, condApproveable, readyToWithdraw)
+	return VerifyProof(root, key, value, proof)
+}
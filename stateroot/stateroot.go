@@ -0,0 +1,234 @@
+package stateroot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sort"
+)
+
+// leaves are spread into fixed buckets by hash of key. each bucket is a merkle
+// tree of its leaves sorted by key, root is a merkle tree over all buckets.
+const (
+	BUCKET_BITS = 16
+	BUCKET_SIZE = 1 << BUCKET_BITS
+)
+
+// domain separation of hashes
+const (
+	hashTagLeaf byte = 0x00
+	hashTagNode byte = 0x01
+)
+
+type Hash [32]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(b) != len(h) {
+		return errors.New("hash length invalid")
+	}
+	copy(h[:], b)
+	return nil
+}
+
+// LeafHash Hash of a leaf, key and value are in canonical encoding.
+func LeafHash(key, value []byte) (h Hash) {
+	sha := sha256.New()
+	sha.Write([]byte{hashTagLeaf})
+	sha.Write(encodeUvarint(uint64(len(key))))
+	sha.Write(key)
+	sha.Write(value)
+	copy(h[:], sha.Sum(nil))
+	return h
+}
+
+// NodeHash Hash of an inner node.
+func NodeHash(left, right Hash) (h Hash) {
+	sha := sha256.New()
+	sha.Write([]byte{hashTagNode})
+	sha.Write(left[:])
+	sha.Write(right[:])
+	copy(h[:], sha.Sum(nil))
+	return h
+}
+
+// BucketIndex Bucket of a leaf key.
+func BucketIndex(key []byte) uint32 {
+	h := sha256.Sum256(key)
+	return uint32(binary.BigEndian.Uint16(h[:2]))
+}
+
+// MerkleRoot Root of the hashes, zero if empty. An odd node is promoted to the next level as is.
+func MerkleRoot(hashes []Hash) Hash {
+	if len(hashes) == 0 {
+		return Hash{}
+	}
+	level := make([]Hash, len(hashes))
+	copy(level, hashes)
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+	return level[0]
+}
+
+func nextLevel(level []Hash) []Hash {
+	next := make([]Hash, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 < len(level) {
+			next = append(next, NodeHash(level[i], level[i+1]))
+		} else {
+			next = append(next, level[i])
+		}
+	}
+	return next
+}
+
+// Tree State leaves by key, root is updated incrementally on dirty buckets only.
+type Tree struct {
+	buckets map[uint32]map[string]Hash // [bucket][key]leafHash
+	dirty   map[uint32]struct{}
+
+	// levels[0] is bucket hashes, last level is root
+	levels [][]Hash
+}
+
+func NewTree() *Tree {
+	t := &Tree{
+		buckets: make(map[uint32]map[string]Hash, 0),
+		dirty:   make(map[uint32]struct{}, 0),
+	}
+
+	t.levels = append(t.levels, make([]Hash, BUCKET_SIZE))
+	for len(t.levels[len(t.levels)-1]) > 1 {
+		t.levels = append(t.levels, nextLevel(t.levels[len(t.levels)-1]))
+	}
+	return t
+}
+
+// Set Update the leaf of key, no change if same.
+func (t *Tree) Set(key []byte, leaf Hash) {
+	idx := BucketIndex(key)
+	bucket, ok := t.buckets[idx]
+	if !ok {
+		bucket = make(map[string]Hash, 1)
+		t.buckets[idx] = bucket
+	}
+	if old, ok := bucket[string(key)]; ok && old == leaf {
+		return
+	}
+	bucket[string(key)] = leaf
+	t.dirty[idx] = struct{}{}
+}
+
+// Delete Remove the leaf of key.
+func (t *Tree) Delete(key []byte) {
+	idx := BucketIndex(key)
+	bucket, ok := t.buckets[idx]
+	if !ok {
+		return
+	}
+	if _, ok := bucket[string(key)]; !ok {
+		return
+	}
+	delete(bucket, string(key))
+	if len(bucket) == 0 {
+		delete(t.buckets, idx)
+	}
+	t.dirty[idx] = struct{}{}
+}
+
+// Get Leaf hash of key.
+func (t *Tree) Get(key []byte) (leaf Hash, ok bool) {
+	bucket, ok := t.buckets[BucketIndex(key)]
+	if !ok {
+		return leaf, false
+	}
+	leaf, ok = bucket[string(key)]
+	return leaf, ok
+}
+
+// Len Count of leaves.
+func (t *Tree) Len() (n int) {
+	for _, bucket := range t.buckets {
+		n += len(bucket)
+	}
+	return n
+}
+
+// Root Rehash the dirty buckets and their paths, return the root.
+func (t *Tree) Root() Hash {
+	for idx := range t.dirty {
+		_, leaves := t.bucketLeaves(idx)
+		t.levels[0][idx] = MerkleRoot(leaves)
+
+		pos := int(idx)
+		for l := 1; l < len(t.levels); l++ {
+			pos /= 2
+			left, right := pos*2, pos*2+1
+			if right < len(t.levels[l-1]) {
+				t.levels[l][pos] = NodeHash(t.levels[l-1][left], t.levels[l-1][right])
+			} else {
+				t.levels[l][pos] = t.levels[l-1][left]
+			}
+		}
+	}
+	t.dirty = make(map[uint32]struct{}, 0)
+	return t.levels[len(t.levels)-1][0]
+}
+
+// bucketLeaves Leaf hashes of the bucket sorted by key.
+func (t *Tree) bucketLeaves(idx uint32) (keys []string, leaves []Hash) {
+	bucket := t.buckets[idx]
+	keys = make([]string, 0, len(bucket))
+	for key := range bucket {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		leaves = append(leaves, bucket[key])
+	}
+	return keys, leaves
+}
+
+// Encoder Canonical encoding of leaf key and value, length prefixed fields.
+type Encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *Encoder) String(s string) *Encoder {
+	e.buf.Write(encodeUvarint(uint64(len(s))))
+	e.buf.WriteString(s)
+	return e
+}
+
+func (e *Encoder) Uint(v uint64) *Encoder {
+	e.buf.Write(encodeUvarint(v))
+	return e
+}
+
+func (e *Encoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+func encodeUvarint(v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return buf[:n]
+}
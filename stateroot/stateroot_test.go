@@ -0,0 +1,79 @@
+package stateroot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTreeRoot(t *testing.T) {
+	tree := NewTree()
+	empty := tree.Root()
+
+	for i := 0; i < 1000; i++ {
+		key, value := BalanceLeaf(fmt.Sprintf("user-%d", i), "ordi", fmt.Sprint(i), "0")
+		tree.Set(key, LeafHash(key, value))
+	}
+	root := tree.Root()
+
+	// same leaves in other order
+	other := NewTree()
+	for i := 999; i >= 0; i-- {
+		key, value := BalanceLeaf(fmt.Sprintf("user-%d", i), "ORDI", fmt.Sprint(i), "0")
+		other.Set(key, LeafHash(key, value))
+	}
+	if other.Root() != root {
+		t.Fatal("root depends on insert order")
+	}
+
+	// update then revert
+	key, value := BalanceLeaf("user-1", "ordi", "2", "0")
+	tree.Set(key, LeafHash(key, value))
+	if tree.Root() == root {
+		t.Fatal("root not changed")
+	}
+	key, value = BalanceLeaf("user-1", "ordi", "1", "0")
+	tree.Set(key, LeafHash(key, value))
+	if tree.Root() != root {
+		t.Fatal("root not reverted")
+	}
+
+	for i := 0; i < 1000; i++ {
+		key, _ := BalanceLeaf(fmt.Sprintf("user-%d", i), "ordi", "", "")
+		tree.Delete(key)
+	}
+	if tree.Len() != 0 || tree.Root() != empty {
+		t.Fatal("root of empty tree changed")
+	}
+}
+
+func TestProof(t *testing.T) {
+	tree := NewTree()
+	// more leaves than buckets, so buckets have paths
+	for i := 0; i < 200000; i++ {
+		key, value := BalanceLeaf(fmt.Sprintf("user-%d", i), "ordi", fmt.Sprint(i), "0")
+		tree.Set(key, LeafHash(key, value))
+	}
+	root := tree.Root()
+
+	for i := 0; i < 200000; i += 997 {
+		key, _ := BalanceLeaf(fmt.Sprintf("user-%d", i), "ordi", "", "")
+		proof, ok := tree.Prove(key)
+		if !ok {
+			t.Fatalf("missing proof of %d", i)
+		}
+		if !VerifyBalance(root, fmt.Sprintf("user-%d", i), "ORDI", fmt.Sprint(i), "0", proof) {
+			t.Fatalf("proof of %d invalid", i)
+		}
+		if VerifyBalance(root, fmt.Sprintf("user-%d", i), "ordi", fmt.Sprint(i+1), "0", proof) {
+			t.Fatalf("proof of %d valid for wrong balance", i)
+		}
+		if VerifyBalance(root, fmt.Sprintf("user-%d", i+1), "ordi", fmt.Sprint(i), "0", proof) {
+			t.Fatalf("proof of %d valid for wrong user", i)
+		}
+	}
+
+	key, _ := BalanceLeaf("nobody", "ordi", "", "")
+	if _, ok := tree.Prove(key); ok {
+		t.Fatal("proof of missing leaf")
+	}
+}
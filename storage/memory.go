@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory KV in memory, for tests.
+type Memory struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{data: make(map[string][]byte, 0)}
+}
+
+func (m *Memory) Get(key []byte) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.data[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(value), nil
+}
+
+func (m *Memory) Put(key, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[string(key)] = bytes.Clone(value)
+	return nil
+}
+
+func (m *Memory) Delete(key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, string(key))
+	return nil
+}
+
+func (m *Memory) Iterate(prefix []byte, fn func(key, value []byte) bool) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []string
+	for key := range m.data {
+		if strings.HasPrefix(key, string(prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !fn([]byte(key), m.data[key]) {
+			break
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Write(batch *Batch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range batch.ops {
+		switch op.op {
+		case opPut:
+			m.data[string(op.key)] = bytes.Clone(op.value)
+		case opDelete:
+			delete(m.data, string(op.key))
+		case opDeletePrefix:
+			for key := range m.data {
+				if strings.HasPrefix(key, string(op.key)) {
+					delete(m.data, key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
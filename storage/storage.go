@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+// KV Ordered key-value store of indexer state.
+type KV interface {
+	// Get Value of key, ErrNotFound if not exist. The value is owned by caller.
+	Get(key []byte) ([]byte, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+
+	// Iterate Keys with prefix in order, stop if fn returns false.
+	// key and value are valid in fn only.
+	Iterate(prefix []byte, fn func(key, value []byte) bool) error
+
+	// Write Apply all operations of batch atomically.
+	Write(batch *Batch) error
+
+	Close() error
+}
+
+const (
+	opPut uint8 = iota
+	opDelete
+	opDeletePrefix
+)
+
+type batchOp struct {
+	op    uint8
+	key   []byte
+	value []byte
+}
+
+// Batch Operations applied together by KV.Write, in order.
+type Batch struct {
+	ops []batchOp
+}
+
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+func (b *Batch) Put(key, value []byte) {
+	b.ops = append(b.ops, batchOp{op: opPut, key: key, value: value})
+}
+
+func (b *Batch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{op: opDelete, key: key})
+}
+
+// DeletePrefix Delete all keys with prefix, before the later operations of batch.
+func (b *Batch) DeletePrefix(prefix []byte) {
+	b.ops = append(b.ops, batchOp{op: opDeletePrefix, key: prefix})
+}
+
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestKV(t *testing.T) {
+	bolt, err := OpenBolt(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open bolt failed: %s", err)
+	}
+	defer bolt.Close()
+
+	for name, kv := range map[string]KV{"memory": NewMemory(), "bolt": bolt} {
+		if _, err := kv.Get([]byte("a/1")); err != ErrNotFound {
+			t.Fatalf("%s: get missing key: %v", name, err)
+		}
+
+		batch := NewBatch()
+		for i := 0; i < 5; i++ {
+			batch.Put([]byte(fmt.Sprintf("a/%d", i)), []byte{byte(i)})
+			batch.Put([]byte(fmt.Sprintf("b/%d", i)), []byte{byte(i)})
+		}
+		if err := kv.Write(batch); err != nil {
+			t.Fatalf("%s: write failed: %s", name, err)
+		}
+		if err := kv.Put([]byte("a/9"), []byte{9}); err != nil {
+			t.Fatalf("%s: put failed: %s", name, err)
+		}
+		if err := kv.Delete([]byte("a/0")); err != nil {
+			t.Fatalf("%s: delete failed: %s", name, err)
+		}
+
+		var keys string
+		kv.Iterate([]byte("a/"), func(key, value []byte) bool {
+			keys += string(key) + " "
+			return true
+		})
+		if keys != "a/1 a/2 a/3 a/4 a/9 " {
+			t.Fatalf("%s: unexpected keys: %s", name, keys)
+		}
+
+		// delete prefix, then put in same batch
+		batch = NewBatch()
+		batch.DeletePrefix([]byte("b/"))
+		batch.Put([]byte("b/7"), []byte{7})
+		if err := kv.Write(batch); err != nil {
+			t.Fatalf("%s: write failed: %s", name, err)
+		}
+		keys = ""
+		kv.Iterate([]byte("b/"), func(key, value []byte) bool {
+			keys += string(key) + " "
+			return true
+		})
+		if keys != "b/7 " {
+			t.Fatalf("%s: unexpected keys after delete prefix: %s", name, keys)
+		}
+		if value, err := kv.Get([]byte("a/3")); err != nil || value[0] != 3 {
+			t.Fatalf("%s: get failed: %v %v", name, value, err)
+		}
+	}
+}
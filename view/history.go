@@ -0,0 +1,120 @@
+package view
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/model"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+)
+
+// HistoryRow Decoded BRC20History, balances are after the event. Fields are json of server and
+// columns of export.
+type HistoryRow struct {
+	Idx                 uint64 `json:"idx" parquet:"idx"`
+	Type                string `json:"type" parquet:"type"`
+	Valid               bool   `json:"valid" parquet:"valid"`
+	Ticker              string `json:"ticker" parquet:"ticker"`
+	InscriptionId       string `json:"inscriptionId" parquet:"inscription_id"`
+	InscriptionNumber   int64  `json:"inscriptionNumber" parquet:"inscription_number"`
+	TxId                string `json:"txid" parquet:"txid"`
+	Vout                uint32 `json:"vout" parquet:"vout"`
+	Offset              uint64 `json:"offset" parquet:"offset"`
+	FromAddress         string `json:"from" parquet:"from_address"`
+	ToAddress           string `json:"to" parquet:"to_address"`
+	Amount              string `json:"amount" parquet:"amount"`
+	OverallBalance      string `json:"overallBalance" parquet:"overall_balance"`
+	AvailableBalance    string `json:"availableBalance" parquet:"available_balance"`
+	TransferableBalance string `json:"transferableBalance" parquet:"transferable_balance"`
+	Satoshi             uint64 `json:"satoshi" parquet:"satoshi"`
+	Fee                 int64  `json:"fee" parquet:"fee"`
+	Height              uint32 `json:"height" parquet:"height"`
+	TxIdx               uint32 `json:"txidx" parquet:"tx_idx"`
+	BlockTime           uint32 `json:"blocktime" parquet:"block_time"`
+}
+
+// ModuleHistoryRow Decoded BRC20ModuleHistory, ticker and amount are of withdraw and approve.
+type ModuleHistoryRow struct {
+	ModuleId          string `json:"moduleId" parquet:"module_id"`
+	Type              string `json:"type" parquet:"type"`
+	Valid             bool   `json:"valid" parquet:"valid"`
+	Ticker            string `json:"ticker" parquet:"ticker"`
+	Amount            string `json:"amount" parquet:"amount"`
+	InscriptionId     string `json:"inscriptionId" parquet:"inscription_id"`
+	InscriptionNumber int64  `json:"inscriptionNumber" parquet:"inscription_number"`
+	TxId              string `json:"txid" parquet:"txid"`
+	Vout              uint32 `json:"vout" parquet:"vout"`
+	Offset            uint64 `json:"offset" parquet:"offset"`
+	FromAddress       string `json:"from" parquet:"from_address"`
+	ToAddress         string `json:"to" parquet:"to_address"`
+	Satoshi           uint64 `json:"satoshi" parquet:"satoshi"`
+	Fee               int64  `json:"fee" parquet:"fee"`
+	Height            uint32 `json:"height" parquet:"height"`
+	TxIdx             uint32 `json:"txidx" parquet:"tx_idx"`
+	BlockTime         uint32 `json:"blocktime" parquet:"block_time"`
+}
+
+// DecodeHistory Row of history record idx.
+func DecodeHistory(idx uint64, h *model.BRC20History, params *chaincfg.Params) HistoryRow {
+	ticker := ""
+	if h.Inscription.Data != nil {
+		ticker = h.Inscription.Data.BRC20Tick
+	}
+	return HistoryRow{
+		Idx:                 idx,
+		Type:                h.TypeName(),
+		Valid:               h.Valid,
+		Ticker:              ticker,
+		InscriptionId:       h.Inscription.InscriptionId,
+		InscriptionNumber:   h.Inscription.InscriptionNumber,
+		TxId:                utils.HashString([]byte(h.TxId)),
+		Vout:                h.Vout,
+		Offset:              h.Offset,
+		FromAddress:         Address(h.PkScriptFrom, params),
+		ToAddress:           Address(h.PkScriptTo, params),
+		Amount:              h.Amount,
+		OverallBalance:      h.OverallBalance,
+		AvailableBalance:    h.AvailableBalance,
+		TransferableBalance: h.TransferableBalance,
+		Satoshi:             h.Satoshi,
+		Fee:                 h.Fee,
+		Height:              h.Height,
+		TxIdx:               h.TxIdx,
+		BlockTime:           h.BlockTime,
+	}
+}
+
+// DecodeModuleHistory Row of history of module.
+func DecodeModuleHistory(moduleId string, h *model.BRC20ModuleHistory, params *chaincfg.Params) ModuleHistoryRow {
+	row := ModuleHistoryRow{
+		ModuleId:          moduleId,
+		Type:              h.TypeName(),
+		Valid:             h.Valid,
+		InscriptionId:     h.Inscription.InscriptionId,
+		InscriptionNumber: h.Inscription.InscriptionNumber,
+		TxId:              utils.HashString([]byte(h.TxId)),
+		Vout:              h.Vout,
+		Offset:            h.Offset,
+		FromAddress:       Address(h.PkScriptFrom, params),
+		ToAddress:         Address(h.PkScriptTo, params),
+		Satoshi:           h.Satoshi,
+		Fee:               h.Fee,
+		Height:            h.Height,
+		TxIdx:             h.TxIdx,
+		BlockTime:         h.BlockTime,
+	}
+	// data is pointer if processed, value if loaded by gob
+	switch data := h.Data.(type) {
+	case *model.BRC20SwapHistoryWithdrawData:
+		row.Ticker, row.Amount = data.Tick, data.Amount
+	case model.BRC20SwapHistoryWithdrawData:
+		row.Ticker, row.Amount = data.Tick, data.Amount
+	case *model.BRC20SwapHistoryApproveData:
+		row.Ticker, row.Amount = data.Tick, data.Amount
+	case model.BRC20SwapHistoryApproveData:
+		row.Ticker, row.Amount = data.Tick, data.Amount
+	case *model.BRC20SwapHistoryCondApproveData:
+		row.Ticker, row.Amount = data.Tick, data.Amount
+	case model.BRC20SwapHistoryCondApproveData:
+		row.Ticker, row.Amount = data.Tick, data.Amount
+	}
+	return row
+}
@@ -0,0 +1,68 @@
+package view
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/unisat-wallet/libbrc20-indexer/decimal"
+	"github.com/unisat-wallet/libbrc20-indexer/utils"
+)
+
+const (
+	DEFAULT_PAGE_LIMIT = 100
+	MAX_PAGE_LIMIT     = 1000
+)
+
+var ErrBadAddress = errors.New("invalid address")
+
+// DecimalString Value of d, 0 if not set.
+func DecimalString(d *decimal.Decimal) string {
+	if d == nil {
+		return "0"
+	}
+	return d.String()
+}
+
+// Address Address of pkScript, hex of it if not standard.
+func Address(pkScript string, params *chaincfg.Params) string {
+	if pkScript == "" {
+		return ""
+	}
+	address, err := utils.GetAddressFromScript([]byte(pkScript), params)
+	if err != nil {
+		return hex.EncodeToString([]byte(pkScript))
+	}
+	return address
+}
+
+// PkScript PkScript of address, ErrBadAddress if not valid of params.
+func PkScript(address string, params *chaincfg.Params) (string, error) {
+	pkScript, err := utils.GetPkScriptByAddress(address, params)
+	if err != nil {
+		return "", ErrBadAddress
+	}
+	return string(pkScript), nil
+}
+
+// PageBounds Bounds of page of limit from start in list of total.
+func PageBounds(start, limit, total int) (int, int) {
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func SortedKeys[V any](m map[string]V) (keys []string) {
+	keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}